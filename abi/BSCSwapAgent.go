@@ -4,6 +4,7 @@
 package abi
 
 import (
+	"errors"
 	"math/big"
 	"strings"
 
@@ -17,18 +18,24 @@ import (
 
 // Reference imports to suppress errors if they are not otherwise used.
 var (
+	_ = errors.New
 	_ = big.NewInt
 	_ = strings.NewReader
 	_ = ethereum.NotFound
-	_ = abi.U256
 	_ = bind.Bind
 	_ = common.Big1
 	_ = types.BloomLookup
 	_ = event.NewSubscription
 )
 
+// BSCSwapAgentMetaData contains all meta data concerning the BSCSwapAgent contract.
+var BSCSwapAgentMetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"fee\",\"type\":\"uint256\"}],\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"previousOwner\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"newOwner\",\"type\":\"address\"}],\"name\":\"OwnershipTransferred\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"fromChainId\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"toChainId\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"fromAddress\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"SwapFilled\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"fromChainId\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"toChainId\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"fromAddress\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"SwapStarted\",\"type\":\"event\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"fromChainId\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"toChainId\",\"type\":\"uint256\"},{\"internalType\":\"address\",\"name\":\"toAddress\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"fillSwap\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"owner\",\"outputs\":[{\"internalType\":\"addresspayable\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"renounceOwnership\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"merkleRoot\",\"type\":\"bytes32\"}],\"name\":\"setOwnerMerkleRoot\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"fee\",\"type\":\"uint256\"}],\"name\":\"setSwapFee\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"chainId\",\"type\":\"uint256\"},{\"internalType\":\"address\",\"name\":\"tokenAddress\",\"type\":\"address\"}],\"name\":\"setToken\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"fromChainId\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"toChainId\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"swap\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"payable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"swapFee\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"tokenAddresses\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"addresspayable\",\"name\":\"newOwner\",\"type\":\"address\"}],\"name\":\"transferOwnership\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"chainId\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"},{\"internalType\":\"bytes32[]\",\"name\":\"merkleProof\",\"type\":\"bytes32[]\"}],\"name\":\"withdrawTokens\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]",
+}
+
 // SwapAgentABI is the input ABI used to generate the binding from.
-const SwapAgentABI = "[{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"fee\",\"type\":\"uint256\"}],\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"previousOwner\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"newOwner\",\"type\":\"address\"}],\"name\":\"OwnershipTransferred\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"fromChainId\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"toChainId\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"fromAddress\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"SwapFilled\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"fromChainId\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"toChainId\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"fromAddress\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"SwapStarted\",\"type\":\"event\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"fromChainId\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"toChainId\",\"type\":\"uint256\"},{\"internalType\":\"address\",\"name\":\"toAddress\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"fillSwap\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"owner\",\"outputs\":[{\"internalType\":\"address payable\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"renounceOwnership\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"merkleRoot\",\"type\":\"bytes32\"}],\"name\":\"setOwnerMerkleRoot\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"fee\",\"type\":\"uint256\"}],\"name\":\"setSwapFee\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"chainId\",\"type\":\"uint256\"},{\"internalType\":\"address\",\"name\":\"tokenAddress\",\"type\":\"address\"}],\"name\":\"setToken\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"fromChainId\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"toChainId\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"swap\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"payable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"swapFee\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"tokenAddresses\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address payable\",\"name\":\"newOwner\",\"type\":\"address\"}],\"name\":\"transferOwnership\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"chainId\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"},{\"internalType\":\"bytes32[]\",\"name\":\"merkleProof\",\"type\":\"bytes32[]\"}],\"name\":\"withdrawTokens\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]"
+// Deprecated: Use BSCSwapAgentMetaData.ABI instead.
+var SwapAgentABI = BSCSwapAgentMetaData.ABI
 
 // BSCSwapAgent is an auto generated Go binding around an Ethereum contract.
 type BSCSwapAgent struct {
@@ -138,7 +145,7 @@ func bindBSCSwapAgent(address common.Address, caller bind.ContractCaller, transa
 // sets the output to result. The result type might be a single field for simple
 // returns, a slice of interfaces for anonymous returns and a struct for named
 // returns.
-func (_BSCSwapAgent *BSCSwapAgentRaw) Call(opts *bind.CallOpts, result interface{}, method string, params ...interface{}) error {
+func (_BSCSwapAgent *BSCSwapAgentRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
 	return _BSCSwapAgent.Contract.BSCSwapAgentCaller.contract.Call(opts, result, method, params...)
 }
 
@@ -157,7 +164,7 @@ func (_BSCSwapAgent *BSCSwapAgentRaw) Transact(opts *bind.TransactOpts, method s
 // sets the output to result. The result type might be a single field for simple
 // returns, a slice of interfaces for anonymous returns and a struct for named
 // returns.
-func (_BSCSwapAgent *BSCSwapAgentCallerRaw) Call(opts *bind.CallOpts, result interface{}, method string, params ...interface{}) error {
+func (_BSCSwapAgent *BSCSwapAgentCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
 	return _BSCSwapAgent.Contract.contract.Call(opts, result, method, params...)
 }
 
@@ -172,249 +179,118 @@ func (_BSCSwapAgent *BSCSwapAgentTransactorRaw) Transact(opts *bind.TransactOpts
 	return _BSCSwapAgent.Contract.contract.Transact(opts, method, params...)
 }
 
-// Bep20Implementation is a free data retrieval call binding the contract method 0x66fec65c.
-//
-// Solidity: function bep20Implementation() constant returns(address)
-func (_BSCSwapAgent *BSCSwapAgentCaller) Bep20Implementation(opts *bind.CallOpts) (common.Address, error) {
-	var (
-		ret0 = new(common.Address)
-	)
-	out := ret0
-	err := _BSCSwapAgent.contract.Call(opts, out, "bep20Implementation")
-	return *ret0, err
-}
-
-// Bep20Implementation is a free data retrieval call binding the contract method 0x66fec65c.
-//
-// Solidity: function bep20Implementation() constant returns(address)
-func (_BSCSwapAgent *BSCSwapAgentSession) Bep20Implementation() (common.Address, error) {
-	return _BSCSwapAgent.Contract.Bep20Implementation(&_BSCSwapAgent.CallOpts)
-}
-
-// Bep20Implementation is a free data retrieval call binding the contract method 0x66fec65c.
-//
-// Solidity: function bep20Implementation() constant returns(address)
-func (_BSCSwapAgent *BSCSwapAgentCallerSession) Bep20Implementation() (common.Address, error) {
-	return _BSCSwapAgent.Contract.Bep20Implementation(&_BSCSwapAgent.CallOpts)
-}
-
-// Bep20ProxyAdmin is a free data retrieval call binding the contract method 0x0344165a.
+// Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
 //
-// Solidity: function bep20ProxyAdmin() constant returns(address)
-func (_BSCSwapAgent *BSCSwapAgentCaller) Bep20ProxyAdmin(opts *bind.CallOpts) (common.Address, error) {
-	var (
-		ret0 = new(common.Address)
-	)
-	out := ret0
-	err := _BSCSwapAgent.contract.Call(opts, out, "bep20ProxyAdmin")
-	return *ret0, err
-}
+// Solidity: function owner() view returns(address)
+func (_BSCSwapAgent *BSCSwapAgentCaller) Owner(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _BSCSwapAgent.contract.Call(opts, &out, "owner")
 
-// Bep20ProxyAdmin is a free data retrieval call binding the contract method 0x0344165a.
-//
-// Solidity: function bep20ProxyAdmin() constant returns(address)
-func (_BSCSwapAgent *BSCSwapAgentSession) Bep20ProxyAdmin() (common.Address, error) {
-	return _BSCSwapAgent.Contract.Bep20ProxyAdmin(&_BSCSwapAgent.CallOpts)
-}
+	if err != nil {
+		return *new(common.Address), err
+	}
 
-// Bep20ProxyAdmin is a free data retrieval call binding the contract method 0x0344165a.
-//
-// Solidity: function bep20ProxyAdmin() constant returns(address)
-func (_BSCSwapAgent *BSCSwapAgentCallerSession) Bep20ProxyAdmin() (common.Address, error) {
-	return _BSCSwapAgent.Contract.Bep20ProxyAdmin(&_BSCSwapAgent.CallOpts)
-}
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
 
-// FilledETHTx is a free data retrieval call binding the contract method 0x4e2dc7f1.
-//
-// Solidity: function filledETHTx(bytes32 ) constant returns(bool)
-func (_BSCSwapAgent *BSCSwapAgentCaller) FilledETHTx(opts *bind.CallOpts, arg0 [32]byte) (bool, error) {
-	var (
-		ret0 = new(bool)
-	)
-	out := ret0
-	err := _BSCSwapAgent.contract.Call(opts, out, "filledETHTx", arg0)
-	return *ret0, err
-}
+	return out0, err
 
-// FilledETHTx is a free data retrieval call binding the contract method 0x4e2dc7f1.
-//
-// Solidity: function filledETHTx(bytes32 ) constant returns(bool)
-func (_BSCSwapAgent *BSCSwapAgentSession) FilledETHTx(arg0 [32]byte) (bool, error) {
-	return _BSCSwapAgent.Contract.FilledETHTx(&_BSCSwapAgent.CallOpts, arg0)
-}
-
-// FilledETHTx is a free data retrieval call binding the contract method 0x4e2dc7f1.
-//
-// Solidity: function filledETHTx(bytes32 ) constant returns(bool)
-func (_BSCSwapAgent *BSCSwapAgentCallerSession) FilledETHTx(arg0 [32]byte) (bool, error) {
-	return _BSCSwapAgent.Contract.FilledETHTx(&_BSCSwapAgent.CallOpts, arg0)
-}
-
-// Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
-//
-// Solidity: function owner() constant returns(address)
-func (_BSCSwapAgent *BSCSwapAgentCaller) Owner(opts *bind.CallOpts) (common.Address, error) {
-	var (
-		ret0 = new(common.Address)
-	)
-	out := ret0
-	err := _BSCSwapAgent.contract.Call(opts, out, "owner")
-	return *ret0, err
 }
 
 // Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
 //
-// Solidity: function owner() constant returns(address)
+// Solidity: function owner() view returns(address)
 func (_BSCSwapAgent *BSCSwapAgentSession) Owner() (common.Address, error) {
 	return _BSCSwapAgent.Contract.Owner(&_BSCSwapAgent.CallOpts)
 }
 
 // Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
 //
-// Solidity: function owner() constant returns(address)
+// Solidity: function owner() view returns(address)
 func (_BSCSwapAgent *BSCSwapAgentCallerSession) Owner() (common.Address, error) {
 	return _BSCSwapAgent.Contract.Owner(&_BSCSwapAgent.CallOpts)
 }
 
 // SwapFee is a free data retrieval call binding the contract method 0x54cf2aeb.
 //
-// Solidity: function swapFee() constant returns(uint256)
+// Solidity: function swapFee() view returns(uint256)
 func (_BSCSwapAgent *BSCSwapAgentCaller) SwapFee(opts *bind.CallOpts) (*big.Int, error) {
-	var (
-		ret0 = new(*big.Int)
-	)
-	out := ret0
-	err := _BSCSwapAgent.contract.Call(opts, out, "swapFee")
-	return *ret0, err
+	var out []interface{}
+	err := _BSCSwapAgent.contract.Call(opts, &out, "swapFee")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
 }
 
 // SwapFee is a free data retrieval call binding the contract method 0x54cf2aeb.
 //
-// Solidity: function swapFee() constant returns(uint256)
+// Solidity: function swapFee() view returns(uint256)
 func (_BSCSwapAgent *BSCSwapAgentSession) SwapFee() (*big.Int, error) {
 	return _BSCSwapAgent.Contract.SwapFee(&_BSCSwapAgent.CallOpts)
 }
 
 // SwapFee is a free data retrieval call binding the contract method 0x54cf2aeb.
 //
-// Solidity: function swapFee() constant returns(uint256)
+// Solidity: function swapFee() view returns(uint256)
 func (_BSCSwapAgent *BSCSwapAgentCallerSession) SwapFee() (*big.Int, error) {
 	return _BSCSwapAgent.Contract.SwapFee(&_BSCSwapAgent.CallOpts)
 }
 
-// SwapMappingBSC2ETH is a free data retrieval call binding the contract method 0xbe0ace69.
-//
-// Solidity: function swapMappingBSC2ETH(address ) constant returns(address)
-func (_BSCSwapAgent *BSCSwapAgentCaller) SwapMappingBSC2ETH(opts *bind.CallOpts, arg0 common.Address) (common.Address, error) {
-	var (
-		ret0 = new(common.Address)
-	)
-	out := ret0
-	err := _BSCSwapAgent.contract.Call(opts, out, "swapMappingBSC2ETH", arg0)
-	return *ret0, err
-}
-
-// SwapMappingBSC2ETH is a free data retrieval call binding the contract method 0xbe0ace69.
-//
-// Solidity: function swapMappingBSC2ETH(address ) constant returns(address)
-func (_BSCSwapAgent *BSCSwapAgentSession) SwapMappingBSC2ETH(arg0 common.Address) (common.Address, error) {
-	return _BSCSwapAgent.Contract.SwapMappingBSC2ETH(&_BSCSwapAgent.CallOpts, arg0)
-}
-
-// SwapMappingBSC2ETH is a free data retrieval call binding the contract method 0xbe0ace69.
-//
-// Solidity: function swapMappingBSC2ETH(address ) constant returns(address)
-func (_BSCSwapAgent *BSCSwapAgentCallerSession) SwapMappingBSC2ETH(arg0 common.Address) (common.Address, error) {
-	return _BSCSwapAgent.Contract.SwapMappingBSC2ETH(&_BSCSwapAgent.CallOpts, arg0)
-}
-
-// SwapMappingETH2BSC is a free data retrieval call binding the contract method 0x60b810f1.
-//
-// Solidity: function swapMappingETH2BSC(address ) constant returns(address)
-func (_BSCSwapAgent *BSCSwapAgentCaller) SwapMappingETH2BSC(opts *bind.CallOpts, arg0 common.Address) (common.Address, error) {
-	var (
-		ret0 = new(common.Address)
-	)
-	out := ret0
-	err := _BSCSwapAgent.contract.Call(opts, out, "swapMappingETH2BSC", arg0)
-	return *ret0, err
-}
-
-// SwapMappingETH2BSC is a free data retrieval call binding the contract method 0x60b810f1.
+// TokenAddresses is a free data retrieval call binding the contract method 0xe5df8b84.
 //
-// Solidity: function swapMappingETH2BSC(address ) constant returns(address)
-func (_BSCSwapAgent *BSCSwapAgentSession) SwapMappingETH2BSC(arg0 common.Address) (common.Address, error) {
-	return _BSCSwapAgent.Contract.SwapMappingETH2BSC(&_BSCSwapAgent.CallOpts, arg0)
-}
-
-// SwapMappingETH2BSC is a free data retrieval call binding the contract method 0x60b810f1.
-//
-// Solidity: function swapMappingETH2BSC(address ) constant returns(address)
-func (_BSCSwapAgent *BSCSwapAgentCallerSession) SwapMappingETH2BSC(arg0 common.Address) (common.Address, error) {
-	return _BSCSwapAgent.Contract.SwapMappingETH2BSC(&_BSCSwapAgent.CallOpts, arg0)
-}
+// Solidity: function tokenAddresses(uint256 ) view returns(address)
+func (_BSCSwapAgent *BSCSwapAgentCaller) TokenAddresses(opts *bind.CallOpts, arg0 *big.Int) (common.Address, error) {
+	var out []interface{}
+	err := _BSCSwapAgent.contract.Call(opts, &out, "tokenAddresses", arg0)
 
-// CreateSwapPair is a paid mutator transaction binding the contract method 0x32bd6e31.
-//
-// Solidity: function createSwapPair(bytes32 ethTxHash, address erc20Addr, string name, string symbol, uint8 decimals) returns(address)
-func (_BSCSwapAgent *BSCSwapAgentTransactor) CreateSwapPair(opts *bind.TransactOpts, ethTxHash [32]byte, erc20Addr common.Address, name string, symbol string, decimals uint8) (*types.Transaction, error) {
-	return _BSCSwapAgent.contract.Transact(opts, "createSwapPair", ethTxHash, erc20Addr, name, symbol, decimals)
-}
+	if err != nil {
+		return *new(common.Address), err
+	}
 
-// CreateSwapPair is a paid mutator transaction binding the contract method 0x32bd6e31.
-//
-// Solidity: function createSwapPair(bytes32 ethTxHash, address erc20Addr, string name, string symbol, uint8 decimals) returns(address)
-func (_BSCSwapAgent *BSCSwapAgentSession) CreateSwapPair(ethTxHash [32]byte, erc20Addr common.Address, name string, symbol string, decimals uint8) (*types.Transaction, error) {
-	return _BSCSwapAgent.Contract.CreateSwapPair(&_BSCSwapAgent.TransactOpts, ethTxHash, erc20Addr, name, symbol, decimals)
-}
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
 
-// CreateSwapPair is a paid mutator transaction binding the contract method 0x32bd6e31.
-//
-// Solidity: function createSwapPair(bytes32 ethTxHash, address erc20Addr, string name, string symbol, uint8 decimals) returns(address)
-func (_BSCSwapAgent *BSCSwapAgentTransactorSession) CreateSwapPair(ethTxHash [32]byte, erc20Addr common.Address, name string, symbol string, decimals uint8) (*types.Transaction, error) {
-	return _BSCSwapAgent.Contract.CreateSwapPair(&_BSCSwapAgent.TransactOpts, ethTxHash, erc20Addr, name, symbol, decimals)
-}
+	return out0, err
 
-// FillETH2BSCSwap is a paid mutator transaction binding the contract method 0xe307b931.
-//
-// Solidity: function fillETH2BSCSwap(bytes32 ethTxHash, address erc20Addr, address toAddress, uint256 amount) returns(bool)
-func (_BSCSwapAgent *BSCSwapAgentTransactor) FillETH2BSCSwap(opts *bind.TransactOpts, ethTxHash [32]byte, erc20Addr common.Address, toAddress common.Address, amount *big.Int) (*types.Transaction, error) {
-	return _BSCSwapAgent.contract.Transact(opts, "fillETH2BSCSwap", ethTxHash, erc20Addr, toAddress, amount)
 }
 
-// FillETH2BSCSwap is a paid mutator transaction binding the contract method 0xe307b931.
+// TokenAddresses is a free data retrieval call binding the contract method 0xe5df8b84.
 //
-// Solidity: function fillETH2BSCSwap(bytes32 ethTxHash, address erc20Addr, address toAddress, uint256 amount) returns(bool)
-func (_BSCSwapAgent *BSCSwapAgentSession) FillETH2BSCSwap(ethTxHash [32]byte, erc20Addr common.Address, toAddress common.Address, amount *big.Int) (*types.Transaction, error) {
-	return _BSCSwapAgent.Contract.FillETH2BSCSwap(&_BSCSwapAgent.TransactOpts, ethTxHash, erc20Addr, toAddress, amount)
+// Solidity: function tokenAddresses(uint256 ) view returns(address)
+func (_BSCSwapAgent *BSCSwapAgentSession) TokenAddresses(arg0 *big.Int) (common.Address, error) {
+	return _BSCSwapAgent.Contract.TokenAddresses(&_BSCSwapAgent.CallOpts, arg0)
 }
 
-// FillETH2BSCSwap is a paid mutator transaction binding the contract method 0xe307b931.
+// TokenAddresses is a free data retrieval call binding the contract method 0xe5df8b84.
 //
-// Solidity: function fillETH2BSCSwap(bytes32 ethTxHash, address erc20Addr, address toAddress, uint256 amount) returns(bool)
-func (_BSCSwapAgent *BSCSwapAgentTransactorSession) FillETH2BSCSwap(ethTxHash [32]byte, erc20Addr common.Address, toAddress common.Address, amount *big.Int) (*types.Transaction, error) {
-	return _BSCSwapAgent.Contract.FillETH2BSCSwap(&_BSCSwapAgent.TransactOpts, ethTxHash, erc20Addr, toAddress, amount)
+// Solidity: function tokenAddresses(uint256 ) view returns(address)
+func (_BSCSwapAgent *BSCSwapAgentCallerSession) TokenAddresses(arg0 *big.Int) (common.Address, error) {
+	return _BSCSwapAgent.Contract.TokenAddresses(&_BSCSwapAgent.CallOpts, arg0)
 }
 
-// Initialize is a paid mutator transaction binding the contract method 0x358394d8.
+// FillSwap is a paid mutator transaction binding the contract method 0x9528d9e8.
 //
-// Solidity: function initialize(address bep20Impl, uint256 fee, address ownerAddr, address bep20ProxyAdminAddr) returns()
-func (_BSCSwapAgent *BSCSwapAgentTransactor) Initialize(opts *bind.TransactOpts, bep20Impl common.Address, fee *big.Int, ownerAddr common.Address, bep20ProxyAdminAddr common.Address) (*types.Transaction, error) {
-	return _BSCSwapAgent.contract.Transact(opts, "initialize", bep20Impl, fee, ownerAddr, bep20ProxyAdminAddr)
+// Solidity: function fillSwap(uint256 fromChainId, uint256 toChainId, address toAddress, uint256 amount) returns(bool)
+func (_BSCSwapAgent *BSCSwapAgentTransactor) FillSwap(opts *bind.TransactOpts, fromChainId *big.Int, toChainId *big.Int, toAddress common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _BSCSwapAgent.contract.Transact(opts, "fillSwap", fromChainId, toChainId, toAddress, amount)
 }
 
-// Initialize is a paid mutator transaction binding the contract method 0x358394d8.
+// FillSwap is a paid mutator transaction binding the contract method 0x9528d9e8.
 //
-// Solidity: function initialize(address bep20Impl, uint256 fee, address ownerAddr, address bep20ProxyAdminAddr) returns()
-func (_BSCSwapAgent *BSCSwapAgentSession) Initialize(bep20Impl common.Address, fee *big.Int, ownerAddr common.Address, bep20ProxyAdminAddr common.Address) (*types.Transaction, error) {
-	return _BSCSwapAgent.Contract.Initialize(&_BSCSwapAgent.TransactOpts, bep20Impl, fee, ownerAddr, bep20ProxyAdminAddr)
+// Solidity: function fillSwap(uint256 fromChainId, uint256 toChainId, address toAddress, uint256 amount) returns(bool)
+func (_BSCSwapAgent *BSCSwapAgentSession) FillSwap(fromChainId *big.Int, toChainId *big.Int, toAddress common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _BSCSwapAgent.Contract.FillSwap(&_BSCSwapAgent.TransactOpts, fromChainId, toChainId, toAddress, amount)
 }
 
-// Initialize is a paid mutator transaction binding the contract method 0x358394d8.
+// FillSwap is a paid mutator transaction binding the contract method 0x9528d9e8.
 //
-// Solidity: function initialize(address bep20Impl, uint256 fee, address ownerAddr, address bep20ProxyAdminAddr) returns()
-func (_BSCSwapAgent *BSCSwapAgentTransactorSession) Initialize(bep20Impl common.Address, fee *big.Int, ownerAddr common.Address, bep20ProxyAdminAddr common.Address) (*types.Transaction, error) {
-	return _BSCSwapAgent.Contract.Initialize(&_BSCSwapAgent.TransactOpts, bep20Impl, fee, ownerAddr, bep20ProxyAdminAddr)
+// Solidity: function fillSwap(uint256 fromChainId, uint256 toChainId, address toAddress, uint256 amount) returns(bool)
+func (_BSCSwapAgent *BSCSwapAgentTransactorSession) FillSwap(fromChainId *big.Int, toChainId *big.Int, toAddress common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _BSCSwapAgent.Contract.FillSwap(&_BSCSwapAgent.TransactOpts, fromChainId, toChainId, toAddress, amount)
 }
 
 // RenounceOwnership is a paid mutator transaction binding the contract method 0x715018a6.
@@ -438,6 +314,27 @@ func (_BSCSwapAgent *BSCSwapAgentTransactorSession) RenounceOwnership() (*types.
 	return _BSCSwapAgent.Contract.RenounceOwnership(&_BSCSwapAgent.TransactOpts)
 }
 
+// SetOwnerMerkleRoot is a paid mutator transaction binding the contract method 0x82762545.
+//
+// Solidity: function setOwnerMerkleRoot(bytes32 merkleRoot) returns()
+func (_BSCSwapAgent *BSCSwapAgentTransactor) SetOwnerMerkleRoot(opts *bind.TransactOpts, merkleRoot [32]byte) (*types.Transaction, error) {
+	return _BSCSwapAgent.contract.Transact(opts, "setOwnerMerkleRoot", merkleRoot)
+}
+
+// SetOwnerMerkleRoot is a paid mutator transaction binding the contract method 0x82762545.
+//
+// Solidity: function setOwnerMerkleRoot(bytes32 merkleRoot) returns()
+func (_BSCSwapAgent *BSCSwapAgentSession) SetOwnerMerkleRoot(merkleRoot [32]byte) (*types.Transaction, error) {
+	return _BSCSwapAgent.Contract.SetOwnerMerkleRoot(&_BSCSwapAgent.TransactOpts, merkleRoot)
+}
+
+// SetOwnerMerkleRoot is a paid mutator transaction binding the contract method 0x82762545.
+//
+// Solidity: function setOwnerMerkleRoot(bytes32 merkleRoot) returns()
+func (_BSCSwapAgent *BSCSwapAgentTransactorSession) SetOwnerMerkleRoot(merkleRoot [32]byte) (*types.Transaction, error) {
+	return _BSCSwapAgent.Contract.SetOwnerMerkleRoot(&_BSCSwapAgent.TransactOpts, merkleRoot)
+}
+
 // SetSwapFee is a paid mutator transaction binding the contract method 0x34e19907.
 //
 // Solidity: function setSwapFee(uint256 fee) returns()
@@ -459,25 +356,46 @@ func (_BSCSwapAgent *BSCSwapAgentTransactorSession) SetSwapFee(fee *big.Int) (*t
 	return _BSCSwapAgent.Contract.SetSwapFee(&_BSCSwapAgent.TransactOpts, fee)
 }
 
-// SwapBSC2ETH is a paid mutator transaction binding the contract method 0x1ba3b150.
+// SetToken is a paid mutator transaction binding the contract method 0xaf906cf3.
+//
+// Solidity: function setToken(uint256 chainId, address tokenAddress) returns()
+func (_BSCSwapAgent *BSCSwapAgentTransactor) SetToken(opts *bind.TransactOpts, chainId *big.Int, tokenAddress common.Address) (*types.Transaction, error) {
+	return _BSCSwapAgent.contract.Transact(opts, "setToken", chainId, tokenAddress)
+}
+
+// SetToken is a paid mutator transaction binding the contract method 0xaf906cf3.
 //
-// Solidity: function swapBSC2ETH(address bep20Addr, uint256 amount) returns(bool)
-func (_BSCSwapAgent *BSCSwapAgentTransactor) SwapBSC2ETH(opts *bind.TransactOpts, bep20Addr common.Address, amount *big.Int) (*types.Transaction, error) {
-	return _BSCSwapAgent.contract.Transact(opts, "swapBSC2ETH", bep20Addr, amount)
+// Solidity: function setToken(uint256 chainId, address tokenAddress) returns()
+func (_BSCSwapAgent *BSCSwapAgentSession) SetToken(chainId *big.Int, tokenAddress common.Address) (*types.Transaction, error) {
+	return _BSCSwapAgent.Contract.SetToken(&_BSCSwapAgent.TransactOpts, chainId, tokenAddress)
 }
 
-// SwapBSC2ETH is a paid mutator transaction binding the contract method 0x1ba3b150.
+// SetToken is a paid mutator transaction binding the contract method 0xaf906cf3.
 //
-// Solidity: function swapBSC2ETH(address bep20Addr, uint256 amount) returns(bool)
-func (_BSCSwapAgent *BSCSwapAgentSession) SwapBSC2ETH(bep20Addr common.Address, amount *big.Int) (*types.Transaction, error) {
-	return _BSCSwapAgent.Contract.SwapBSC2ETH(&_BSCSwapAgent.TransactOpts, bep20Addr, amount)
+// Solidity: function setToken(uint256 chainId, address tokenAddress) returns()
+func (_BSCSwapAgent *BSCSwapAgentTransactorSession) SetToken(chainId *big.Int, tokenAddress common.Address) (*types.Transaction, error) {
+	return _BSCSwapAgent.Contract.SetToken(&_BSCSwapAgent.TransactOpts, chainId, tokenAddress)
 }
 
-// SwapBSC2ETH is a paid mutator transaction binding the contract method 0x1ba3b150.
+// Swap is a paid mutator transaction binding the contract method 0x9d9892cd.
 //
-// Solidity: function swapBSC2ETH(address bep20Addr, uint256 amount) returns(bool)
-func (_BSCSwapAgent *BSCSwapAgentTransactorSession) SwapBSC2ETH(bep20Addr common.Address, amount *big.Int) (*types.Transaction, error) {
-	return _BSCSwapAgent.Contract.SwapBSC2ETH(&_BSCSwapAgent.TransactOpts, bep20Addr, amount)
+// Solidity: function swap(uint256 fromChainId, uint256 toChainId, uint256 amount) payable returns(bool)
+func (_BSCSwapAgent *BSCSwapAgentTransactor) Swap(opts *bind.TransactOpts, fromChainId *big.Int, toChainId *big.Int, amount *big.Int) (*types.Transaction, error) {
+	return _BSCSwapAgent.contract.Transact(opts, "swap", fromChainId, toChainId, amount)
+}
+
+// Swap is a paid mutator transaction binding the contract method 0x9d9892cd.
+//
+// Solidity: function swap(uint256 fromChainId, uint256 toChainId, uint256 amount) payable returns(bool)
+func (_BSCSwapAgent *BSCSwapAgentSession) Swap(fromChainId *big.Int, toChainId *big.Int, amount *big.Int) (*types.Transaction, error) {
+	return _BSCSwapAgent.Contract.Swap(&_BSCSwapAgent.TransactOpts, fromChainId, toChainId, amount)
+}
+
+// Swap is a paid mutator transaction binding the contract method 0x9d9892cd.
+//
+// Solidity: function swap(uint256 fromChainId, uint256 toChainId, uint256 amount) payable returns(bool)
+func (_BSCSwapAgent *BSCSwapAgentTransactorSession) Swap(fromChainId *big.Int, toChainId *big.Int, amount *big.Int) (*types.Transaction, error) {
+	return _BSCSwapAgent.Contract.Swap(&_BSCSwapAgent.TransactOpts, fromChainId, toChainId, amount)
 }
 
 // TransferOwnership is a paid mutator transaction binding the contract method 0xf2fde38b.
@@ -501,6 +419,27 @@ func (_BSCSwapAgent *BSCSwapAgentTransactorSession) TransferOwnership(newOwner c
 	return _BSCSwapAgent.Contract.TransferOwnership(&_BSCSwapAgent.TransactOpts, newOwner)
 }
 
+// WithdrawTokens is a paid mutator transaction binding the contract method 0x4d606013.
+//
+// Solidity: function withdrawTokens(uint256 chainId, uint256 amount, bytes32[] merkleProof) returns(bool)
+func (_BSCSwapAgent *BSCSwapAgentTransactor) WithdrawTokens(opts *bind.TransactOpts, chainId *big.Int, amount *big.Int, merkleProof [][32]byte) (*types.Transaction, error) {
+	return _BSCSwapAgent.contract.Transact(opts, "withdrawTokens", chainId, amount, merkleProof)
+}
+
+// WithdrawTokens is a paid mutator transaction binding the contract method 0x4d606013.
+//
+// Solidity: function withdrawTokens(uint256 chainId, uint256 amount, bytes32[] merkleProof) returns(bool)
+func (_BSCSwapAgent *BSCSwapAgentSession) WithdrawTokens(chainId *big.Int, amount *big.Int, merkleProof [][32]byte) (*types.Transaction, error) {
+	return _BSCSwapAgent.Contract.WithdrawTokens(&_BSCSwapAgent.TransactOpts, chainId, amount, merkleProof)
+}
+
+// WithdrawTokens is a paid mutator transaction binding the contract method 0x4d606013.
+//
+// Solidity: function withdrawTokens(uint256 chainId, uint256 amount, bytes32[] merkleProof) returns(bool)
+func (_BSCSwapAgent *BSCSwapAgentTransactorSession) WithdrawTokens(chainId *big.Int, amount *big.Int, merkleProof [][32]byte) (*types.Transaction, error) {
+	return _BSCSwapAgent.Contract.WithdrawTokens(&_BSCSwapAgent.TransactOpts, chainId, amount, merkleProof)
+}
+
 // BSCSwapAgentOwnershipTransferredIterator is returned from FilterOwnershipTransferred and is used to iterate over the raw logs and unpacked data for OwnershipTransferred events raised by the BSCSwapAgent contract.
 type BSCSwapAgentOwnershipTransferredIterator struct {
 	Event *BSCSwapAgentOwnershipTransferred // Event containing the contract specifics and raw log
@@ -650,10 +589,11 @@ func (_BSCSwapAgent *BSCSwapAgentFilterer) ParseOwnershipTransferred(log types.L
 	if err := _BSCSwapAgent.contract.UnpackLog(event, "OwnershipTransferred", log); err != nil {
 		return nil, err
 	}
+	event.Raw = log
 	return event, nil
 }
 
-// BSCSwapAgentSwapFilledIterator is returned from FilterSwapFilled and is used to iterate over the raw logs and unpacked data for SphynxSwapFilled events raised by the BSCSwapAgent contract.
+// BSCSwapAgentSwapFilledIterator is returned from FilterSwapFilled and is used to iterate over the raw logs and unpacked data for SwapFilled events raised by the BSCSwapAgent contract.
 type BSCSwapAgentSwapFilledIterator struct {
 	Event *BSCSwapAgentSwapFilled // Event containing the contract specifics and raw log
 
@@ -720,59 +660,59 @@ func (it *BSCSwapAgentSwapFilledIterator) Close() error {
 	return nil
 }
 
-// BSCSwapAgentSwapFilled represents a SphynxSwapFilled event raised by the BSCSwapAgent contract.
+// BSCSwapAgentSwapFilled represents a SwapFilled event raised by the BSCSwapAgent contract.
 type BSCSwapAgentSwapFilled struct {
-	Bep20Addr common.Address
-	EthTxHash [32]byte
-	ToAddress common.Address
-	Amount    *big.Int
-	Raw       types.Log // Blockchain specific contextual infos
+	FromChainId *big.Int
+	ToChainId   *big.Int
+	FromAddress common.Address
+	Amount      *big.Int
+	Raw         types.Log // Blockchain specific contextual infos
 }
 
-// FilterSwapFilled is a free log retrieval operation binding the contract event 0x3bebd9a738291e69898b5dbfadb6329b4b09fc648bdef68762928e521463abd9.
+// FilterSwapFilled is a free log retrieval operation binding the contract event 0xc3ee4982aeb6e8228a604aaebff10180dbcd5b05e88b2812427836a419051f1c.
 //
-// Solidity: event SphynxSwapFilled(address indexed bep20Addr, bytes32 indexed ethTxHash, address indexed toAddress, uint256 amount)
-func (_BSCSwapAgent *BSCSwapAgentFilterer) FilterSwapFilled(opts *bind.FilterOpts, bep20Addr []common.Address, ethTxHash [][32]byte, toAddress []common.Address) (*BSCSwapAgentSwapFilledIterator, error) {
+// Solidity: event SwapFilled(uint256 fromChainId, uint256 indexed toChainId, address indexed fromAddress, uint256 indexed amount)
+func (_BSCSwapAgent *BSCSwapAgentFilterer) FilterSwapFilled(opts *bind.FilterOpts, toChainId []*big.Int, fromAddress []common.Address, amount []*big.Int) (*BSCSwapAgentSwapFilledIterator, error) {
 
-	var bep20AddrRule []interface{}
-	for _, bep20AddrItem := range bep20Addr {
-		bep20AddrRule = append(bep20AddrRule, bep20AddrItem)
+	var toChainIdRule []interface{}
+	for _, toChainIdItem := range toChainId {
+		toChainIdRule = append(toChainIdRule, toChainIdItem)
 	}
-	var ethTxHashRule []interface{}
-	for _, ethTxHashItem := range ethTxHash {
-		ethTxHashRule = append(ethTxHashRule, ethTxHashItem)
+	var fromAddressRule []interface{}
+	for _, fromAddressItem := range fromAddress {
+		fromAddressRule = append(fromAddressRule, fromAddressItem)
 	}
-	var toAddressRule []interface{}
-	for _, toAddressItem := range toAddress {
-		toAddressRule = append(toAddressRule, toAddressItem)
+	var amountRule []interface{}
+	for _, amountItem := range amount {
+		amountRule = append(amountRule, amountItem)
 	}
 
-	logs, sub, err := _BSCSwapAgent.contract.FilterLogs(opts, "SphynxSwapFilled", bep20AddrRule, ethTxHashRule, toAddressRule)
+	logs, sub, err := _BSCSwapAgent.contract.FilterLogs(opts, "SwapFilled", toChainIdRule, fromAddressRule, amountRule)
 	if err != nil {
 		return nil, err
 	}
-	return &BSCSwapAgentSwapFilledIterator{contract: _BSCSwapAgent.contract, event: "SphynxSwapFilled", logs: logs, sub: sub}, nil
+	return &BSCSwapAgentSwapFilledIterator{contract: _BSCSwapAgent.contract, event: "SwapFilled", logs: logs, sub: sub}, nil
 }
 
-// WatchSwapFilled is a free log subscription operation binding the contract event 0x3bebd9a738291e69898b5dbfadb6329b4b09fc648bdef68762928e521463abd9.
+// WatchSwapFilled is a free log subscription operation binding the contract event 0xc3ee4982aeb6e8228a604aaebff10180dbcd5b05e88b2812427836a419051f1c.
 //
-// Solidity: event SphynxSwapFilled(address indexed bep20Addr, bytes32 indexed ethTxHash, address indexed toAddress, uint256 amount)
-func (_BSCSwapAgent *BSCSwapAgentFilterer) WatchSwapFilled(opts *bind.WatchOpts, sink chan<- *BSCSwapAgentSwapFilled, bep20Addr []common.Address, ethTxHash [][32]byte, toAddress []common.Address) (event.Subscription, error) {
+// Solidity: event SwapFilled(uint256 fromChainId, uint256 indexed toChainId, address indexed fromAddress, uint256 indexed amount)
+func (_BSCSwapAgent *BSCSwapAgentFilterer) WatchSwapFilled(opts *bind.WatchOpts, sink chan<- *BSCSwapAgentSwapFilled, toChainId []*big.Int, fromAddress []common.Address, amount []*big.Int) (event.Subscription, error) {
 
-	var bep20AddrRule []interface{}
-	for _, bep20AddrItem := range bep20Addr {
-		bep20AddrRule = append(bep20AddrRule, bep20AddrItem)
+	var toChainIdRule []interface{}
+	for _, toChainIdItem := range toChainId {
+		toChainIdRule = append(toChainIdRule, toChainIdItem)
 	}
-	var ethTxHashRule []interface{}
-	for _, ethTxHashItem := range ethTxHash {
-		ethTxHashRule = append(ethTxHashRule, ethTxHashItem)
+	var fromAddressRule []interface{}
+	for _, fromAddressItem := range fromAddress {
+		fromAddressRule = append(fromAddressRule, fromAddressItem)
 	}
-	var toAddressRule []interface{}
-	for _, toAddressItem := range toAddress {
-		toAddressRule = append(toAddressRule, toAddressItem)
+	var amountRule []interface{}
+	for _, amountItem := range amount {
+		amountRule = append(amountRule, amountItem)
 	}
 
-	logs, sub, err := _BSCSwapAgent.contract.WatchLogs(opts, "SphynxSwapFilled", bep20AddrRule, ethTxHashRule, toAddressRule)
+	logs, sub, err := _BSCSwapAgent.contract.WatchLogs(opts, "SwapFilled", toChainIdRule, fromAddressRule, amountRule)
 	if err != nil {
 		return nil, err
 	}
@@ -783,7 +723,7 @@ func (_BSCSwapAgent *BSCSwapAgentFilterer) WatchSwapFilled(opts *bind.WatchOpts,
 			case log := <-logs:
 				// New log arrived, parse the event and forward to the user
 				event := new(BSCSwapAgentSwapFilled)
-				if err := _BSCSwapAgent.contract.UnpackLog(event, "SphynxSwapFilled", log); err != nil {
+				if err := _BSCSwapAgent.contract.UnpackLog(event, "SwapFilled", log); err != nil {
 					return err
 				}
 				event.Raw = log
@@ -804,170 +744,18 @@ func (_BSCSwapAgent *BSCSwapAgentFilterer) WatchSwapFilled(opts *bind.WatchOpts,
 	}), nil
 }
 
-// ParseSwapFilled is a log parse operation binding the contract event 0x3bebd9a738291e69898b5dbfadb6329b4b09fc648bdef68762928e521463abd9.
+// ParseSwapFilled is a log parse operation binding the contract event 0xc3ee4982aeb6e8228a604aaebff10180dbcd5b05e88b2812427836a419051f1c.
 //
-// Solidity: event SphynxSwapFilled(address indexed bep20Addr, bytes32 indexed ethTxHash, address indexed toAddress, uint256 amount)
+// Solidity: event SwapFilled(uint256 fromChainId, uint256 indexed toChainId, address indexed fromAddress, uint256 indexed amount)
 func (_BSCSwapAgent *BSCSwapAgentFilterer) ParseSwapFilled(log types.Log) (*BSCSwapAgentSwapFilled, error) {
 	event := new(BSCSwapAgentSwapFilled)
-	if err := _BSCSwapAgent.contract.UnpackLog(event, "SphynxSwapFilled", log); err != nil {
+	if err := _BSCSwapAgent.contract.UnpackLog(event, "SwapFilled", log); err != nil {
 		return nil, err
 	}
+	event.Raw = log
 	return event, nil
 }
 
-// BSCSwapAgentSwapPairCreatedIterator is returned from FilterSwapPairCreated and is used to iterate over the raw logs and unpacked data for SwapPairCreated events raised by the BSCSwapAgent contract.
-type BSCSwapAgentSwapPairCreatedIterator struct {
-	Event *BSCSwapAgentSwapPairCreated // Event containing the contract specifics and raw log
-
-	contract *bind.BoundContract // Generic contract to use for unpacking event data
-	event    string              // Event name to use for unpacking event data
-
-	logs chan types.Log        // Log channel receiving the found contract events
-	sub  ethereum.Subscription // Subscription for errors, completion and termination
-	done bool                  // Whether the subscription completed delivering logs
-	fail error                 // Occurred error to stop iteration
-}
-
-// Next advances the iterator to the subsequent event, returning whether there
-// are any more events found. In case of a retrieval or parsing error, false is
-// returned and Error() can be queried for the exact failure.
-func (it *BSCSwapAgentSwapPairCreatedIterator) Next() bool {
-	// If the iterator failed, stop iterating
-	if it.fail != nil {
-		return false
-	}
-	// If the iterator completed, deliver directly whatever's available
-	if it.done {
-		select {
-		case log := <-it.logs:
-			it.Event = new(BSCSwapAgentSwapPairCreated)
-			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
-				it.fail = err
-				return false
-			}
-			it.Event.Raw = log
-			return true
-
-		default:
-			return false
-		}
-	}
-	// Iterator still in progress, wait for either a data or an error event
-	select {
-	case log := <-it.logs:
-		it.Event = new(BSCSwapAgentSwapPairCreated)
-		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
-			it.fail = err
-			return false
-		}
-		it.Event.Raw = log
-		return true
-
-	case err := <-it.sub.Err():
-		it.done = true
-		it.fail = err
-		return it.Next()
-	}
-}
-
-// Error returns any retrieval or parsing error occurred during filtering.
-func (it *BSCSwapAgentSwapPairCreatedIterator) Error() error {
-	return it.fail
-}
-
-// Close terminates the iteration process, releasing any pending underlying
-// resources.
-func (it *BSCSwapAgentSwapPairCreatedIterator) Close() error {
-	it.sub.Unsubscribe()
-	return nil
-}
-
-// BSCSwapAgentSwapPairCreated represents a SwapPairCreated event raised by the BSCSwapAgent contract.
-type BSCSwapAgentSwapPairCreated struct {
-	EthRegisterTxHash [32]byte
-	Bep20Addr         common.Address
-	Erc20Addr         common.Address
-	Symbol            string
-	Name              string
-	Decimals          uint8
-	Raw               types.Log // Blockchain specific contextual infos
-}
-
-// FilterSwapPairCreated is a free log retrieval operation binding the contract event 0xcc0314763eabceb74cd3d30ae785c09bfe4e204af2088b3bfcdbbe5082133db5.
-//
-// Solidity: event SwapPairCreated(bytes32 indexed ethRegisterTxHash, address indexed bep20Addr, address indexed erc20Addr, string symbol, string name, uint8 decimals)
-func (_BSCSwapAgent *BSCSwapAgentFilterer) FilterSwapPairCreated(opts *bind.FilterOpts, ethRegisterTxHash [][32]byte, bep20Addr []common.Address, erc20Addr []common.Address) (*BSCSwapAgentSwapPairCreatedIterator, error) {
-
-	var ethRegisterTxHashRule []interface{}
-	for _, ethRegisterTxHashItem := range ethRegisterTxHash {
-		ethRegisterTxHashRule = append(ethRegisterTxHashRule, ethRegisterTxHashItem)
-	}
-	var bep20AddrRule []interface{}
-	for _, bep20AddrItem := range bep20Addr {
-		bep20AddrRule = append(bep20AddrRule, bep20AddrItem)
-	}
-	var erc20AddrRule []interface{}
-	for _, erc20AddrItem := range erc20Addr {
-		erc20AddrRule = append(erc20AddrRule, erc20AddrItem)
-	}
-
-	logs, sub, err := _BSCSwapAgent.contract.FilterLogs(opts, "SphynxSwapPairCreated", ethRegisterTxHashRule, bep20AddrRule, erc20AddrRule)
-	if err != nil {
-		return nil, err
-	}
-	return &BSCSwapAgentSwapPairCreatedIterator{contract: _BSCSwapAgent.contract, event: "SphynxSwapPairCreated", logs: logs, sub: sub}, nil
-}
-
-// WatchSwapPairCreated is a free log subscription operation binding the contract event 0xcc0314763eabceb74cd3d30ae785c09bfe4e204af2088b3bfcdbbe5082133db5.
-//
-// Solidity: event SwapPairCreated(bytes32 indexed ethRegisterTxHash, address indexed bep20Addr, address indexed erc20Addr, string symbol, string name, uint8 decimals)
-func (_BSCSwapAgent *BSCSwapAgentFilterer) WatchSwapPairCreated(opts *bind.WatchOpts, sink chan<- *BSCSwapAgentSwapPairCreated, ethRegisterTxHash [][32]byte, bep20Addr []common.Address, erc20Addr []common.Address) (event.Subscription, error) {
-
-	var ethRegisterTxHashRule []interface{}
-	for _, ethRegisterTxHashItem := range ethRegisterTxHash {
-		ethRegisterTxHashRule = append(ethRegisterTxHashRule, ethRegisterTxHashItem)
-	}
-	var bep20AddrRule []interface{}
-	for _, bep20AddrItem := range bep20Addr {
-		bep20AddrRule = append(bep20AddrRule, bep20AddrItem)
-	}
-	var erc20AddrRule []interface{}
-	for _, erc20AddrItem := range erc20Addr {
-		erc20AddrRule = append(erc20AddrRule, erc20AddrItem)
-	}
-
-	logs, sub, err := _BSCSwapAgent.contract.WatchLogs(opts, "SphynxSwapPairCreated", ethRegisterTxHashRule, bep20AddrRule, erc20AddrRule)
-	if err != nil {
-		return nil, err
-	}
-	return event.NewSubscription(func(quit <-chan struct{}) error {
-		defer sub.Unsubscribe()
-		for {
-			select {
-			case log := <-logs:
-				// New log arrived, parse the event and forward to the user
-				event := new(BSCSwapAgentSwapPairCreated)
-				if err := _BSCSwapAgent.contract.UnpackLog(event, "SphynxSwapPairCreated", log); err != nil {
-					return err
-				}
-				event.Raw = log
-
-				select {
-				case sink <- event:
-				case err := <-sub.Err():
-					return err
-				case <-quit:
-					return nil
-				}
-			case err := <-sub.Err():
-				return err
-			case <-quit:
-				return nil
-			}
-		}
-	}), nil
-}
-
 // BSCSwapAgentSwapStartedIterator is returned from FilterSwapStarted and is used to iterate over the raw logs and unpacked data for SwapStarted events raised by the BSCSwapAgent contract.
 type BSCSwapAgentSwapStartedIterator struct {
 	Event *BSCSwapAgentSwapStarted // Event containing the contract specifics and raw log
@@ -1037,58 +825,57 @@ func (it *BSCSwapAgentSwapStartedIterator) Close() error {
 
 // BSCSwapAgentSwapStarted represents a SwapStarted event raised by the BSCSwapAgent contract.
 type BSCSwapAgentSwapStarted struct {
-	Bep20Addr common.Address
-	Erc20Addr common.Address
-	FromAddr  common.Address
-	Amount    *big.Int
-	FeeAmount *big.Int
-	Raw       types.Log // Blockchain specific contextual infos
+	FromChainId *big.Int
+	ToChainId   *big.Int
+	FromAddress common.Address
+	Amount      *big.Int
+	Raw         types.Log // Blockchain specific contextual infos
 }
 
-// FilterSwapStarted is a free log retrieval operation binding the contract event 0x49c08ff11118922c1e8298915531eff9ef6f8b39b44b3e9952b75d47e1d0cdd0.
+// FilterSwapStarted is a free log retrieval operation binding the contract event 0x7b2b39fe8cb99baf3c533665217a130daefeee1af6329eca59c5bf06a53999ac.
 //
-// Solidity: event SwapStarted(address indexed bep20Addr, address indexed erc20Addr, address indexed fromAddr, uint256 amount, uint256 feeAmount)
-func (_BSCSwapAgent *BSCSwapAgentFilterer) FilterSwapStarted(opts *bind.FilterOpts, bep20Addr []common.Address, erc20Addr []common.Address, fromAddr []common.Address) (*BSCSwapAgentSwapStartedIterator, error) {
+// Solidity: event SwapStarted(uint256 fromChainId, uint256 indexed toChainId, address indexed fromAddress, uint256 indexed amount)
+func (_BSCSwapAgent *BSCSwapAgentFilterer) FilterSwapStarted(opts *bind.FilterOpts, toChainId []*big.Int, fromAddress []common.Address, amount []*big.Int) (*BSCSwapAgentSwapStartedIterator, error) {
 
-	var bep20AddrRule []interface{}
-	for _, bep20AddrItem := range bep20Addr {
-		bep20AddrRule = append(bep20AddrRule, bep20AddrItem)
+	var toChainIdRule []interface{}
+	for _, toChainIdItem := range toChainId {
+		toChainIdRule = append(toChainIdRule, toChainIdItem)
 	}
-	var erc20AddrRule []interface{}
-	for _, erc20AddrItem := range erc20Addr {
-		erc20AddrRule = append(erc20AddrRule, erc20AddrItem)
+	var fromAddressRule []interface{}
+	for _, fromAddressItem := range fromAddress {
+		fromAddressRule = append(fromAddressRule, fromAddressItem)
 	}
-	var fromAddrRule []interface{}
-	for _, fromAddrItem := range fromAddr {
-		fromAddrRule = append(fromAddrRule, fromAddrItem)
+	var amountRule []interface{}
+	for _, amountItem := range amount {
+		amountRule = append(amountRule, amountItem)
 	}
 
-	logs, sub, err := _BSCSwapAgent.contract.FilterLogs(opts, "SphynxSwapStarted", bep20AddrRule, erc20AddrRule, fromAddrRule)
+	logs, sub, err := _BSCSwapAgent.contract.FilterLogs(opts, "SwapStarted", toChainIdRule, fromAddressRule, amountRule)
 	if err != nil {
 		return nil, err
 	}
-	return &BSCSwapAgentSwapStartedIterator{contract: _BSCSwapAgent.contract, event: "SphynxSwapStarted", logs: logs, sub: sub}, nil
+	return &BSCSwapAgentSwapStartedIterator{contract: _BSCSwapAgent.contract, event: "SwapStarted", logs: logs, sub: sub}, nil
 }
 
-// WatchSwapStarted is a free log subscription operation binding the contract event 0x49c08ff11118922c1e8298915531eff9ef6f8b39b44b3e9952b75d47e1d0cdd0.
+// WatchSwapStarted is a free log subscription operation binding the contract event 0x7b2b39fe8cb99baf3c533665217a130daefeee1af6329eca59c5bf06a53999ac.
 //
-// Solidity: event SwapStarted(address indexed bep20Addr, address indexed erc20Addr, address indexed fromAddr, uint256 amount, uint256 feeAmount)
-func (_BSCSwapAgent *BSCSwapAgentFilterer) WatchSwapStarted(opts *bind.WatchOpts, sink chan<- *BSCSwapAgentSwapStarted, bep20Addr []common.Address, erc20Addr []common.Address, fromAddr []common.Address) (event.Subscription, error) {
+// Solidity: event SwapStarted(uint256 fromChainId, uint256 indexed toChainId, address indexed fromAddress, uint256 indexed amount)
+func (_BSCSwapAgent *BSCSwapAgentFilterer) WatchSwapStarted(opts *bind.WatchOpts, sink chan<- *BSCSwapAgentSwapStarted, toChainId []*big.Int, fromAddress []common.Address, amount []*big.Int) (event.Subscription, error) {
 
-	var bep20AddrRule []interface{}
-	for _, bep20AddrItem := range bep20Addr {
-		bep20AddrRule = append(bep20AddrRule, bep20AddrItem)
+	var toChainIdRule []interface{}
+	for _, toChainIdItem := range toChainId {
+		toChainIdRule = append(toChainIdRule, toChainIdItem)
 	}
-	var erc20AddrRule []interface{}
-	for _, erc20AddrItem := range erc20Addr {
-		erc20AddrRule = append(erc20AddrRule, erc20AddrItem)
+	var fromAddressRule []interface{}
+	for _, fromAddressItem := range fromAddress {
+		fromAddressRule = append(fromAddressRule, fromAddressItem)
 	}
-	var fromAddrRule []interface{}
-	for _, fromAddrItem := range fromAddr {
-		fromAddrRule = append(fromAddrRule, fromAddrItem)
+	var amountRule []interface{}
+	for _, amountItem := range amount {
+		amountRule = append(amountRule, amountItem)
 	}
 
-	logs, sub, err := _BSCSwapAgent.contract.WatchLogs(opts, "SphynxSwapStarted", bep20AddrRule, erc20AddrRule, fromAddrRule)
+	logs, sub, err := _BSCSwapAgent.contract.WatchLogs(opts, "SwapStarted", toChainIdRule, fromAddressRule, amountRule)
 	if err != nil {
 		return nil, err
 	}
@@ -1120,13 +907,14 @@ func (_BSCSwapAgent *BSCSwapAgentFilterer) WatchSwapStarted(opts *bind.WatchOpts
 	}), nil
 }
 
-// ParseSwapStarted is a log parse operation binding the contract event 0x49c08ff11118922c1e8298915531eff9ef6f8b39b44b3e9952b75d47e1d0cdd0.
+// ParseSwapStarted is a log parse operation binding the contract event 0x7b2b39fe8cb99baf3c533665217a130daefeee1af6329eca59c5bf06a53999ac.
 //
-// Solidity: event SwapStarted(address indexed bep20Addr, address indexed erc20Addr, address indexed fromAddr, uint256 amount, uint256 feeAmount)
+// Solidity: event SwapStarted(uint256 fromChainId, uint256 indexed toChainId, address indexed fromAddress, uint256 indexed amount)
 func (_BSCSwapAgent *BSCSwapAgentFilterer) ParseSwapStarted(log types.Log) (*BSCSwapAgentSwapStarted, error) {
 	event := new(BSCSwapAgentSwapStarted)
-	if err := _BSCSwapAgent.contract.UnpackLog(event, "SphynxSwapStarted", log); err != nil {
+	if err := _BSCSwapAgent.contract.UnpackLog(event, "SwapStarted", log); err != nil {
 		return nil, err
 	}
+	event.Raw = log
 	return event, nil
 }
@@ -4,6 +4,7 @@
 package abi
 
 import (
+	"errors"
 	"math/big"
 	"strings"
 
@@ -17,18 +18,25 @@ import (
 
 // Reference imports to suppress errors if they are not otherwise used.
 var (
+	_ = errors.New
 	_ = big.NewInt
 	_ = strings.NewReader
 	_ = ethereum.NotFound
-	_ = abi.U256
 	_ = bind.Bind
 	_ = common.Big1
 	_ = types.BloomLookup
 	_ = event.NewSubscription
 )
 
+// ETHSwapAgentMetaData contains all meta data concerning the ETHSwapAgent contract.
+var ETHSwapAgentMetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"fee\",\"type\":\"uint256\"}],\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"previousOwner\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"newOwner\",\"type\":\"address\"}],\"name\":\"OwnershipTransferred\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"fromChainId\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"toChainId\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"fromAddress\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"SwapFilled\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"fromChainId\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"toChainId\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"fromAddress\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"SwapStarted\",\"type\":\"event\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"fromChainId\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"toChainId\",\"type\":\"uint256\"},{\"internalType\":\"address\",\"name\":\"toAddress\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"fillSwap\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"owner\",\"outputs\":[{\"internalType\":\"addresspayable\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"renounceOwnership\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"merkleRoot\",\"type\":\"bytes32\"}],\"name\":\"setOwnerMerkleRoot\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"fee\",\"type\":\"uint256\"}],\"name\":\"setSwapFee\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"chainId\",\"type\":\"uint256\"},{\"internalType\":\"address\",\"name\":\"tokenAddress\",\"type\":\"address\"}],\"name\":\"setToken\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"fromChainId\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"toChainId\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"swap\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"payable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"swapFee\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"tokenAddresses\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"addresspayable\",\"name\":\"newOwner\",\"type\":\"address\"}],\"name\":\"transferOwnership\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"chainId\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"},{\"internalType\":\"bytes32[]\",\"name\":\"merkleProof\",\"type\":\"bytes32[]\"}],\"name\":\"withdrawTokens\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]",
+}
+
 // ETHSwapAgentABI is the input ABI used to generate the binding from.
-const ETHSwapAgentABI = "[{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"fee\",\"type\":\"uint256\"}],\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"previousOwner\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"newOwner\",\"type\":\"address\"}],\"name\":\"OwnershipTransferred\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"fromChainId\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"toChainId\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"fromAddress\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"SwapFilled\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"fromChainId\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"toChainId\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"fromAddress\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"SwapStarted\",\"type\":\"event\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"fromChainId\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"toChainId\",\"type\":\"uint256\"},{\"internalType\":\"address\",\"name\":\"toAddress\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"fillSwap\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"owner\",\"outputs\":[{\"internalType\":\"address payable\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"renounceOwnership\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"merkleRoot\",\"type\":\"bytes32\"}],\"name\":\"setOwnerMerkleRoot\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"fee\",\"type\":\"uint256\"}],\"name\":\"setSwapFee\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"chainId\",\"type\":\"uint256\"},{\"internalType\":\"address\",\"name\":\"tokenAddress\",\"type\":\"address\"}],\"name\":\"setToken\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"fromChainId\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"toChainId\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"swap\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"payable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"swapFee\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"tokenAddresses\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address payable\",\"name\":\"newOwner\",\"type\":\"address\"}],\"name\":\"transferOwnership\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"chainId\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"},{\"internalType\":\"bytes32[]\",\"name\":\"merkleProof\",\"type\":\"bytes32[]\"}],\"name\":\"withdrawTokens\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]"
+// Deprecated: Use ETHSwapAgentMetaData.ABI instead.
+var ETHSwapAgentABI = ETHSwapAgentMetaData.ABI
+
 // ETHSwapAgent is an auto generated Go binding around an Ethereum contract.
 type ETHSwapAgent struct {
 	ETHSwapAgentCaller     // Read-only binding to the contract
@@ -137,7 +145,7 @@ func bindETHSwapAgent(address common.Address, caller bind.ContractCaller, transa
 // sets the output to result. The result type might be a single field for simple
 // returns, a slice of interfaces for anonymous returns and a struct for named
 // returns.
-func (_ETHSwapAgent *ETHSwapAgentRaw) Call(opts *bind.CallOpts, result interface{}, method string, params ...interface{}) error {
+func (_ETHSwapAgent *ETHSwapAgentRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
 	return _ETHSwapAgent.Contract.ETHSwapAgentCaller.contract.Call(opts, result, method, params...)
 }
 
@@ -156,7 +164,7 @@ func (_ETHSwapAgent *ETHSwapAgentRaw) Transact(opts *bind.TransactOpts, method s
 // sets the output to result. The result type might be a single field for simple
 // returns, a slice of interfaces for anonymous returns and a struct for named
 // returns.
-func (_ETHSwapAgent *ETHSwapAgentCallerRaw) Call(opts *bind.CallOpts, result interface{}, method string, params ...interface{}) error {
+func (_ETHSwapAgent *ETHSwapAgentCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
 	return _ETHSwapAgent.Contract.contract.Call(opts, result, method, params...)
 }
 
@@ -171,171 +179,118 @@ func (_ETHSwapAgent *ETHSwapAgentTransactorRaw) Transact(opts *bind.TransactOpts
 	return _ETHSwapAgent.Contract.contract.Transact(opts, method, params...)
 }
 
-// FilledBSCTx is a free data retrieval call binding the contract method 0x50877c77.
+// Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
 //
-// Solidity: function filledBSCTx(bytes32 ) constant returns(bool)
-func (_ETHSwapAgent *ETHSwapAgentCaller) FilledBSCTx(opts *bind.CallOpts, arg0 [32]byte) (bool, error) {
-	var (
-		ret0 = new(bool)
-	)
-	out := ret0
-	err := _ETHSwapAgent.contract.Call(opts, out, "filledBSCTx", arg0)
-	return *ret0, err
-}
+// Solidity: function owner() view returns(address)
+func (_ETHSwapAgent *ETHSwapAgentCaller) Owner(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _ETHSwapAgent.contract.Call(opts, &out, "owner")
 
-// FilledBSCTx is a free data retrieval call binding the contract method 0x50877c77.
-//
-// Solidity: function filledBSCTx(bytes32 ) constant returns(bool)
-func (_ETHSwapAgent *ETHSwapAgentSession) FilledBSCTx(arg0 [32]byte) (bool, error) {
-	return _ETHSwapAgent.Contract.FilledBSCTx(&_ETHSwapAgent.CallOpts, arg0)
-}
+	if err != nil {
+		return *new(common.Address), err
+	}
 
-// FilledBSCTx is a free data retrieval call binding the contract method 0x50877c77.
-//
-// Solidity: function filledBSCTx(bytes32 ) constant returns(bool)
-func (_ETHSwapAgent *ETHSwapAgentCallerSession) FilledBSCTx(arg0 [32]byte) (bool, error) {
-	return _ETHSwapAgent.Contract.FilledBSCTx(&_ETHSwapAgent.CallOpts, arg0)
-}
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
 
-// Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
-//
-// Solidity: function owner() constant returns(address)
-func (_ETHSwapAgent *ETHSwapAgentCaller) Owner(opts *bind.CallOpts) (common.Address, error) {
-	var (
-		ret0 = new(common.Address)
-	)
-	out := ret0
-	err := _ETHSwapAgent.contract.Call(opts, out, "owner")
-	return *ret0, err
 }
 
 // Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
 //
-// Solidity: function owner() constant returns(address)
+// Solidity: function owner() view returns(address)
 func (_ETHSwapAgent *ETHSwapAgentSession) Owner() (common.Address, error) {
 	return _ETHSwapAgent.Contract.Owner(&_ETHSwapAgent.CallOpts)
 }
 
 // Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
 //
-// Solidity: function owner() constant returns(address)
+// Solidity: function owner() view returns(address)
 func (_ETHSwapAgent *ETHSwapAgentCallerSession) Owner() (common.Address, error) {
 	return _ETHSwapAgent.Contract.Owner(&_ETHSwapAgent.CallOpts)
 }
 
-// RegisteredERC20 is a free data retrieval call binding the contract method 0x89b15604.
+// SwapFee is a free data retrieval call binding the contract method 0x54cf2aeb.
 //
-// Solidity: function registeredERC20(address ) constant returns(bool)
-func (_ETHSwapAgent *ETHSwapAgentCaller) RegisteredERC20(opts *bind.CallOpts, arg0 common.Address) (bool, error) {
-	var (
-		ret0 = new(bool)
-	)
-	out := ret0
-	err := _ETHSwapAgent.contract.Call(opts, out, "registeredERC20", arg0)
-	return *ret0, err
-}
+// Solidity: function swapFee() view returns(uint256)
+func (_ETHSwapAgent *ETHSwapAgentCaller) SwapFee(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _ETHSwapAgent.contract.Call(opts, &out, "swapFee")
 
-// RegisteredERC20 is a free data retrieval call binding the contract method 0x89b15604.
-//
-// Solidity: function registeredERC20(address ) constant returns(bool)
-func (_ETHSwapAgent *ETHSwapAgentSession) RegisteredERC20(arg0 common.Address) (bool, error) {
-	return _ETHSwapAgent.Contract.RegisteredERC20(&_ETHSwapAgent.CallOpts, arg0)
-}
+	if err != nil {
+		return *new(*big.Int), err
+	}
 
-// RegisteredERC20 is a free data retrieval call binding the contract method 0x89b15604.
-//
-// Solidity: function registeredERC20(address ) constant returns(bool)
-func (_ETHSwapAgent *ETHSwapAgentCallerSession) RegisteredERC20(arg0 common.Address) (bool, error) {
-	return _ETHSwapAgent.Contract.RegisteredERC20(&_ETHSwapAgent.CallOpts, arg0)
-}
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
 
-// SwapFee is a free data retrieval call binding the contract method 0x54cf2aeb.
-//
-// Solidity: function swapFee() constant returns(uint256)
-func (_ETHSwapAgent *ETHSwapAgentCaller) SwapFee(opts *bind.CallOpts) (*big.Int, error) {
-	var (
-		ret0 = new(*big.Int)
-	)
-	out := ret0
-	err := _ETHSwapAgent.contract.Call(opts, out, "swapFee")
-	return *ret0, err
 }
 
 // SwapFee is a free data retrieval call binding the contract method 0x54cf2aeb.
 //
-// Solidity: function swapFee() constant returns(uint256)
+// Solidity: function swapFee() view returns(uint256)
 func (_ETHSwapAgent *ETHSwapAgentSession) SwapFee() (*big.Int, error) {
 	return _ETHSwapAgent.Contract.SwapFee(&_ETHSwapAgent.CallOpts)
 }
 
 // SwapFee is a free data retrieval call binding the contract method 0x54cf2aeb.
 //
-// Solidity: function swapFee() constant returns(uint256)
+// Solidity: function swapFee() view returns(uint256)
 func (_ETHSwapAgent *ETHSwapAgentCallerSession) SwapFee() (*big.Int, error) {
 	return _ETHSwapAgent.Contract.SwapFee(&_ETHSwapAgent.CallOpts)
 }
 
-// FillBSC2ETHSwap is a paid mutator transaction binding the contract method 0x9867df11.
+// TokenAddresses is a free data retrieval call binding the contract method 0xe5df8b84.
 //
-// Solidity: function fillBSC2ETHSwap(bytes32 bscTxHash, address erc20Addr, address toAddress, uint256 amount) returns(bool)
-func (_ETHSwapAgent *ETHSwapAgentTransactor) FillBSC2ETHSwap(opts *bind.TransactOpts, bscTxHash [32]byte, erc20Addr common.Address, toAddress common.Address, amount *big.Int) (*types.Transaction, error) {
-	return _ETHSwapAgent.contract.Transact(opts, "fillBSC2ETHSwap", bscTxHash, erc20Addr, toAddress, amount)
-}
+// Solidity: function tokenAddresses(uint256 ) view returns(address)
+func (_ETHSwapAgent *ETHSwapAgentCaller) TokenAddresses(opts *bind.CallOpts, arg0 *big.Int) (common.Address, error) {
+	var out []interface{}
+	err := _ETHSwapAgent.contract.Call(opts, &out, "tokenAddresses", arg0)
 
-// FillBSC2ETHSwap is a paid mutator transaction binding the contract method 0x9867df11.
-//
-// Solidity: function fillBSC2ETHSwap(bytes32 bscTxHash, address erc20Addr, address toAddress, uint256 amount) returns(bool)
-func (_ETHSwapAgent *ETHSwapAgentSession) FillBSC2ETHSwap(bscTxHash [32]byte, erc20Addr common.Address, toAddress common.Address, amount *big.Int) (*types.Transaction, error) {
-	return _ETHSwapAgent.Contract.FillBSC2ETHSwap(&_ETHSwapAgent.TransactOpts, bscTxHash, erc20Addr, toAddress, amount)
-}
+	if err != nil {
+		return *new(common.Address), err
+	}
 
-// FillBSC2ETHSwap is a paid mutator transaction binding the contract method 0x9867df11.
-//
-// Solidity: function fillBSC2ETHSwap(bytes32 bscTxHash, address erc20Addr, address toAddress, uint256 amount) returns(bool)
-func (_ETHSwapAgent *ETHSwapAgentTransactorSession) FillBSC2ETHSwap(bscTxHash [32]byte, erc20Addr common.Address, toAddress common.Address, amount *big.Int) (*types.Transaction, error) {
-	return _ETHSwapAgent.Contract.FillBSC2ETHSwap(&_ETHSwapAgent.TransactOpts, bscTxHash, erc20Addr, toAddress, amount)
-}
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
 
-// Initialize is a paid mutator transaction binding the contract method 0xda35a26f.
-//
-// Solidity: function initialize(uint256 fee, address ownerAddr) returns()
-func (_ETHSwapAgent *ETHSwapAgentTransactor) Initialize(opts *bind.TransactOpts, fee *big.Int, ownerAddr common.Address) (*types.Transaction, error) {
-	return _ETHSwapAgent.contract.Transact(opts, "initialize", fee, ownerAddr)
 }
 
-// Initialize is a paid mutator transaction binding the contract method 0xda35a26f.
+// TokenAddresses is a free data retrieval call binding the contract method 0xe5df8b84.
 //
-// Solidity: function initialize(uint256 fee, address ownerAddr) returns()
-func (_ETHSwapAgent *ETHSwapAgentSession) Initialize(fee *big.Int, ownerAddr common.Address) (*types.Transaction, error) {
-	return _ETHSwapAgent.Contract.Initialize(&_ETHSwapAgent.TransactOpts, fee, ownerAddr)
+// Solidity: function tokenAddresses(uint256 ) view returns(address)
+func (_ETHSwapAgent *ETHSwapAgentSession) TokenAddresses(arg0 *big.Int) (common.Address, error) {
+	return _ETHSwapAgent.Contract.TokenAddresses(&_ETHSwapAgent.CallOpts, arg0)
 }
 
-// Initialize is a paid mutator transaction binding the contract method 0xda35a26f.
+// TokenAddresses is a free data retrieval call binding the contract method 0xe5df8b84.
 //
-// Solidity: function initialize(uint256 fee, address ownerAddr) returns()
-func (_ETHSwapAgent *ETHSwapAgentTransactorSession) Initialize(fee *big.Int, ownerAddr common.Address) (*types.Transaction, error) {
-	return _ETHSwapAgent.Contract.Initialize(&_ETHSwapAgent.TransactOpts, fee, ownerAddr)
+// Solidity: function tokenAddresses(uint256 ) view returns(address)
+func (_ETHSwapAgent *ETHSwapAgentCallerSession) TokenAddresses(arg0 *big.Int) (common.Address, error) {
+	return _ETHSwapAgent.Contract.TokenAddresses(&_ETHSwapAgent.CallOpts, arg0)
 }
 
-// RegisterSwapPairToBSC is a paid mutator transaction binding the contract method 0x5c13c151.
+// FillSwap is a paid mutator transaction binding the contract method 0x9528d9e8.
 //
-// Solidity: function registerSwapPairToBSC(address erc20Addr) returns(bool)
-func (_ETHSwapAgent *ETHSwapAgentTransactor) RegisterSwapPairToBSC(opts *bind.TransactOpts, erc20Addr common.Address) (*types.Transaction, error) {
-	return _ETHSwapAgent.contract.Transact(opts, "registerSwapPairToBSC", erc20Addr)
+// Solidity: function fillSwap(uint256 fromChainId, uint256 toChainId, address toAddress, uint256 amount) returns(bool)
+func (_ETHSwapAgent *ETHSwapAgentTransactor) FillSwap(opts *bind.TransactOpts, fromChainId *big.Int, toChainId *big.Int, toAddress common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _ETHSwapAgent.contract.Transact(opts, "fillSwap", fromChainId, toChainId, toAddress, amount)
 }
 
-// RegisterSwapPairToBSC is a paid mutator transaction binding the contract method 0x5c13c151.
+// FillSwap is a paid mutator transaction binding the contract method 0x9528d9e8.
 //
-// Solidity: function registerSwapPairToBSC(address erc20Addr) returns(bool)
-func (_ETHSwapAgent *ETHSwapAgentSession) RegisterSwapPairToBSC(erc20Addr common.Address) (*types.Transaction, error) {
-	return _ETHSwapAgent.Contract.RegisterSwapPairToBSC(&_ETHSwapAgent.TransactOpts, erc20Addr)
+// Solidity: function fillSwap(uint256 fromChainId, uint256 toChainId, address toAddress, uint256 amount) returns(bool)
+func (_ETHSwapAgent *ETHSwapAgentSession) FillSwap(fromChainId *big.Int, toChainId *big.Int, toAddress common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _ETHSwapAgent.Contract.FillSwap(&_ETHSwapAgent.TransactOpts, fromChainId, toChainId, toAddress, amount)
 }
 
-// RegisterSwapPairToBSC is a paid mutator transaction binding the contract method 0x5c13c151.
+// FillSwap is a paid mutator transaction binding the contract method 0x9528d9e8.
 //
-// Solidity: function registerSwapPairToBSC(address erc20Addr) returns(bool)
-func (_ETHSwapAgent *ETHSwapAgentTransactorSession) RegisterSwapPairToBSC(erc20Addr common.Address) (*types.Transaction, error) {
-	return _ETHSwapAgent.Contract.RegisterSwapPairToBSC(&_ETHSwapAgent.TransactOpts, erc20Addr)
+// Solidity: function fillSwap(uint256 fromChainId, uint256 toChainId, address toAddress, uint256 amount) returns(bool)
+func (_ETHSwapAgent *ETHSwapAgentTransactorSession) FillSwap(fromChainId *big.Int, toChainId *big.Int, toAddress common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _ETHSwapAgent.Contract.FillSwap(&_ETHSwapAgent.TransactOpts, fromChainId, toChainId, toAddress, amount)
 }
 
 // RenounceOwnership is a paid mutator transaction binding the contract method 0x715018a6.
@@ -359,6 +314,27 @@ func (_ETHSwapAgent *ETHSwapAgentTransactorSession) RenounceOwnership() (*types.
 	return _ETHSwapAgent.Contract.RenounceOwnership(&_ETHSwapAgent.TransactOpts)
 }
 
+// SetOwnerMerkleRoot is a paid mutator transaction binding the contract method 0x82762545.
+//
+// Solidity: function setOwnerMerkleRoot(bytes32 merkleRoot) returns()
+func (_ETHSwapAgent *ETHSwapAgentTransactor) SetOwnerMerkleRoot(opts *bind.TransactOpts, merkleRoot [32]byte) (*types.Transaction, error) {
+	return _ETHSwapAgent.contract.Transact(opts, "setOwnerMerkleRoot", merkleRoot)
+}
+
+// SetOwnerMerkleRoot is a paid mutator transaction binding the contract method 0x82762545.
+//
+// Solidity: function setOwnerMerkleRoot(bytes32 merkleRoot) returns()
+func (_ETHSwapAgent *ETHSwapAgentSession) SetOwnerMerkleRoot(merkleRoot [32]byte) (*types.Transaction, error) {
+	return _ETHSwapAgent.Contract.SetOwnerMerkleRoot(&_ETHSwapAgent.TransactOpts, merkleRoot)
+}
+
+// SetOwnerMerkleRoot is a paid mutator transaction binding the contract method 0x82762545.
+//
+// Solidity: function setOwnerMerkleRoot(bytes32 merkleRoot) returns()
+func (_ETHSwapAgent *ETHSwapAgentTransactorSession) SetOwnerMerkleRoot(merkleRoot [32]byte) (*types.Transaction, error) {
+	return _ETHSwapAgent.Contract.SetOwnerMerkleRoot(&_ETHSwapAgent.TransactOpts, merkleRoot)
+}
+
 // SetSwapFee is a paid mutator transaction binding the contract method 0x34e19907.
 //
 // Solidity: function setSwapFee(uint256 fee) returns()
@@ -380,25 +356,46 @@ func (_ETHSwapAgent *ETHSwapAgentTransactorSession) SetSwapFee(fee *big.Int) (*t
 	return _ETHSwapAgent.Contract.SetSwapFee(&_ETHSwapAgent.TransactOpts, fee)
 }
 
-// SwapETH2BSC is a paid mutator transaction binding the contract method 0xb9927a9c.
+// SetToken is a paid mutator transaction binding the contract method 0xaf906cf3.
+//
+// Solidity: function setToken(uint256 chainId, address tokenAddress) returns()
+func (_ETHSwapAgent *ETHSwapAgentTransactor) SetToken(opts *bind.TransactOpts, chainId *big.Int, tokenAddress common.Address) (*types.Transaction, error) {
+	return _ETHSwapAgent.contract.Transact(opts, "setToken", chainId, tokenAddress)
+}
+
+// SetToken is a paid mutator transaction binding the contract method 0xaf906cf3.
+//
+// Solidity: function setToken(uint256 chainId, address tokenAddress) returns()
+func (_ETHSwapAgent *ETHSwapAgentSession) SetToken(chainId *big.Int, tokenAddress common.Address) (*types.Transaction, error) {
+	return _ETHSwapAgent.Contract.SetToken(&_ETHSwapAgent.TransactOpts, chainId, tokenAddress)
+}
+
+// SetToken is a paid mutator transaction binding the contract method 0xaf906cf3.
+//
+// Solidity: function setToken(uint256 chainId, address tokenAddress) returns()
+func (_ETHSwapAgent *ETHSwapAgentTransactorSession) SetToken(chainId *big.Int, tokenAddress common.Address) (*types.Transaction, error) {
+	return _ETHSwapAgent.Contract.SetToken(&_ETHSwapAgent.TransactOpts, chainId, tokenAddress)
+}
+
+// Swap is a paid mutator transaction binding the contract method 0x9d9892cd.
 //
-// Solidity: function swapETH2BSC(address erc20Addr, uint256 amount) returns(bool)
-func (_ETHSwapAgent *ETHSwapAgentTransactor) SwapETH2BSC(opts *bind.TransactOpts, erc20Addr common.Address, amount *big.Int) (*types.Transaction, error) {
-	return _ETHSwapAgent.contract.Transact(opts, "swapETH2BSC", erc20Addr, amount)
+// Solidity: function swap(uint256 fromChainId, uint256 toChainId, uint256 amount) payable returns(bool)
+func (_ETHSwapAgent *ETHSwapAgentTransactor) Swap(opts *bind.TransactOpts, fromChainId *big.Int, toChainId *big.Int, amount *big.Int) (*types.Transaction, error) {
+	return _ETHSwapAgent.contract.Transact(opts, "swap", fromChainId, toChainId, amount)
 }
 
-// SwapETH2BSC is a paid mutator transaction binding the contract method 0xb9927a9c.
+// Swap is a paid mutator transaction binding the contract method 0x9d9892cd.
 //
-// Solidity: function swapETH2BSC(address erc20Addr, uint256 amount) returns(bool)
-func (_ETHSwapAgent *ETHSwapAgentSession) SwapETH2BSC(erc20Addr common.Address, amount *big.Int) (*types.Transaction, error) {
-	return _ETHSwapAgent.Contract.SwapETH2BSC(&_ETHSwapAgent.TransactOpts, erc20Addr, amount)
+// Solidity: function swap(uint256 fromChainId, uint256 toChainId, uint256 amount) payable returns(bool)
+func (_ETHSwapAgent *ETHSwapAgentSession) Swap(fromChainId *big.Int, toChainId *big.Int, amount *big.Int) (*types.Transaction, error) {
+	return _ETHSwapAgent.Contract.Swap(&_ETHSwapAgent.TransactOpts, fromChainId, toChainId, amount)
 }
 
-// SwapETH2BSC is a paid mutator transaction binding the contract method 0xb9927a9c.
+// Swap is a paid mutator transaction binding the contract method 0x9d9892cd.
 //
-// Solidity: function swapETH2BSC(address erc20Addr, uint256 amount) returns(bool)
-func (_ETHSwapAgent *ETHSwapAgentTransactorSession) SwapETH2BSC(erc20Addr common.Address, amount *big.Int) (*types.Transaction, error) {
-	return _ETHSwapAgent.Contract.SwapETH2BSC(&_ETHSwapAgent.TransactOpts, erc20Addr, amount)
+// Solidity: function swap(uint256 fromChainId, uint256 toChainId, uint256 amount) payable returns(bool)
+func (_ETHSwapAgent *ETHSwapAgentTransactorSession) Swap(fromChainId *big.Int, toChainId *big.Int, amount *big.Int) (*types.Transaction, error) {
+	return _ETHSwapAgent.Contract.Swap(&_ETHSwapAgent.TransactOpts, fromChainId, toChainId, amount)
 }
 
 // TransferOwnership is a paid mutator transaction binding the contract method 0xf2fde38b.
@@ -422,6 +419,27 @@ func (_ETHSwapAgent *ETHSwapAgentTransactorSession) TransferOwnership(newOwner c
 	return _ETHSwapAgent.Contract.TransferOwnership(&_ETHSwapAgent.TransactOpts, newOwner)
 }
 
+// WithdrawTokens is a paid mutator transaction binding the contract method 0x4d606013.
+//
+// Solidity: function withdrawTokens(uint256 chainId, uint256 amount, bytes32[] merkleProof) returns(bool)
+func (_ETHSwapAgent *ETHSwapAgentTransactor) WithdrawTokens(opts *bind.TransactOpts, chainId *big.Int, amount *big.Int, merkleProof [][32]byte) (*types.Transaction, error) {
+	return _ETHSwapAgent.contract.Transact(opts, "withdrawTokens", chainId, amount, merkleProof)
+}
+
+// WithdrawTokens is a paid mutator transaction binding the contract method 0x4d606013.
+//
+// Solidity: function withdrawTokens(uint256 chainId, uint256 amount, bytes32[] merkleProof) returns(bool)
+func (_ETHSwapAgent *ETHSwapAgentSession) WithdrawTokens(chainId *big.Int, amount *big.Int, merkleProof [][32]byte) (*types.Transaction, error) {
+	return _ETHSwapAgent.Contract.WithdrawTokens(&_ETHSwapAgent.TransactOpts, chainId, amount, merkleProof)
+}
+
+// WithdrawTokens is a paid mutator transaction binding the contract method 0x4d606013.
+//
+// Solidity: function withdrawTokens(uint256 chainId, uint256 amount, bytes32[] merkleProof) returns(bool)
+func (_ETHSwapAgent *ETHSwapAgentTransactorSession) WithdrawTokens(chainId *big.Int, amount *big.Int, merkleProof [][32]byte) (*types.Transaction, error) {
+	return _ETHSwapAgent.Contract.WithdrawTokens(&_ETHSwapAgent.TransactOpts, chainId, amount, merkleProof)
+}
+
 // ETHSwapAgentOwnershipTransferredIterator is returned from FilterOwnershipTransferred and is used to iterate over the raw logs and unpacked data for OwnershipTransferred events raised by the ETHSwapAgent contract.
 type ETHSwapAgentOwnershipTransferredIterator struct {
 	Event *ETHSwapAgentOwnershipTransferred // Event containing the contract specifics and raw log
@@ -571,10 +589,11 @@ func (_ETHSwapAgent *ETHSwapAgentFilterer) ParseOwnershipTransferred(log types.L
 	if err := _ETHSwapAgent.contract.UnpackLog(event, "OwnershipTransferred", log); err != nil {
 		return nil, err
 	}
+	event.Raw = log
 	return event, nil
 }
 
-// ETHSwapAgentSwapFilledIterator is returned from FilterSwapFilled and is used to iterate over the raw logs and unpacked data for SphynxSwapFilled events raised by the ETHSwapAgent contract.
+// ETHSwapAgentSwapFilledIterator is returned from FilterSwapFilled and is used to iterate over the raw logs and unpacked data for SwapFilled events raised by the ETHSwapAgent contract.
 type ETHSwapAgentSwapFilledIterator struct {
 	Event *ETHSwapAgentSwapFilled // Event containing the contract specifics and raw log
 
@@ -641,59 +660,59 @@ func (it *ETHSwapAgentSwapFilledIterator) Close() error {
 	return nil
 }
 
-// ETHSwapAgentSwapFilled represents a SphynxSwapFilled event raised by the ETHSwapAgent contract.
+// ETHSwapAgentSwapFilled represents a SwapFilled event raised by the ETHSwapAgent contract.
 type ETHSwapAgentSwapFilled struct {
-	Erc20Addr common.Address
-	BscTxHash [32]byte
-	ToAddress common.Address
-	Amount    *big.Int
-	Raw       types.Log // Blockchain specific contextual infos
+	FromChainId *big.Int
+	ToChainId   *big.Int
+	FromAddress common.Address
+	Amount      *big.Int
+	Raw         types.Log // Blockchain specific contextual infos
 }
 
-// FilterSwapFilled is a free log retrieval operation binding the contract event 0x3bebd9a738291e69898b5dbfadb6329b4b09fc648bdef68762928e521463abd9.
+// FilterSwapFilled is a free log retrieval operation binding the contract event 0xc3ee4982aeb6e8228a604aaebff10180dbcd5b05e88b2812427836a419051f1c.
 //
-// Solidity: event SphynxSwapFilled(address indexed erc20Addr, bytes32 indexed bscTxHash, address indexed toAddress, uint256 amount)
-func (_ETHSwapAgent *ETHSwapAgentFilterer) FilterSwapFilled(opts *bind.FilterOpts, erc20Addr []common.Address, bscTxHash [][32]byte, toAddress []common.Address) (*ETHSwapAgentSwapFilledIterator, error) {
+// Solidity: event SwapFilled(uint256 fromChainId, uint256 indexed toChainId, address indexed fromAddress, uint256 indexed amount)
+func (_ETHSwapAgent *ETHSwapAgentFilterer) FilterSwapFilled(opts *bind.FilterOpts, toChainId []*big.Int, fromAddress []common.Address, amount []*big.Int) (*ETHSwapAgentSwapFilledIterator, error) {
 
-	var erc20AddrRule []interface{}
-	for _, erc20AddrItem := range erc20Addr {
-		erc20AddrRule = append(erc20AddrRule, erc20AddrItem)
+	var toChainIdRule []interface{}
+	for _, toChainIdItem := range toChainId {
+		toChainIdRule = append(toChainIdRule, toChainIdItem)
 	}
-	var bscTxHashRule []interface{}
-	for _, bscTxHashItem := range bscTxHash {
-		bscTxHashRule = append(bscTxHashRule, bscTxHashItem)
+	var fromAddressRule []interface{}
+	for _, fromAddressItem := range fromAddress {
+		fromAddressRule = append(fromAddressRule, fromAddressItem)
 	}
-	var toAddressRule []interface{}
-	for _, toAddressItem := range toAddress {
-		toAddressRule = append(toAddressRule, toAddressItem)
+	var amountRule []interface{}
+	for _, amountItem := range amount {
+		amountRule = append(amountRule, amountItem)
 	}
 
-	logs, sub, err := _ETHSwapAgent.contract.FilterLogs(opts, "SphynxSwapFilled", erc20AddrRule, bscTxHashRule, toAddressRule)
+	logs, sub, err := _ETHSwapAgent.contract.FilterLogs(opts, "SwapFilled", toChainIdRule, fromAddressRule, amountRule)
 	if err != nil {
 		return nil, err
 	}
-	return &ETHSwapAgentSwapFilledIterator{contract: _ETHSwapAgent.contract, event: "SphynxSwapFilled", logs: logs, sub: sub}, nil
+	return &ETHSwapAgentSwapFilledIterator{contract: _ETHSwapAgent.contract, event: "SwapFilled", logs: logs, sub: sub}, nil
 }
 
-// WatchSwapFilled is a free log subscription operation binding the contract event 0x3bebd9a738291e69898b5dbfadb6329b4b09fc648bdef68762928e521463abd9.
+// WatchSwapFilled is a free log subscription operation binding the contract event 0xc3ee4982aeb6e8228a604aaebff10180dbcd5b05e88b2812427836a419051f1c.
 //
-// Solidity: event SphynxSwapFilled(address indexed erc20Addr, bytes32 indexed bscTxHash, address indexed toAddress, uint256 amount)
-func (_ETHSwapAgent *ETHSwapAgentFilterer) WatchSwapFilled(opts *bind.WatchOpts, sink chan<- *ETHSwapAgentSwapFilled, erc20Addr []common.Address, bscTxHash [][32]byte, toAddress []common.Address) (event.Subscription, error) {
+// Solidity: event SwapFilled(uint256 fromChainId, uint256 indexed toChainId, address indexed fromAddress, uint256 indexed amount)
+func (_ETHSwapAgent *ETHSwapAgentFilterer) WatchSwapFilled(opts *bind.WatchOpts, sink chan<- *ETHSwapAgentSwapFilled, toChainId []*big.Int, fromAddress []common.Address, amount []*big.Int) (event.Subscription, error) {
 
-	var erc20AddrRule []interface{}
-	for _, erc20AddrItem := range erc20Addr {
-		erc20AddrRule = append(erc20AddrRule, erc20AddrItem)
+	var toChainIdRule []interface{}
+	for _, toChainIdItem := range toChainId {
+		toChainIdRule = append(toChainIdRule, toChainIdItem)
 	}
-	var bscTxHashRule []interface{}
-	for _, bscTxHashItem := range bscTxHash {
-		bscTxHashRule = append(bscTxHashRule, bscTxHashItem)
+	var fromAddressRule []interface{}
+	for _, fromAddressItem := range fromAddress {
+		fromAddressRule = append(fromAddressRule, fromAddressItem)
 	}
-	var toAddressRule []interface{}
-	for _, toAddressItem := range toAddress {
-		toAddressRule = append(toAddressRule, toAddressItem)
+	var amountRule []interface{}
+	for _, amountItem := range amount {
+		amountRule = append(amountRule, amountItem)
 	}
 
-	logs, sub, err := _ETHSwapAgent.contract.WatchLogs(opts, "SphynxSwapFilled", erc20AddrRule, bscTxHashRule, toAddressRule)
+	logs, sub, err := _ETHSwapAgent.contract.WatchLogs(opts, "SwapFilled", toChainIdRule, fromAddressRule, amountRule)
 	if err != nil {
 		return nil, err
 	}
@@ -704,7 +723,7 @@ func (_ETHSwapAgent *ETHSwapAgentFilterer) WatchSwapFilled(opts *bind.WatchOpts,
 			case log := <-logs:
 				// New log arrived, parse the event and forward to the user
 				event := new(ETHSwapAgentSwapFilled)
-				if err := _ETHSwapAgent.contract.UnpackLog(event, "SphynxSwapFilled", log); err != nil {
+				if err := _ETHSwapAgent.contract.UnpackLog(event, "SwapFilled", log); err != nil {
 					return err
 				}
 				event.Raw = log
@@ -725,169 +744,15 @@ func (_ETHSwapAgent *ETHSwapAgentFilterer) WatchSwapFilled(opts *bind.WatchOpts,
 	}), nil
 }
 
-// ParseSwapFilled is a log parse operation binding the contract event 0x3bebd9a738291e69898b5dbfadb6329b4b09fc648bdef68762928e521463abd9.
+// ParseSwapFilled is a log parse operation binding the contract event 0xc3ee4982aeb6e8228a604aaebff10180dbcd5b05e88b2812427836a419051f1c.
 //
-// Solidity: event SphynxSwapFilled(address indexed erc20Addr, bytes32 indexed bscTxHash, address indexed toAddress, uint256 amount)
+// Solidity: event SwapFilled(uint256 fromChainId, uint256 indexed toChainId, address indexed fromAddress, uint256 indexed amount)
 func (_ETHSwapAgent *ETHSwapAgentFilterer) ParseSwapFilled(log types.Log) (*ETHSwapAgentSwapFilled, error) {
 	event := new(ETHSwapAgentSwapFilled)
-	if err := _ETHSwapAgent.contract.UnpackLog(event, "SphynxSwapFilled", log); err != nil {
-		return nil, err
-	}
-	return event, nil
-}
-
-// ETHSwapAgentSwapPairRegisterIterator is returned from FilterSwapPairRegister and is used to iterate over the raw logs and unpacked data for SwapPairRegister events raised by the ETHSwapAgent contract.
-type ETHSwapAgentSwapPairRegisterIterator struct {
-	Event *ETHSwapAgentSwapPairRegister // Event containing the contract specifics and raw log
-
-	contract *bind.BoundContract // Generic contract to use for unpacking event data
-	event    string              // Event name to use for unpacking event data
-
-	logs chan types.Log        // Log channel receiving the found contract events
-	sub  ethereum.Subscription // Subscription for errors, completion and termination
-	done bool                  // Whether the subscription completed delivering logs
-	fail error                 // Occurred error to stop iteration
-}
-
-// Next advances the iterator to the subsequent event, returning whether there
-// are any more events found. In case of a retrieval or parsing error, false is
-// returned and Error() can be queried for the exact failure.
-func (it *ETHSwapAgentSwapPairRegisterIterator) Next() bool {
-	// If the iterator failed, stop iterating
-	if it.fail != nil {
-		return false
-	}
-	// If the iterator completed, deliver directly whatever's available
-	if it.done {
-		select {
-		case log := <-it.logs:
-			it.Event = new(ETHSwapAgentSwapPairRegister)
-			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
-				it.fail = err
-				return false
-			}
-			it.Event.Raw = log
-			return true
-
-		default:
-			return false
-		}
-	}
-	// Iterator still in progress, wait for either a data or an error event
-	select {
-	case log := <-it.logs:
-		it.Event = new(ETHSwapAgentSwapPairRegister)
-		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
-			it.fail = err
-			return false
-		}
-		it.Event.Raw = log
-		return true
-
-	case err := <-it.sub.Err():
-		it.done = true
-		it.fail = err
-		return it.Next()
-	}
-}
-
-// Error returns any retrieval or parsing error occurred during filtering.
-func (it *ETHSwapAgentSwapPairRegisterIterator) Error() error {
-	return it.fail
-}
-
-// Close terminates the iteration process, releasing any pending underlying
-// resources.
-func (it *ETHSwapAgentSwapPairRegisterIterator) Close() error {
-	it.sub.Unsubscribe()
-	return nil
-}
-
-// ETHSwapAgentSwapPairRegister represents a SwapPairRegister event raised by the ETHSwapAgent contract.
-type ETHSwapAgentSwapPairRegister struct {
-	Sponsor   common.Address
-	Erc20Addr common.Address
-	Name      string
-	Symbol    string
-	Decimals  uint8
-	Raw       types.Log // Blockchain specific contextual infos
-}
-
-// FilterSwapPairRegister is a free log retrieval operation binding the contract event 0xfe3bd005e346323fa452df8cafc28c55b99e3766ba8750571d139c6cf5bc08a0.
-//
-// Solidity: event SwapPairRegister(address indexed sponsor, address indexed erc20Addr, string name, string symbol, uint8 decimals)
-func (_ETHSwapAgent *ETHSwapAgentFilterer) FilterSwapPairRegister(opts *bind.FilterOpts, sponsor []common.Address, erc20Addr []common.Address) (*ETHSwapAgentSwapPairRegisterIterator, error) {
-
-	var sponsorRule []interface{}
-	for _, sponsorItem := range sponsor {
-		sponsorRule = append(sponsorRule, sponsorItem)
-	}
-	var erc20AddrRule []interface{}
-	for _, erc20AddrItem := range erc20Addr {
-		erc20AddrRule = append(erc20AddrRule, erc20AddrItem)
-	}
-
-	logs, sub, err := _ETHSwapAgent.contract.FilterLogs(opts, "SpynxSwapPairRegister", sponsorRule, erc20AddrRule)
-	if err != nil {
-		return nil, err
-	}
-	return &ETHSwapAgentSwapPairRegisterIterator{contract: _ETHSwapAgent.contract, event: "SpynxSwapPairRegister", logs: logs, sub: sub}, nil
-}
-
-// WatchSwapPairRegister is a free log subscription operation binding the contract event 0xfe3bd005e346323fa452df8cafc28c55b99e3766ba8750571d139c6cf5bc08a0.
-//
-// Solidity: event SwapPairRegister(address indexed sponsor, address indexed erc20Addr, string name, string symbol, uint8 decimals)
-func (_ETHSwapAgent *ETHSwapAgentFilterer) WatchSwapPairRegister(opts *bind.WatchOpts, sink chan<- *ETHSwapAgentSwapPairRegister, sponsor []common.Address, erc20Addr []common.Address) (event.Subscription, error) {
-
-	var sponsorRule []interface{}
-	for _, sponsorItem := range sponsor {
-		sponsorRule = append(sponsorRule, sponsorItem)
-	}
-	var erc20AddrRule []interface{}
-	for _, erc20AddrItem := range erc20Addr {
-		erc20AddrRule = append(erc20AddrRule, erc20AddrItem)
-	}
-
-	logs, sub, err := _ETHSwapAgent.contract.WatchLogs(opts, "SphynxSwapPairRegister", sponsorRule, erc20AddrRule)
-	if err != nil {
-		return nil, err
-	}
-	return event.NewSubscription(func(quit <-chan struct{}) error {
-		defer sub.Unsubscribe()
-		for {
-			select {
-			case log := <-logs:
-				// New log arrived, parse the event and forward to the user
-				event := new(ETHSwapAgentSwapPairRegister)
-				if err := _ETHSwapAgent.contract.UnpackLog(event, "SphynxSwapPairRegister", log); err != nil {
-					return err
-				}
-				event.Raw = log
-
-				select {
-				case sink <- event:
-				case err := <-sub.Err():
-					return err
-				case <-quit:
-					return nil
-				}
-			case err := <-sub.Err():
-				return err
-			case <-quit:
-				return nil
-			}
-		}
-	}), nil
-}
-
-// ParseSwapPairRegister is a log parse operation binding the contract event 0xfe3bd005e346323fa452df8cafc28c55b99e3766ba8750571d139c6cf5bc08a0.
-//
-// Solidity: event SwapPairRegister(address indexed sponsor, address indexed erc20Addr, string name, string symbol, uint8 decimals)
-func (_ETHSwapAgent *ETHSwapAgentFilterer) ParseSwapPairRegister(log types.Log) (*ETHSwapAgentSwapPairRegister, error) {
-	event := new(ETHSwapAgentSwapPairRegister)
-	if err := _ETHSwapAgent.contract.UnpackLog(event, "SphynxSwapPairRegister", log); err != nil {
+	if err := _ETHSwapAgent.contract.UnpackLog(event, "SwapFilled", log); err != nil {
 		return nil, err
 	}
+	event.Raw = log
 	return event, nil
 }
 
@@ -960,49 +825,57 @@ func (it *ETHSwapAgentSwapStartedIterator) Close() error {
 
 // ETHSwapAgentSwapStarted represents a SwapStarted event raised by the ETHSwapAgent contract.
 type ETHSwapAgentSwapStarted struct {
-	Erc20Addr common.Address
-	FromAddr  common.Address
-	Amount    *big.Int
-	FeeAmount *big.Int
-	Raw       types.Log // Blockchain specific contextual infos
+	FromChainId *big.Int
+	ToChainId   *big.Int
+	FromAddress common.Address
+	Amount      *big.Int
+	Raw         types.Log // Blockchain specific contextual infos
 }
 
-// FilterSwapStarted is a free log retrieval operation binding the contract event 0xf60309f865a6aa297da5fac6188136a02e5acfdf6e8f6d35257a9f4e9653170f.
+// FilterSwapStarted is a free log retrieval operation binding the contract event 0x7b2b39fe8cb99baf3c533665217a130daefeee1af6329eca59c5bf06a53999ac.
 //
-// Solidity: event SwapStarted(address indexed erc20Addr, address indexed fromAddr, uint256 amount, uint256 feeAmount)
-func (_ETHSwapAgent *ETHSwapAgentFilterer) FilterSwapStarted(opts *bind.FilterOpts, erc20Addr []common.Address, fromAddr []common.Address) (*ETHSwapAgentSwapStartedIterator, error) {
+// Solidity: event SwapStarted(uint256 fromChainId, uint256 indexed toChainId, address indexed fromAddress, uint256 indexed amount)
+func (_ETHSwapAgent *ETHSwapAgentFilterer) FilterSwapStarted(opts *bind.FilterOpts, toChainId []*big.Int, fromAddress []common.Address, amount []*big.Int) (*ETHSwapAgentSwapStartedIterator, error) {
 
-	var erc20AddrRule []interface{}
-	for _, erc20AddrItem := range erc20Addr {
-		erc20AddrRule = append(erc20AddrRule, erc20AddrItem)
+	var toChainIdRule []interface{}
+	for _, toChainIdItem := range toChainId {
+		toChainIdRule = append(toChainIdRule, toChainIdItem)
 	}
-	var fromAddrRule []interface{}
-	for _, fromAddrItem := range fromAddr {
-		fromAddrRule = append(fromAddrRule, fromAddrItem)
+	var fromAddressRule []interface{}
+	for _, fromAddressItem := range fromAddress {
+		fromAddressRule = append(fromAddressRule, fromAddressItem)
+	}
+	var amountRule []interface{}
+	for _, amountItem := range amount {
+		amountRule = append(amountRule, amountItem)
 	}
 
-	logs, sub, err := _ETHSwapAgent.contract.FilterLogs(opts, "SphynxSwapStarted", erc20AddrRule, fromAddrRule)
+	logs, sub, err := _ETHSwapAgent.contract.FilterLogs(opts, "SwapStarted", toChainIdRule, fromAddressRule, amountRule)
 	if err != nil {
 		return nil, err
 	}
-	return &ETHSwapAgentSwapStartedIterator{contract: _ETHSwapAgent.contract, event: "SphynxSwapStarted", logs: logs, sub: sub}, nil
+	return &ETHSwapAgentSwapStartedIterator{contract: _ETHSwapAgent.contract, event: "SwapStarted", logs: logs, sub: sub}, nil
 }
 
-// WatchSwapStarted is a free log subscription operation binding the contract event 0xf60309f865a6aa297da5fac6188136a02e5acfdf6e8f6d35257a9f4e9653170f.
+// WatchSwapStarted is a free log subscription operation binding the contract event 0x7b2b39fe8cb99baf3c533665217a130daefeee1af6329eca59c5bf06a53999ac.
 //
-// Solidity: event SwapStarted(address indexed erc20Addr, address indexed fromAddr, uint256 amount, uint256 feeAmount)
-func (_ETHSwapAgent *ETHSwapAgentFilterer) WatchSwapStarted(opts *bind.WatchOpts, sink chan<- *ETHSwapAgentSwapStarted, erc20Addr []common.Address, fromAddr []common.Address) (event.Subscription, error) {
+// Solidity: event SwapStarted(uint256 fromChainId, uint256 indexed toChainId, address indexed fromAddress, uint256 indexed amount)
+func (_ETHSwapAgent *ETHSwapAgentFilterer) WatchSwapStarted(opts *bind.WatchOpts, sink chan<- *ETHSwapAgentSwapStarted, toChainId []*big.Int, fromAddress []common.Address, amount []*big.Int) (event.Subscription, error) {
 
-	var erc20AddrRule []interface{}
-	for _, erc20AddrItem := range erc20Addr {
-		erc20AddrRule = append(erc20AddrRule, erc20AddrItem)
+	var toChainIdRule []interface{}
+	for _, toChainIdItem := range toChainId {
+		toChainIdRule = append(toChainIdRule, toChainIdItem)
+	}
+	var fromAddressRule []interface{}
+	for _, fromAddressItem := range fromAddress {
+		fromAddressRule = append(fromAddressRule, fromAddressItem)
 	}
-	var fromAddrRule []interface{}
-	for _, fromAddrItem := range fromAddr {
-		fromAddrRule = append(fromAddrRule, fromAddrItem)
+	var amountRule []interface{}
+	for _, amountItem := range amount {
+		amountRule = append(amountRule, amountItem)
 	}
 
-	logs, sub, err := _ETHSwapAgent.contract.WatchLogs(opts, "SphynxSwapStarted", erc20AddrRule, fromAddrRule)
+	logs, sub, err := _ETHSwapAgent.contract.WatchLogs(opts, "SwapStarted", toChainIdRule, fromAddressRule, amountRule)
 	if err != nil {
 		return nil, err
 	}
@@ -1013,7 +886,7 @@ func (_ETHSwapAgent *ETHSwapAgentFilterer) WatchSwapStarted(opts *bind.WatchOpts
 			case log := <-logs:
 				// New log arrived, parse the event and forward to the user
 				event := new(ETHSwapAgentSwapStarted)
-				if err := _ETHSwapAgent.contract.UnpackLog(event, "SphynxSwapStarted", log); err != nil {
+				if err := _ETHSwapAgent.contract.UnpackLog(event, "SwapStarted", log); err != nil {
 					return err
 				}
 				event.Raw = log
@@ -1034,13 +907,14 @@ func (_ETHSwapAgent *ETHSwapAgentFilterer) WatchSwapStarted(opts *bind.WatchOpts
 	}), nil
 }
 
-// ParseSwapStarted is a log parse operation binding the contract event 0xf60309f865a6aa297da5fac6188136a02e5acfdf6e8f6d35257a9f4e9653170f.
+// ParseSwapStarted is a log parse operation binding the contract event 0x7b2b39fe8cb99baf3c533665217a130daefeee1af6329eca59c5bf06a53999ac.
 //
-// Solidity: event SwapStarted(address indexed erc20Addr, address indexed fromAddr, uint256 amount, uint256 feeAmount)
+// Solidity: event SwapStarted(uint256 fromChainId, uint256 indexed toChainId, address indexed fromAddress, uint256 indexed amount)
 func (_ETHSwapAgent *ETHSwapAgentFilterer) ParseSwapStarted(log types.Log) (*ETHSwapAgentSwapStarted, error) {
 	event := new(ETHSwapAgentSwapStarted)
-	if err := _ETHSwapAgent.contract.UnpackLog(event, "SphynxSwapStarted", log); err != nil {
+	if err := _ETHSwapAgent.contract.UnpackLog(event, "SwapStarted", log); err != nil {
 		return nil, err
 	}
+	event.Raw = log
 	return event, nil
 }
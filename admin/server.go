@@ -6,15 +6,15 @@ import (
 	"io/ioutil"
 	"math/big"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/gorilla/mux"
 	"github.com/jinzhu/gorm"
 
-	cmm "occ-swap-server/common"
+	occcom "occ-swap-server/common"
 	"occ-swap-server/model"
+	"occ-swap-server/observer"
 	"occ-swap-server/swap"
 	"occ-swap-server/util"
 )
@@ -31,15 +31,20 @@ type Admin struct {
 	cfg *util.Config
 
 	hmacSigner *util.HmacSigner
+	// key is the api key of the approver
+	approvers  map[string]*util.HmacSigner
 	swapEngine *swap.SwapEngine
+	observers  []*observer.Observer
 }
 
-func NewAdmin(config *util.Config, db *gorm.DB, signer *util.HmacSigner, swapEngine *swap.SwapEngine) *Admin {
+func NewAdmin(config *util.Config, db *gorm.DB, signer *util.HmacSigner, approvers map[string]*util.HmacSigner, swapEngine *swap.SwapEngine, observers []*observer.Observer) *Admin {
 	return &Admin{
 		DB:         db,
 		cfg:        config,
 		hmacSigner: signer,
+		approvers:  approvers,
 		swapEngine: swapEngine,
+		observers:  observers,
 	}
 }
 
@@ -118,8 +123,7 @@ func (admin *Admin) UpdateSwapPairHandler(w http.ResponseWriter, r *http.Request
 	}
 
 	swapPairIns, err := admin.swapEngine.GetSwapPairInstance(common.HexToAddress(updateSwapPair.ERC20Addr))
-	// disable is only for frontend, do not affect backend
-	// if we want to disable it in backend, set the low_bound and upper_bound to be zero
+	// the swapper rejects the swaps of disabled swap pairs and amounts out of the bounds
 	if err != nil && updateSwapPair.Available {
 		// add swapPair in swapper
 		err = admin.swapEngine.AddSwapPairInstance(&swapPair)
@@ -153,6 +157,7 @@ func (admin *Admin) Endpoints(w http.ResponseWriter, r *http.Request) {
 		Endpoints: []string{
 			"/update_swap_pair",
 			"/healthz",
+			"/status",
 		},
 	}
 
@@ -184,7 +189,7 @@ func (admin *Admin) WithdrawToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err = withdrawCheck(&withdrawToken); err != nil {
+	if err = admin.withdrawCheck(&withdrawToken); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -215,9 +220,9 @@ func (admin *Admin) WithdrawToken(w http.ResponseWriter, r *http.Request) {
 
 }
 
-func withdrawCheck(withdraw *withdrawTokenRequest) error {
-	if strings.ToUpper(withdraw.Chain) != cmm.ChainBSC && strings.ToUpper(withdraw.Chain) != cmm.ChainETH {
-		return fmt.Errorf("bsc_token_contract_addr can't be empty")
+func (admin *Admin) withdrawCheck(withdraw *withdrawTokenRequest) error {
+	if admin.cfg.ChainConfig.GetChain(withdraw.Chain) == nil {
+		return fmt.Errorf("unknown chain: %s", withdraw.Chain)
 	}
 	if !common.IsHexAddress(withdraw.TokenAddr) {
 		return fmt.Errorf("token address is not a valid address")
@@ -269,6 +274,438 @@ func (admin *Admin) RetryFailedSwaps(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (admin *Admin) RefundSwaps(w http.ResponseWriter, r *http.Request) {
+	reqBody, err := admin.checkAuth(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var refundSwaps refundSwapsRequest
+	err = json.Unmarshal(reqBody, &refundSwaps)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var refundSwapsResp refundSwapsResponse
+	refundSwapsResp.SwapIDList, refundSwapsResp.RejectedSwapIDList, err = admin.swapEngine.InsertRefundSwaps(refundSwaps.SwapIDList)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		refundSwapsResp.ErrMsg = err.Error()
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	jsonBytes, err := json.MarshalIndent(refundSwapsResp, "", "    ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	_, err = w.Write(jsonBytes)
+	if err != nil {
+		util.Logger.Errorf("write response error, err=%s", err.Error())
+	}
+}
+
+// ResolveMissingRefunds settles the missing refunds of the swaps by the chain, a refund stays missing while its refund
+// tx may still land
+func (admin *Admin) ResolveMissingRefunds(w http.ResponseWriter, r *http.Request) {
+	reqBody, err := admin.checkAuth(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var resolveMissingRefunds resolveMissingRefundsRequest
+	err = json.Unmarshal(reqBody, &resolveMissingRefunds)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var resolveMissingRefundsResp resolveMissingRefundsResponse
+	resolveMissingRefundsResp.SwapIDList, resolveMissingRefundsResp.RejectedSwapIDList, err = admin.swapEngine.ResolveMissingRefunds(resolveMissingRefunds.SwapIDList)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		resolveMissingRefundsResp.ErrMsg = err.Error()
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	jsonBytes, err := json.MarshalIndent(resolveMissingRefundsResp, "", "    ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	_, err = w.Write(jsonBytes)
+	if err != nil {
+		util.Logger.Errorf("write response error, err=%s", err.Error())
+	}
+}
+
+// HeldSwaps lists the swaps held by the velocity limits and the volume held on every chain
+func (admin *Admin) HeldSwaps(w http.ResponseWriter, r *http.Request) {
+	_, err := admin.checkAuth(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var heldSwapsResp heldSwapsResponse
+	heldSwapsResp.Swaps, heldSwapsResp.RetrySwaps, heldSwapsResp.Volumes, err = admin.swapEngine.GetHeldSwaps()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		heldSwapsResp.ErrMsg = err.Error()
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	jsonBytes, err := json.MarshalIndent(heldSwapsResp, "", "    ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	_, err = w.Write(jsonBytes)
+	if err != nil {
+		util.Logger.Errorf("write response error, err=%s", err.Error())
+	}
+}
+
+func (admin *Admin) ReleaseHeldSwaps(w http.ResponseWriter, r *http.Request) {
+	reqBody, err := admin.checkAuth(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var releaseHeldSwaps releaseHeldSwapsRequest
+	err = json.Unmarshal(reqBody, &releaseHeldSwaps)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var releaseHeldSwapsResp releaseHeldSwapsResponse
+	releaseHeldSwapsResp.SwapIDList, releaseHeldSwapsResp.RejectedSwapIDList, err = admin.swapEngine.ReleaseHeldSwaps(releaseHeldSwaps.SwapIDList)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		releaseHeldSwapsResp.ErrMsg = err.Error()
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	jsonBytes, err := json.MarshalIndent(releaseHeldSwapsResp, "", "    ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	_, err = w.Write(jsonBytes)
+	if err != nil {
+		util.Logger.Errorf("write response error, err=%s", err.Error())
+	}
+}
+
+func (admin *Admin) ReleaseHeldRetrySwaps(w http.ResponseWriter, r *http.Request) {
+	reqBody, err := admin.checkAuth(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var releaseHeldRetrySwaps releaseHeldRetrySwapsRequest
+	err = json.Unmarshal(reqBody, &releaseHeldRetrySwaps)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var releaseHeldRetrySwapsResp releaseHeldRetrySwapsResponse
+	releaseHeldRetrySwapsResp.RetrySwapIDList, releaseHeldRetrySwapsResp.RejectedRetrySwapIDList, err =
+		admin.swapEngine.ReleaseHeldRetrySwaps(releaseHeldRetrySwaps.RetrySwapIDList)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		releaseHeldRetrySwapsResp.ErrMsg = err.Error()
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	jsonBytes, err := json.MarshalIndent(releaseHeldRetrySwapsResp, "", "    ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	_, err = w.Write(jsonBytes)
+	if err != nil {
+		util.Logger.Errorf("write response error, err=%s", err.Error())
+	}
+}
+
+// PendingApprovals lists the swaps waiting for approvals, it is signed by an approver
+func (admin *Admin) PendingApprovals(w http.ResponseWriter, r *http.Request) {
+	_, _, err := admin.checkApproverAuth(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var pendingApprovalsResp pendingApprovalsResponse
+	pendingApprovalsResp.Swaps, err = admin.swapEngine.GetPendingApprovals()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		pendingApprovalsResp.ErrMsg = err.Error()
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	jsonBytes, err := json.MarshalIndent(pendingApprovalsResp, "", "    ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	_, err = w.Write(jsonBytes)
+	if err != nil {
+		util.Logger.Errorf("write response error, err=%s", err.Error())
+	}
+}
+
+// ApproveSwaps records the approvals of the approver signing the request
+func (admin *Admin) ApproveSwaps(w http.ResponseWriter, r *http.Request) {
+	reqBody, approver, err := admin.checkApproverAuth(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var approveSwaps approveSwapsRequest
+	err = json.Unmarshal(reqBody, &approveSwaps)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var approveSwapsResp approveSwapsResponse
+	approveSwapsResp.SwapIDList, approveSwapsResp.RejectedSwapIDList, err = admin.swapEngine.ApproveSwaps(approver, approveSwaps.SwapIDList)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		approveSwapsResp.ErrMsg = err.Error()
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	jsonBytes, err := json.MarshalIndent(approveSwapsResp, "", "    ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	_, err = w.Write(jsonBytes)
+	if err != nil {
+		util.Logger.Errorf("write response error, err=%s", err.Error())
+	}
+}
+
+// RejectSwaps records the rejections of the approver signing the request
+func (admin *Admin) RejectSwaps(w http.ResponseWriter, r *http.Request) {
+	reqBody, approver, err := admin.checkApproverAuth(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var rejectSwaps rejectSwapsRequest
+	err = json.Unmarshal(reqBody, &rejectSwaps)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if rejectSwaps.Reason == "" {
+		http.Error(w, "reason can't be empty", http.StatusBadRequest)
+		return
+	}
+
+	var rejectSwapsResp rejectSwapsResponse
+	rejectSwapsResp.SwapIDList, rejectSwapsResp.RejectedSwapIDList, err = admin.swapEngine.RejectSwaps(approver, rejectSwaps.SwapIDList, rejectSwaps.Reason)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		rejectSwapsResp.ErrMsg = err.Error()
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	jsonBytes, err := json.MarshalIndent(rejectSwapsResp, "", "    ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	_, err = w.Write(jsonBytes)
+	if err != nil {
+		util.Logger.Errorf("write response error, err=%s", err.Error())
+	}
+}
+
+// Pauses lists the pauses in force, whether set by the admin or tripped by an anomaly
+func (admin *Admin) Pauses(w http.ResponseWriter, r *http.Request) {
+	_, err := admin.checkAuth(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var pausesResp pausesResponse
+	pausesResp.Pauses, err = admin.swapEngine.GetPauses()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		pausesResp.ErrMsg = err.Error()
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	jsonBytes, err := json.MarshalIndent(pausesResp, "", "    ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	_, err = w.Write(jsonBytes)
+	if err != nil {
+		util.Logger.Errorf("write response error, err=%s", err.Error())
+	}
+}
+
+// Pause stops sending the fills globally, to a chain or of a direction
+func (admin *Admin) Pause(w http.ResponseWriter, r *http.Request) {
+	reqBody, err := admin.checkAuth(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var pause pauseRequest
+	err = json.Unmarshal(reqBody, &pause)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var pauseResp pauseResponse
+	err = admin.swapEngine.Pause(occcom.PauseScope(pause.Scope), pause.Target, pause.Reason)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		pauseResp.ErrMsg = err.Error()
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	jsonBytes, err := json.MarshalIndent(pauseResp, "", "    ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	_, err = w.Write(jsonBytes)
+	if err != nil {
+		util.Logger.Errorf("write response error, err=%s", err.Error())
+	}
+}
+
+// Resume lifts the pauses of a scope
+func (admin *Admin) Resume(w http.ResponseWriter, r *http.Request) {
+	reqBody, err := admin.checkAuth(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var resume resumeRequest
+	err = json.Unmarshal(reqBody, &resume)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var resumeResp resumeResponse
+	resumeResp.Lifted, err = admin.swapEngine.Resume(occcom.PauseScope(resume.Scope), resume.Target)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		resumeResp.ErrMsg = err.Error()
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	jsonBytes, err := json.MarshalIndent(resumeResp, "", "    ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	_, err = w.Write(jsonBytes)
+	if err != nil {
+		util.Logger.Errorf("write response error, err=%s", err.Error())
+	}
+}
+
+// Status reports the last fetched block of every chain and the health of its executor and providers
+func (admin *Admin) Status(w http.ResponseWriter, r *http.Request) {
+	_, err := admin.checkAuth(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	statuses := make([]*observer.Status, 0, len(admin.observers))
+	for _, ob := range admin.observers {
+		status, err := ob.Status()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		statuses = append(statuses, status)
+	}
+
+	jsonBytes, err := json.MarshalIndent(statuses, "", "    ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(jsonBytes)
+	if err != nil {
+		util.Logger.Errorf("write response error, err=%s", err.Error())
+	}
+}
+
 func (admin *Admin) Healthz(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
@@ -292,14 +729,48 @@ func (admin *Admin) checkAuth(r *http.Request) ([]byte, error) {
 	return payload, nil
 }
 
+// checkApproverAuth verifies the request is signed by one of the approvers, it returns the payload and the api key of
+// the approver
+func (admin *Admin) checkApproverAuth(r *http.Request) ([]byte, string, error) {
+	apiKey := r.Header.Get("ApiKey")
+	hash := r.Header.Get("Authorization")
+
+	payload, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	signer, ok := admin.approvers[apiKey]
+	if !ok {
+		return nil, "", fmt.Errorf("unknown approver")
+	}
+
+	if !signer.Verify(payload, hash) {
+		return nil, "", fmt.Errorf("invalid auth")
+	}
+	return payload, apiKey, nil
+}
+
 func (admin *Admin) Serve() {
 	router := mux.NewRouter()
 
 	router.HandleFunc("/", admin.Endpoints).Methods("GET")
 	router.HandleFunc("/healthz", admin.Healthz).Methods("GET")
+	router.HandleFunc("/status", admin.Status).Methods("GET")
 	router.HandleFunc("/update_swap_pair", admin.UpdateSwapPairHandler).Methods("PUT")
 	router.HandleFunc("/withdraw_token", admin.WithdrawToken).Methods("POST")
 	router.HandleFunc("/retry_failed_swaps", admin.RetryFailedSwaps).Methods("POST")
+	router.HandleFunc("/refund_swaps", admin.RefundSwaps).Methods("POST")
+	router.HandleFunc("/resolve_missing_refunds", admin.ResolveMissingRefunds).Methods("POST")
+	router.HandleFunc("/held_swaps", admin.HeldSwaps).Methods("GET")
+	router.HandleFunc("/release_held_swaps", admin.ReleaseHeldSwaps).Methods("POST")
+	router.HandleFunc("/release_held_retry_swaps", admin.ReleaseHeldRetrySwaps).Methods("POST")
+	router.HandleFunc("/pending_approvals", admin.PendingApprovals).Methods("GET")
+	router.HandleFunc("/approve_swaps", admin.ApproveSwaps).Methods("POST")
+	router.HandleFunc("/reject_swaps", admin.RejectSwaps).Methods("POST")
+	router.HandleFunc("/pauses", admin.Pauses).Methods("GET")
+	router.HandleFunc("/pause", admin.Pause).Methods("POST")
+	router.HandleFunc("/resume", admin.Resume).Methods("POST")
 
 	listenAddr := DefaultListenAddr
 	if admin.cfg.AdminConfig.ListenAddr != "" {
@@ -1,5 +1,10 @@
 package admin
 
+import (
+	"occ-swap-server/model"
+	"occ-swap-server/swap"
+)
+
 type updateSwapPairRequest struct {
 	ERC20Addr  string `json:"erc20_addr"`
 	Available  bool   `json:"available"`
@@ -29,3 +34,103 @@ type retryFailedSwapsResponse struct {
 	RejectedSwapIDList []uint `json:"rejected_swap_id_list"`
 	ErrMsg             string `json:"err_msg"`
 }
+
+type refundSwapsRequest struct {
+	SwapIDList []uint `json:"swap_id_list"`
+}
+
+type refundSwapsResponse struct {
+	SwapIDList         []uint `json:"swap_id_list"`
+	RejectedSwapIDList []uint `json:"rejected_swap_id_list"`
+	ErrMsg             string `json:"err_msg"`
+}
+
+type resolveMissingRefundsRequest struct {
+	SwapIDList []uint `json:"swap_id_list"`
+}
+
+type resolveMissingRefundsResponse struct {
+	SwapIDList         []uint `json:"swap_id_list"`
+	RejectedSwapIDList []uint `json:"rejected_swap_id_list"`
+	ErrMsg             string `json:"err_msg"`
+}
+
+type heldSwapsResponse struct {
+	Swaps      []model.Swap       `json:"swaps"`
+	RetrySwaps []model.RetrySwap  `json:"retry_swaps"`
+	Volumes    []*swap.HeldVolume `json:"volumes"`
+	ErrMsg     string             `json:"err_msg"`
+}
+
+type releaseHeldSwapsRequest struct {
+	SwapIDList []uint `json:"swap_id_list"`
+}
+
+type releaseHeldSwapsResponse struct {
+	SwapIDList         []uint `json:"swap_id_list"`
+	RejectedSwapIDList []uint `json:"rejected_swap_id_list"`
+	ErrMsg             string `json:"err_msg"`
+}
+
+type releaseHeldRetrySwapsRequest struct {
+	RetrySwapIDList []uint `json:"retry_swap_id_list"`
+}
+
+type releaseHeldRetrySwapsResponse struct {
+	RetrySwapIDList         []uint `json:"retry_swap_id_list"`
+	RejectedRetrySwapIDList []uint `json:"rejected_retry_swap_id_list"`
+	ErrMsg                  string `json:"err_msg"`
+}
+
+type pendingApprovalsResponse struct {
+	Swaps  []*swap.PendingApproval `json:"swaps"`
+	ErrMsg string                  `json:"err_msg"`
+}
+
+type approveSwapsRequest struct {
+	SwapIDList []uint `json:"swap_id_list"`
+}
+
+type approveSwapsResponse struct {
+	SwapIDList         []uint `json:"swap_id_list"`
+	RejectedSwapIDList []uint `json:"rejected_swap_id_list"`
+	ErrMsg             string `json:"err_msg"`
+}
+
+type rejectSwapsRequest struct {
+	SwapIDList []uint `json:"swap_id_list"`
+	Reason     string `json:"reason"`
+}
+
+type rejectSwapsResponse struct {
+	SwapIDList         []uint `json:"swap_id_list"`
+	RejectedSwapIDList []uint `json:"rejected_swap_id_list"`
+	ErrMsg             string `json:"err_msg"`
+}
+
+type pausesResponse struct {
+	Pauses []model.Pause `json:"pauses"`
+	ErrMsg string        `json:"err_msg"`
+}
+
+type pauseRequest struct {
+	// global, chain or direction
+	Scope string `json:"scope"`
+	// the chain name of a chain pause, the direction of a direction pause
+	Target string `json:"target"`
+	Reason string `json:"reason"`
+}
+
+type pauseResponse struct {
+	ErrMsg string `json:"err_msg"`
+}
+
+type resumeRequest struct {
+	Scope  string `json:"scope"`
+	Target string `json:"target"`
+}
+
+type resumeResponse struct {
+	Lifted int64  `json:"lifted"`
+	ErrMsg string `json:"err_msg"`
+}
@@ -6,11 +6,12 @@ const (
 	ObserverMaxBlockNumber = 10000
 	ObserverPruneInterval  = 10 * time.Second
 	ObserverAlertInterval  = 5 * time.Second
-
-	ChainBSC   = "BSC"   // binance smart chain
-	ChainETH   = "ETH"   // ethereum
-	// ChainMATIC = "MATIC" //polygon
-	ChainMATIC = "CRO" //cronos
+	// defaults of the range scanning of the observer
+	ObserverDefaultMaxFetchRange   = 1000
+	ObserverDefaultCatchUpDistance = 100
+	// the observer waits for a new head of the subscription at most this long before it polls
+	ObserverSubscriptionMaxWait = 60 * time.Second
+	WsReconnectInterval         = 5 * time.Second
 
 	VaultName = "BSC_ETH_SWAP"
 
@@ -19,13 +20,57 @@ const (
 
 	LocalPrivateKey = "local_private_key"
 	AWSPrivateKey   = "aws_private_key"
+
+	GasStrategyLegacy     = "legacy"
+	GasStrategyEIP1559    = "eip1559"
+	GasStrategyFixed      = "fixed"
+	GasStrategyFeeHistory = "fee_history"
+
+	ExecutorTypeEvm    = "evm"
+	ExecutorTypeReplay = "replay"
+
+	ScreenerTypeBlocklist = "blocklist"
+	ScreenerTypeWebhook   = "webhook"
+	// seconds the webhook screener waits for a response by default
+	ScreenerDefaultWebhookTimeout = 10
+
+	ProviderStrategyPriority   = "priority"
+	ProviderStrategyRoundRobin = "round_robin"
+
+	ProviderHealthCheckInterval = 10 * time.Second
+	ProviderDefaultMaxHeadLag   = 10
+
+	// defaults of the swap workers of a destination chain
+	SwapDefaultWorkers         = 1
+	SwapDefaultMaxPendingFills = 100
+
+	// the fills to a chain are paused after this many fills to it failed in a row
+	SwapDefaultMaxFillFailures = 5
+	// seconds between the balance checks of the signers
+	BalanceMonitorDefaultInterval = 60
+
+	PauseScopeGlobal    PauseScope = "global"
+	PauseScopeChain     PauseScope = "chain"
+	PauseScopeDirection PauseScope = "direction"
+
+	// the admin, or the anomaly which tripped the circuit breaker
+	PausedByAdmin        = "admin"
+	PausedByHMAC         = "hmac_verification"
+	PausedByFillFailures = "fill_failures"
+	PausedByReorg        = "reorg"
+	PausedByBalance      = "low_balance"
 )
 
 type SwapStatus string
 type SwapPairStatus string
 type RetrySwapStatus string
 type SwapDirection string
+type SwapRefundStatus string
+type SwapApprovalDecision string
+type PauseScope string
 
+// BlockAndEventLogs holds the events of the blocks [FromHeight, Height], only the header of the last block is kept,
+// the parent of the first block is checked against the last fetched block
 type BlockAndEventLogs struct {
 	Height          int64
 	Chain           string
@@ -33,4 +78,7 @@ type BlockAndEventLogs struct {
 	ParentBlockHash string
 	BlockTime       int64
 	Events          []interface{}
+
+	FromHeight          int64
+	FromParentBlockHash string
 }
@@ -0,0 +1,170 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcmm "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	sabi "occ-swap-server/abi"
+	"occ-swap-server/util"
+)
+
+// ErrMalformedLog is returned when a log does not match the layout of its event
+var ErrMalformedLog = errors.New("malformed event log")
+
+// Agent is a deployment of the swap agent on a chain, it emits the events from FromHeight on
+type Agent struct {
+	FromHeight int64
+	Address    ethcmm.Address
+
+	SwapStarted      abi.Event
+	SwapFilled       abi.Event
+	SwapPairRegister abi.Event
+}
+
+// LoadAgents returns the agent versions of the chain by increasing FromHeight
+func LoadAgents(chainCfg *util.ChainInfo) ([]*Agent, error) {
+	versions := chainCfg.AgentVersions
+	if len(versions) == 0 {
+		versions = []util.AgentVersion{{}}
+	}
+
+	agents := make([]*Agent, 0, len(versions))
+	for idx, version := range versions {
+		swapAgentAbi, err := loadAbi(version.SwapAgentAbiFile, sabi.SwapAgentABI)
+		if err != nil {
+			return nil, fmt.Errorf("load swap agent abi of agent version %d of %s error: %s", idx, chainCfg.Name, err.Error())
+		}
+		swapPairAgentAbi, err := loadAbi(version.SwapPairAgentAbiFile, sabi.SwapPairAgentABI)
+		if err != nil {
+			return nil, fmt.Errorf("load swap pair agent abi of agent version %d of %s error: %s", idx, chainCfg.Name, err.Error())
+		}
+
+		agent := &Agent{
+			FromHeight: version.FromHeight,
+			Address:    ethcmm.HexToAddress(chainCfg.SwapAgentAddr),
+		}
+		if version.SwapAgentAddr != "" {
+			agent.Address = ethcmm.HexToAddress(version.SwapAgentAddr)
+		}
+		if agent.SwapStarted, err = lookupEvent(swapAgentAbi, version.SwapStartedEvent, SwapStartedEventName, SwapStartedEvent{}); err != nil {
+			return nil, fmt.Errorf("agent version %d of %s: %s", idx, chainCfg.Name, err.Error())
+		}
+		if agent.SwapFilled, err = lookupEvent(swapAgentAbi, version.SwapFilledEvent, SwapFilledEventName, SwapFilledEvent{}); err != nil {
+			return nil, fmt.Errorf("agent version %d of %s: %s", idx, chainCfg.Name, err.Error())
+		}
+		if agent.SwapPairRegister, err = lookupEvent(swapPairAgentAbi, version.SwapPairRegisterEvent, SwapPairRegisterEventName, SwapPairRegisterEvent{}); err != nil {
+			return nil, fmt.Errorf("agent version %d of %s: %s", idx, chainCfg.Name, err.Error())
+		}
+		agents = append(agents, agent)
+	}
+	return agents, nil
+}
+
+func loadAbi(file, builtin string) (*abi.ABI, error) {
+	abiJson := builtin
+	if file != "" {
+		bz, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		abiJson = string(bz)
+	}
+	parsed, err := abi.JSON(strings.NewReader(abiJson))
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+// lookupEvent returns the named event of the abi, it must carry every field the typed event needs
+func lookupEvent(agentAbi *abi.ABI, name, defaultName string, typed interface{}) (abi.Event, error) {
+	if name == "" {
+		name = defaultName
+	}
+	event, ok := agentAbi.Events[name]
+	if !ok {
+		return abi.Event{}, fmt.Errorf("event %s is not in the abi", name)
+	}
+	if event.Anonymous {
+		return abi.Event{}, fmt.Errorf("event %s is anonymous", name)
+	}
+
+	inputs := make(map[string]bool, len(event.Inputs))
+	for _, input := range event.Inputs {
+		inputs[input.Name] = true
+	}
+	typ := reflect.TypeOf(typed)
+	for idx := 0; idx < typ.NumField(); idx++ {
+		argName, optional := eventArgName(typ.Field(idx))
+		if argName != "" && !optional && !inputs[argName] {
+			return abi.Event{}, fmt.Errorf("event %s has no argument %s", name, argName)
+		}
+	}
+	return event, nil
+}
+
+// eventArgName returns the argument name of the field given by its event tag, e.g. `event:"amount"` or
+// `event:"feeAmount,optional"`. Fields without the tag are not decoded
+func eventArgName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("event")
+	if tag == "" {
+		return "", false
+	}
+	parts := strings.Split(tag, ",")
+	return parts[0], len(parts) > 1 && parts[1] == "optional"
+}
+
+// DecodeEvent decodes the log into the typed event by the abi of the event. The topic count is checked against the
+// indexed arguments, the arguments are assigned to the fields by their event tags
+func DecodeEvent(event abi.Event, log *types.Log, out interface{}) error {
+	if len(log.Topics) == 0 || log.Topics[0] != event.ID {
+		return fmt.Errorf("%w: log is not a %s event", ErrMalformedLog, event.Name)
+	}
+	indexed := make(abi.Arguments, 0, len(event.Inputs))
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			indexed = append(indexed, input)
+		}
+	}
+	if len(log.Topics) != len(indexed)+1 {
+		return fmt.Errorf("%w: %s event has %d topics, %d are expected", ErrMalformedLog, event.Name, len(log.Topics), len(indexed)+1)
+	}
+
+	values := make(map[string]interface{}, len(event.Inputs))
+	if err := event.Inputs.UnpackIntoMap(values, log.Data); err != nil {
+		return fmt.Errorf("%w: unpack data of %s event error: %s", ErrMalformedLog, event.Name, err.Error())
+	}
+	if err := abi.ParseTopicsIntoMap(values, indexed, log.Topics[1:]); err != nil {
+		return fmt.Errorf("%w: parse topics of %s event error: %s", ErrMalformedLog, event.Name, err.Error())
+	}
+
+	outValue := reflect.ValueOf(out).Elem()
+	for idx := 0; idx < outValue.NumField(); idx++ {
+		field := outValue.Type().Field(idx)
+		argName, optional := eventArgName(field)
+		if argName == "" {
+			continue
+		}
+		value, ok := values[argName]
+		if !ok {
+			if optional {
+				continue
+			}
+			return fmt.Errorf("%w: %s event has no argument %s", ErrMalformedLog, event.Name, argName)
+		}
+		argValue := reflect.ValueOf(value)
+		if !argValue.Type().AssignableTo(field.Type) {
+			return fmt.Errorf("%w: argument %s of %s event is %s, %s is expected", ErrMalformedLog, argName, event.Name,
+				argValue.Type().String(), field.Type.String())
+		}
+		outValue.Field(idx).Set(argValue)
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package executor
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcmm "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	sabi "occ-swap-server/abi"
+)
+
+// swapStartedWithTokenABI is a later agent emitting the fee and the deposited token
+const swapStartedWithTokenABI = `[{"anonymous":false,"name":"SwapStarted","type":"event","inputs":[
+	{"indexed":false,"name":"fromChainId","type":"uint256"},
+	{"indexed":true,"name":"toChainId","type":"uint256"},
+	{"indexed":true,"name":"fromAddress","type":"address"},
+	{"indexed":false,"name":"amount","type":"uint256"},
+	{"indexed":false,"name":"feeAmount","type":"uint256"},
+	{"indexed":false,"name":"tokenAddr","type":"address"}]}]`
+
+// swapStartedMistypedABI declares the amount as an address
+const swapStartedMistypedABI = `[{"anonymous":false,"name":"SwapStarted","type":"event","inputs":[
+	{"indexed":false,"name":"fromChainId","type":"uint256"},
+	{"indexed":true,"name":"toChainId","type":"uint256"},
+	{"indexed":true,"name":"fromAddress","type":"address"},
+	{"indexed":false,"name":"amount","type":"address"}]}]`
+
+func swapStartedEvent(t *testing.T, abiJson string) abi.Event {
+	parsed, err := abi.JSON(strings.NewReader(abiJson))
+	if err != nil {
+		t.Fatalf("parse abi error: %s", err.Error())
+	}
+	return parsed.Events[SwapStartedEventName]
+}
+
+// packLog builds the log of the event emitting the arguments by their names
+func packLog(t *testing.T, event abi.Event, args map[string]interface{}) *types.Log {
+	log := &types.Log{Topics: []ethcmm.Hash{event.ID}}
+	nonIndexed := make([]interface{}, 0, len(event.Inputs))
+	for _, input := range event.Inputs {
+		if !input.Indexed {
+			nonIndexed = append(nonIndexed, args[input.Name])
+			continue
+		}
+		topics, err := abi.MakeTopics([]interface{}{args[input.Name]})
+		if err != nil {
+			t.Fatalf("make topic of %s error: %s", input.Name, err.Error())
+		}
+		log.Topics = append(log.Topics, topics[0][0])
+	}
+	data, err := event.Inputs.NonIndexed().Pack(nonIndexed...)
+	if err != nil {
+		t.Fatalf("pack data of %s error: %s", event.Name, err.Error())
+	}
+	log.Data = data
+	return log
+}
+
+func TestDecodeEvent(t *testing.T) {
+	sponsor := ethcmm.HexToAddress("0x0a")
+	token := ethcmm.HexToAddress("0x0b")
+	args := map[string]interface{}{
+		"fromChainId": big.NewInt(56),
+		"toChainId":   big.NewInt(1),
+		"fromAddress": sponsor,
+		"amount":      big.NewInt(1000),
+		"feeAmount":   big.NewInt(10),
+		"tokenAddr":   token,
+	}
+	builtin := swapStartedEvent(t, sabi.SwapAgentABI)
+	withToken := swapStartedEvent(t, swapStartedWithTokenABI)
+	mistyped := swapStartedEvent(t, swapStartedMistypedABI)
+	mistypedArgs := map[string]interface{}{
+		"fromChainId": big.NewInt(56),
+		"toChainId":   big.NewInt(1),
+		"fromAddress": sponsor,
+		"amount":      token,
+	}
+
+	cases := []struct {
+		name  string
+		event abi.Event
+		log   func() *types.Log
+		// the decoded fee and token, they are empty if the event does not carry them
+		wantFee   string
+		wantToken ethcmm.Address
+		wantErr   bool
+	}{
+		{
+			name:  "builtin event without the optional arguments",
+			event: builtin,
+			log:   func() *types.Log { return packLog(t, builtin, args) },
+		},
+		{
+			name:      "event with the fee and token",
+			event:     withToken,
+			log:       func() *types.Log { return packLog(t, withToken, args) },
+			wantFee:   "10",
+			wantToken: token,
+		},
+		{
+			name:  "log of another event",
+			event: builtin,
+			log: func() *types.Log {
+				log := packLog(t, builtin, args)
+				log.Topics[0] = ethcmm.HexToHash("0x01")
+				return log
+			},
+			wantErr: true,
+		},
+		{
+			name:  "log without topics",
+			event: builtin,
+			log: func() *types.Log {
+				log := packLog(t, builtin, args)
+				log.Topics = nil
+				return log
+			},
+			wantErr: true,
+		},
+		{
+			name:  "missing indexed topic",
+			event: builtin,
+			log: func() *types.Log {
+				log := packLog(t, builtin, args)
+				log.Topics = log.Topics[:len(log.Topics)-1]
+				return log
+			},
+			wantErr: true,
+		},
+		{
+			name:  "extra topic",
+			event: builtin,
+			log: func() *types.Log {
+				log := packLog(t, builtin, args)
+				log.Topics = append(log.Topics, ethcmm.HexToHash("0x01"))
+				return log
+			},
+			wantErr: true,
+		},
+		{
+			name:  "truncated data",
+			event: withToken,
+			log: func() *types.Log {
+				log := packLog(t, withToken, args)
+				log.Data = log.Data[:len(log.Data)-32]
+				return log
+			},
+			wantErr: true,
+		},
+		{
+			name:    "argument of another type",
+			event:   mistyped,
+			log:     func() *types.Log { return packLog(t, mistyped, mistypedArgs) },
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		event := &SwapStartedEvent{}
+		err := DecodeEvent(c.event, c.log(), event)
+		if c.wantErr {
+			if !errors.Is(err, ErrMalformedLog) {
+				t.Fatalf("%s: error = %v, want %v", c.name, err, ErrMalformedLog)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: decode error: %s", c.name, err.Error())
+		}
+		if event.FromChainId.Int64() != 56 || event.ToChainId.Int64() != 1 || event.Amount.Int64() != 1000 {
+			t.Fatalf("%s: decoded chain ids %s, %s and amount %s, want 56, 1 and 1000", c.name,
+				event.FromChainId.String(), event.ToChainId.String(), event.Amount.String())
+		}
+		if event.FromAddress != sponsor {
+			t.Fatalf("%s: from address = %s, want %s", c.name, event.FromAddress.String(), sponsor.String())
+		}
+		fee := ""
+		if event.FeeAmount != nil {
+			fee = event.FeeAmount.String()
+		}
+		if fee != c.wantFee {
+			t.Fatalf("%s: fee = %q, want %q", c.name, fee, c.wantFee)
+		}
+		if event.TokenAddr != c.wantToken {
+			t.Fatalf("%s: token = %s, want %s", c.name, event.TokenAddr.String(), c.wantToken.String())
+		}
+	}
+}
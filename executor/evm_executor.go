@@ -0,0 +1,328 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcmm "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	contractabi "occ-swap-server/abi"
+	"occ-swap-server/common"
+	"occ-swap-server/provider"
+	"occ-swap-server/util"
+)
+
+// EvmExecutor fetches the events of the swap agents from an EVM chain, the events are decoded by the abi of the
+// agent version deployed at the height of the log
+type EvmExecutor struct {
+	Chain  string
+	Config *util.Config
+
+	Agents []*Agent
+	// the swap agent contracts by address, the deposited token is read from them
+	agentInsts map[ethcmm.Address]*contractabi.ETHSwapAgent
+	Client     *provider.Pool
+
+	// the latest head of the websocket subscription, nil while the subscription is down
+	WsProvider string
+	mutex      sync.RWMutex
+	latestHead *types.Header
+	heads      chan struct{}
+}
+
+func NewEvmExecutor(ethClient *provider.Pool, chainCfg *util.ChainInfo, config *util.Config) (*EvmExecutor, error) {
+	agents, err := LoadAgents(chainCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	agentInsts := make(map[ethcmm.Address]*contractabi.ETHSwapAgent, len(agents))
+	for _, agent := range agents {
+		agentInst, err := contractabi.NewETHSwapAgent(agent.Address, ethClient)
+		if err != nil {
+			return nil, err
+		}
+		agentInsts[agent.Address] = agentInst
+	}
+
+	return &EvmExecutor{
+		Chain:      chainCfg.Name,
+		Config:     config,
+		Agents:     agents,
+		agentInsts: agentInsts,
+		Client:     ethClient,
+		WsProvider: chainCfg.WsProvider,
+		heads:      make(chan struct{}, 1),
+	}, nil
+}
+
+// agentAt returns the agent version emitting the events at the height
+func (e *EvmExecutor) agentAt(height int64) *Agent {
+	agent := e.Agents[0]
+	for _, version := range e.Agents {
+		if version.FromHeight <= height {
+			agent = version
+		}
+	}
+	return agent
+}
+
+// filterQuery returns the addresses and topics of the agent versions deployed in [from, to]
+func (e *EvmExecutor) filterQuery(from, to int64) ([]ethcmm.Address, [][]ethcmm.Hash) {
+	addresses := make([]ethcmm.Address, 0, len(e.Agents))
+	topics := make([]ethcmm.Hash, 0, 3*len(e.Agents))
+	for idx, agent := range e.Agents {
+		if agent.FromHeight > to || (idx+1 < len(e.Agents) && e.Agents[idx+1].FromHeight <= from) {
+			continue
+		}
+		addresses = append(addresses, agent.Address)
+		topics = append(topics, agent.SwapStarted.ID, agent.SwapFilled.ID, agent.SwapPairRegister.ID)
+	}
+	return addresses, [][]ethcmm.Hash{topics}
+}
+
+func (e *EvmExecutor) GetChainName() string {
+	return e.Chain
+}
+
+func (e *EvmExecutor) GetHealth() *Health {
+	health := &Health{
+		Chain:        e.Chain,
+		ExecutorType: common.ExecutorTypeEvm,
+		Subscribed:   e.Subscribed(),
+		Providers:    e.Client.Status(),
+	}
+	head, err := e.GetHeight()
+	if err != nil {
+		health.Err = err.Error()
+		return health
+	}
+	health.Head = head
+	for _, status := range health.Providers {
+		if status.Healthy {
+			health.Healthy = true
+		}
+	}
+	if !health.Healthy {
+		health.Err = "no healthy provider"
+	}
+	return health
+}
+
+func (e *EvmExecutor) GetHeight() (int64, error) {
+	if head := e.getHead(); head != nil {
+		return head.Number.Int64(), nil
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	height, err := e.Client.BlockNumber(ctxWithTimeout)
+	if err != nil {
+		return 0, err
+	}
+	return int64(height), nil
+}
+
+func (e *EvmExecutor) GetBlockHash(height int64) (string, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	header, err := e.Client.QuorumHeaderByNumber(ctxWithTimeout, big.NewInt(height))
+	if err != nil {
+		return "", err
+	}
+	return header.Hash().String(), nil
+}
+
+// GetBlockAndTxEvents returns the events of the block, the latest head of the subscription is used without a query
+// and the logs are only queried if the bloom of the header may contain the swap agent
+func (e *EvmExecutor) GetBlockAndTxEvents(height int64) (*common.BlockAndEventLogs, error) {
+	header := e.getHead()
+	if header != nil && header.Number.Int64() < height {
+		return nil, fmt.Errorf("%w: height %d, head %d", ErrBlockNotReached, height, header.Number.Int64())
+	}
+	// the head of the subscription is a single provider's view, it is not taken for quorum reads
+	if header == nil || header.Number.Int64() != height || e.Client.Quorum() > 1 {
+		ctxWithTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		var err error
+		header, err = e.Client.QuorumHeaderByNumber(ctxWithTimeout, big.NewInt(height))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	packageLogs := make([]interface{}, 0)
+	if types.BloomLookup(header.Bloom, e.agentAt(height).Address) {
+		var err error
+		packageLogs, err = e.GetLogs(height, height, 5*time.Second)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &common.BlockAndEventLogs{
+		Height:          height,
+		Chain:           e.Chain,
+		BlockHash:       header.Hash().String(),
+		ParentBlockHash: header.ParentHash.String(),
+		BlockTime:       int64(header.Time),
+		Events:          packageLogs,
+
+		FromHeight:          height,
+		FromParentBlockHash: header.ParentHash.String(),
+	}, nil
+}
+
+func (e *EvmExecutor) GetRangeBlockAndTxEvents(from, to int64) (*common.BlockAndEventLogs, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	fromHeader, err := e.Client.QuorumHeaderByNumber(ctxWithTimeout, big.NewInt(from))
+	if err != nil {
+		return nil, err
+	}
+	toHeader := fromHeader
+	if to != from {
+		toHeader, err = e.Client.QuorumHeaderByNumber(ctxWithTimeout, big.NewInt(to))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	packageLogs, err := e.GetLogs(from, to, 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	return &common.BlockAndEventLogs{
+		Height:          to,
+		Chain:           e.Chain,
+		BlockHash:       toHeader.Hash().String(),
+		ParentBlockHash: toHeader.ParentHash.String(),
+		BlockTime:       int64(toHeader.Time),
+		Events:          packageLogs,
+
+		FromHeight:          from,
+		FromParentBlockHash: fromHeader.ParentHash.String(),
+	}, nil
+}
+
+func (e *EvmExecutor) GetLogs(from, to int64, timeout time.Duration) ([]interface{}, error) {
+	addresses, topics := e.filterQuery(from, to)
+
+	ctxWithTimeout, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	logs, err := e.Client.QuorumFilterLogs(ctxWithTimeout, ethereum.FilterQuery{
+		FromBlock: big.NewInt(from),
+		ToBlock:   big.NewInt(to),
+		Topics:    topics,
+		Addresses: addresses,
+	})
+
+	if err != nil {
+		if isRangeTooLargeError(err) {
+			return nil, fmt.Errorf("%w: [%d, %d], %s", ErrRangeTooLarge, from, to, err.Error())
+		}
+		return nil, err
+	}
+
+	eventModels := make([]interface{}, 0, len(logs))
+	for _, log := range logs {
+		// a log of an agent is only taken while the agent is the deployed version
+		agent := e.agentAt(int64(log.BlockNumber))
+		if log.Address != agent.Address || len(log.Topics) == 0 {
+			continue
+		}
+		var eventModel interface{}
+		switch log.Topics[0] {
+		case agent.SwapStarted.ID:
+			eventModel, err = e.parseSwapStartLog(agent, &log)
+			if err != nil {
+				return nil, err
+			}
+		case agent.SwapFilled.ID:
+			eventModel = e.parseSwapFilledLog(agent, &log)
+		case agent.SwapPairRegister.ID:
+			eventModel = e.parseSwapPairRegisterLog(agent, &log)
+		}
+		if eventModel != nil {
+			eventModels = append(eventModels, eventModel)
+		}
+	}
+	return eventModels, nil
+}
+
+// parseSwapStartLog returns an error only if the deposited token can not be resolved, the block should be fetched again
+func (e *EvmExecutor) parseSwapStartLog(agent *Agent, log *types.Log) (interface{}, error) {
+	event := &SwapStartedEvent{}
+	if err := DecodeEvent(agent.SwapStarted, log, event); err != nil {
+		// the deposit is not lost, it must be handled by hand
+		e.alertMalformedLog(log, err)
+		return nil, nil
+	}
+
+	// the agent pulls the token configured for the source chain id. setToken can change it, so it is read at the
+	// height of the event. A node which pruned the state of that height fails the read and the block is not saved
+	if event.TokenAddr == (ethcmm.Address{}) {
+		ctxWithTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		var err error
+		event.TokenAddr, err = e.agentInsts[agent.Address].TokenAddresses(&bind.CallOpts{
+			BlockNumber: new(big.Int).SetUint64(log.BlockNumber),
+			Context:     ctxWithTimeout,
+		}, event.FromChainId)
+		if err != nil {
+			return nil, fmt.Errorf("read token of chain id %s at height %d: %w", event.FromChainId.String(), log.BlockNumber, err)
+		}
+	}
+
+	eventModel := event.ToSwapStartTxLog(log)
+	eventModel.Chain = e.Chain
+	util.Logger.Debugf("Found bridge swap: Chain: %s, txHash: %s, token: %s, toChainId: %s, fromAddress: %s, amount: %s",
+		eventModel.Chain, eventModel.TxHash, eventModel.TokenAddr, eventModel.ToChainId, eventModel.FromAddress, eventModel.Amount)
+	return eventModel, nil
+}
+
+// alertMalformedLog reports a log of the swap agent which can not be decoded, it is dropped by the observer
+func (e *EvmExecutor) alertMalformedLog(log *types.Log, err error) {
+	msg := fmt.Sprintf("Urgent alert: drop malformed log of swap agent %s on %s, tx hash %s, log index %d, height %d: %s",
+		log.Address.String(), e.Chain, log.TxHash.String(), log.Index, log.BlockNumber, err.Error())
+	util.Logger.Errorf("%s", msg)
+	util.SendTelegramMessage(msg)
+}
+
+func (e *EvmExecutor) parseSwapFilledLog(agent *Agent, log *types.Log) interface{} {
+	event := &SwapFilledEvent{}
+	if err := DecodeEvent(agent.SwapFilled, log, event); err != nil {
+		e.alertMalformedLog(log, err)
+		return nil
+	}
+	eventModel := event.ToSwapFilledTxLog(log)
+	eventModel.Chain = e.Chain
+	util.Logger.Debugf("Found bridge fill: Chain: %s, txHash: %s, swapId: %s, toAddress: %s, amount: %s",
+		eventModel.Chain, eventModel.TxHash, eventModel.SwapId, eventModel.ToAddress, eventModel.Amount)
+	return eventModel
+}
+
+func (e *EvmExecutor) parseSwapPairRegisterLog(agent *Agent, log *types.Log) interface{} {
+	event := &SwapPairRegisterEvent{}
+	if err := DecodeEvent(agent.SwapPairRegister, log, event); err != nil {
+		e.alertMalformedLog(log, err)
+		return nil
+	}
+	eventModel := event.ToSwapPairRegisterLog(log)
+	eventModel.Chain = e.Chain
+	util.Logger.Debugf("Found swap pair register: Chain: %s, txHash: %s, sponsor: %s, erc20: %s, bep20: %s, symbol: %s",
+		eventModel.Chain, eventModel.TxHash, eventModel.Sponsor, eventModel.ERC20Addr, eventModel.BEP20Addr, eventModel.Symbol)
+	return eventModel
+}
@@ -0,0 +1,48 @@
+package executor
+
+import (
+	"fmt"
+	"sync"
+
+	"occ-swap-server/common"
+	"occ-swap-server/provider"
+	"occ-swap-server/util"
+)
+
+// Factory creates the executor of a chain
+type Factory func(client *provider.Pool, chainCfg *util.ChainInfo, config *util.Config) (Executor, error)
+
+var (
+	factoriesMutex sync.RWMutex
+	factories      = map[string]Factory{
+		common.ExecutorTypeEvm: func(client *provider.Pool, chainCfg *util.ChainInfo, config *util.Config) (Executor, error) {
+			return NewEvmExecutor(client, chainCfg, config)
+		},
+		common.ExecutorTypeReplay: func(_ *provider.Pool, chainCfg *util.ChainInfo, _ *util.Config) (Executor, error) {
+			return NewReplayExecutor(chainCfg)
+		},
+	}
+)
+
+// RegisterExecutor registers the factory of an executor type, it replaces the factory registered before
+func RegisterExecutor(executorType string, factory Factory) {
+	factoriesMutex.Lock()
+	defer factoriesMutex.Unlock()
+	factories[executorType] = factory
+}
+
+// NewExecutor creates the executor selected by executor_type of the chain, the evm executor if it is empty
+func NewExecutor(client *provider.Pool, chainCfg *util.ChainInfo, config *util.Config) (Executor, error) {
+	executorType := chainCfg.ExecutorType
+	if executorType == "" {
+		executorType = common.ExecutorTypeEvm
+	}
+
+	factoriesMutex.RLock()
+	factory, ok := factories[executorType]
+	factoriesMutex.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown executor type of %s: %s", chainCfg.Name, executorType)
+	}
+	return factory(client, chainCfg, config)
+}
@@ -0,0 +1,149 @@
+package executor
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"occ-swap-server/common"
+	"occ-swap-server/model"
+	"occ-swap-server/util"
+)
+
+// replayBlock is a line of the replay file, the blocks missing in the file have no events
+type replayBlock struct {
+	Height    int64  `json:"height"`
+	BlockHash string `json:"block_hash"`
+	BlockTime int64  `json:"block_time"`
+
+	SwapStarts        []model.SwapStartTxLog        `json:"swap_starts"`
+	SwapFills         []model.SwapFilledTxLog       `json:"swap_fills"`
+	SwapPairRegisters []model.SwapPairRegisterTxLog `json:"swap_pair_registers"`
+}
+
+// ReplayExecutor serves the blocks of a file as the events of a chain, the observer and the swap engine can be
+// tested against it without a chain
+type ReplayExecutor struct {
+	Chain string
+
+	blocks map[int64]*replayBlock
+	head   int64
+}
+
+// NewReplayExecutor reads the blocks of the replay file, one json block per line
+func NewReplayExecutor(chainCfg *util.ChainInfo) (*ReplayExecutor, error) {
+	file, err := os.Open(chainCfg.ReplayFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	executor := &ReplayExecutor{
+		Chain:  chainCfg.Name,
+		blocks: make(map[int64]*replayBlock),
+	}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var block replayBlock
+		if err := json.Unmarshal(scanner.Bytes(), &block); err != nil {
+			return nil, fmt.Errorf("parse line %d of replay file %s error: %s", lineNum, chainCfg.ReplayFile, err.Error())
+		}
+		if _, ok := executor.blocks[block.Height]; ok {
+			return nil, fmt.Errorf("duplicated block %d in replay file %s", block.Height, chainCfg.ReplayFile)
+		}
+		executor.blocks[block.Height] = &block
+		if block.Height > executor.head {
+			executor.head = block.Height
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return executor, nil
+}
+
+func (e *ReplayExecutor) GetChainName() string {
+	return e.Chain
+}
+
+func (e *ReplayExecutor) GetHeight() (int64, error) {
+	return e.head, nil
+}
+
+func (e *ReplayExecutor) GetHealth() *Health {
+	return &Health{
+		Chain:        e.Chain,
+		ExecutorType: common.ExecutorTypeReplay,
+		Healthy:      true,
+		Head:         e.head,
+	}
+}
+
+// GetBlockHash returns the hash of the block in the file, or a hash derived from the height for the missing blocks
+func (e *ReplayExecutor) GetBlockHash(height int64) (string, error) {
+	if block, ok := e.blocks[height]; ok && block.BlockHash != "" {
+		return block.BlockHash, nil
+	}
+	return crypto.Keccak256Hash([]byte(fmt.Sprintf("%s#%d", e.Chain, height))).String(), nil
+}
+
+func (e *ReplayExecutor) GetBlockAndTxEvents(height int64) (*common.BlockAndEventLogs, error) {
+	return e.GetRangeBlockAndTxEvents(height, height)
+}
+
+func (e *ReplayExecutor) GetRangeBlockAndTxEvents(from, to int64) (*common.BlockAndEventLogs, error) {
+	if to > e.head {
+		return nil, fmt.Errorf("%w: height %d, head %d", ErrBlockNotReached, to, e.head)
+	}
+
+	events := make([]interface{}, 0)
+	for height := from; height <= to; height++ {
+		block, ok := e.blocks[height]
+		if !ok {
+			continue
+		}
+		blockHash, _ := e.GetBlockHash(height)
+		// the events are copied as they are saved by the observer
+		for _, swapStart := range block.SwapStarts {
+			event := swapStart
+			event.Chain, event.Height, event.BlockHash = e.Chain, height, blockHash
+			events = append(events, &event)
+		}
+		for _, swapFill := range block.SwapFills {
+			event := swapFill
+			event.Chain, event.Height, event.BlockHash = e.Chain, height, blockHash
+			events = append(events, &event)
+		}
+		for _, register := range block.SwapPairRegisters {
+			event := register
+			event.Chain, event.Height, event.BlockHash = e.Chain, height, blockHash
+			events = append(events, &event)
+		}
+	}
+
+	blockHash, _ := e.GetBlockHash(to)
+	fromParentHash, _ := e.GetBlockHash(from - 1)
+	parentHash, _ := e.GetBlockHash(to - 1)
+	var blockTime int64
+	if block, ok := e.blocks[to]; ok {
+		blockTime = block.BlockTime
+	}
+	return &common.BlockAndEventLogs{
+		Height:          to,
+		Chain:           e.Chain,
+		BlockHash:       blockHash,
+		ParentBlockHash: parentHash,
+		BlockTime:       blockTime,
+		Events:          events,
+
+		FromHeight:          from,
+		FromParentBlockHash: fromParentHash,
+	}, nil
+}
@@ -0,0 +1,109 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"occ-swap-server/common"
+	"occ-swap-server/util"
+)
+
+func (e *EvmExecutor) Subscribe() {
+	if e.WsProvider == "" {
+		return
+	}
+	go func() {
+		for {
+			err := e.runSubscription()
+			e.setHead(nil)
+			util.Logger.Errorf("%s subscription is down, fall back to polling, err=%s", e.Chain, err.Error())
+			e.notify()
+			time.Sleep(common.WsReconnectInterval)
+		}
+	}()
+}
+
+func (e *EvmExecutor) Subscribed() bool {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return e.latestHead != nil
+}
+
+func (e *EvmExecutor) NewHeads() <-chan struct{} {
+	return e.heads
+}
+
+// runSubscription subscribes to the new heads and the logs of the swap agent until the subscription fails, the
+// observer is notified once subscribed so it backfills the blocks missed while the subscription was down
+func (e *EvmExecutor) runSubscription() error {
+	ctxWithTimeout, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := ethclient.DialContext(ctxWithTimeout, e.WsProvider)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	headCh := make(chan *types.Header, 16)
+	headSub, err := client.SubscribeNewHead(context.Background(), headCh)
+	if err != nil {
+		return err
+	}
+	defer headSub.Unsubscribe()
+
+	// the logs are only a hint to wake the observer, the latest agent is subscribed
+	latest := e.Agents[len(e.Agents)-1]
+	addresses, topics := e.filterQuery(latest.FromHeight, latest.FromHeight)
+	logCh := make(chan types.Log, 64)
+	logSub, err := client.SubscribeFilterLogs(context.Background(), ethereum.FilterQuery{
+		Topics:    topics,
+		Addresses: addresses,
+	}, logCh)
+	if err != nil {
+		return err
+	}
+	defer logSub.Unsubscribe()
+
+	util.Logger.Infof("subscribed to new heads and logs of %s", e.Chain)
+	for {
+		select {
+		case header := <-headCh:
+			e.setHead(header)
+			e.notify()
+		case log := <-logCh:
+			util.Logger.Debugf("receive %s log, txHash: %s, height: %d, removed: %t", e.Chain, log.TxHash.String(), log.BlockNumber, log.Removed)
+			e.notify()
+		case err := <-headSub.Err():
+			return fmt.Errorf("new heads subscription: %v", err)
+		case err := <-logSub.Err():
+			return fmt.Errorf("logs subscription: %v", err)
+		}
+	}
+}
+
+// setHead keeps the latest head of the subscription, nil marks the subscription down
+func (e *EvmExecutor) setHead(header *types.Header) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.latestHead = header
+}
+
+func (e *EvmExecutor) getHead() *types.Header {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return e.latestHead
+}
+
+// notify wakes the observer without blocking, one pending notification is enough
+func (e *EvmExecutor) notify() {
+	select {
+	case e.heads <- struct{}{}:
+	default:
+	}
+}
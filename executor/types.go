@@ -1,111 +1,150 @@
 package executor
 
 import (
-	"fmt"
+	"errors"
 	"math/big"
+	"strings"
 
 	common "occ-swap-server/common"
 
-	"github.com/ethereum/go-ethereum/accounts/abi"
 	ethcmm "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 
 	"occ-swap-server/model"
+	"occ-swap-server/provider"
 )
 
 type Executor interface {
 	GetBlockAndTxEvents(height int64) (*common.BlockAndEventLogs, error)
+	// GetRangeBlockAndTxEvents returns the events of the blocks [from, to] with a single log query, it returns
+	// ErrRangeTooLarge if the provider refuses the range
+	GetRangeBlockAndTxEvents(from, to int64) (*common.BlockAndEventLogs, error)
+	GetHeight() (int64, error)
+	// GetBlockHash returns the hash of the canonical block of the given height
+	GetBlockHash(height int64) (string, error)
 	GetChainName() string
+	GetHealth() *Health
 }
 
-// ===================  SwapStarted =============
-var (
-	SwapStartedEventName        = "SwapStarted"
-	ETH2BSCSwapStartedEventHash = ethcmm.HexToHash("0x7b2b39fe8cb99baf3c533665217a130daefeee1af6329eca59c5bf06a53999ac")
-	BSC2ETHSwapStartedEventHash = ethcmm.HexToHash("0x7b2b39fe8cb99baf3c533665217a130daefeee1af6329eca59c5bf06a53999ac")
-)
+// Health is reported by the executor to the observer and the admin server
+type Health struct {
+	Chain        string `json:"chain"`
+	ExecutorType string `json:"executor_type"`
+	Healthy      bool   `json:"healthy"`
+	Head         int64  `json:"head"`
+	Err          string `json:"err,omitempty"`
 
-type ETH2BSCSwapStartedEvent struct {
-	ERC20Addr ethcmm.Address
-	FromAddr  ethcmm.Address
-	Amount    *big.Int
-	FeeAmount *big.Int
+	Subscribed bool              `json:"subscribed"`
+	Providers  []provider.Status `json:"providers,omitempty"`
 }
 
-func (ev *ETH2BSCSwapStartedEvent) ToSwapStartTxLog(log *types.Log) *model.SwapStartTxLog {
-	pack := &model.SwapStartTxLog{
-		TokenAddr:   ev.ERC20Addr.String(),
-		FromAddress: ev.FromAddr.String(),
-		Amount:      ev.Amount.String(),
-
-		FeeAmount: ev.FeeAmount.String(),
-		BlockHash: log.BlockHash.Hex(),
-		TxHash:    log.TxHash.String(),
-		Height:    int64(log.BlockNumber),
-	}
-	return pack
+// HeadSubscriber is implemented by the executors which subscribe to the new heads and logs of a websocket provider
+type HeadSubscriber interface {
+	// Subscribe starts the subscription routine, it reconnects until the subscription is set up again
+	Subscribe()
+	// Subscribed returns false while the subscription is down, the observer polls then
+	Subscribed() bool
+	// NewHeads is notified on new heads, logs of the swap agent and reconnections
+	NewHeads() <-chan struct{}
 }
 
-func ParseETH2BSCSwapStartEvent(abi *abi.ABI, log *types.Log) (*ETH2BSCSwapStartedEvent, error) {
-	var ev ETH2BSCSwapStartedEvent
-
-	fmt.Printf("parse swap start event block=%s", log.Data)
+// ErrBlockNotReached is returned when the block is not produced yet
+var ErrBlockNotReached = errors.New("block not reached")
+
+// ErrRangeTooLarge is returned when the provider refuses a log query for too many blocks or results
+var ErrRangeTooLarge = errors.New("log query range too large")
+
+// the errors of the common providers when a log query is too large
+var rangeTooLargeErrors = []string{
+	"query returned more than",
+	"too many",
+	"limit exceeded",
+	"range is too large",
+	"block range",
+	"response size",
+	"query timeout exceeded",
+}
 
-	err := abi.Unpack(&ev, SwapStartedEventName, log.Data)
-	if err != nil {
-		return nil, err
+func isRangeTooLargeError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, pattern := range rangeTooLargeErrors {
+		if strings.Contains(msg, pattern) {
+			return true
+		}
 	}
+	return false
+}
 
-	ev.ERC20Addr = ethcmm.BytesToAddress(log.Topics[1].Bytes())
-	ev.FromAddr = ethcmm.BytesToAddress(log.Topics[2].Bytes())
+// ===================  SwapStarted =============
+var SwapStartedEventName = "SwapStarted"
 
-	return &ev, nil
-}
+type SwapStartedEvent struct {
+	FromChainId *big.Int       `event:"fromChainId"`
+	ToChainId   *big.Int       `event:"toChainId"`
+	FromAddress ethcmm.Address `event:"fromAddress"`
+	Amount      *big.Int       `event:"amount"`
+	FeeAmount   *big.Int       `event:"feeAmount,optional"`
 
-type BSC2ETHSwapStartedEvent struct {
-	FeeAmount   *big.Int
-	toChainId   *big.Int
-	fromAddress ethcmm.Address
-	amount      *big.Int
+	// the token deposited to the swap agent, it is resolved from the agent if the event does not carry it
+	TokenAddr ethcmm.Address `event:"tokenAddr,optional"`
 }
 
-func (ev *BSC2ETHSwapStartedEvent) ToSwapStartTxLog(log *types.Log) *model.SwapStartTxLog {
+func (ev *SwapStartedEvent) ToSwapStartTxLog(log *types.Log) *model.SwapStartTxLog {
+	feeAmount := ev.FeeAmount
+	if feeAmount == nil {
+		feeAmount = big.NewInt(0)
+	}
 	pack := &model.SwapStartTxLog{
-		FromAddress: ev.fromAddress.String(),
-		Amount:      ev.amount.String(),
-		ToChainId:   ev.toChainId.String(),
+		TokenAddr:   ev.TokenAddr.String(),
+		FromAddress: ev.FromAddress.String(),
+		Amount:      ev.Amount.String(),
+		ToChainId:   ev.ToChainId.String(),
 
-		FeeAmount: ev.FeeAmount.String(),
+		FeeAmount: feeAmount.String(),
 		BlockHash: log.BlockHash.Hex(),
 		TxHash:    log.TxHash.String(),
+		LogIndex:  log.Index,
 		Height:    int64(log.BlockNumber),
 	}
 	return pack
 }
 
-func ParseBSC2ETHSwapStartEvent(abi *abi.ABI, log *types.Log) (*BSC2ETHSwapStartedEvent, error) {
-	var ev BSC2ETHSwapStartedEvent
+// ===================  SwapFilled =============
+var SwapFilledEventName = "SwapFilled"
+
+type SwapFilledEvent struct {
+	// the contract names the first argument of fillSwap fromChainId, it carries the swap id
+	FromChainId *big.Int       `event:"fromChainId"`
+	ToChainId   *big.Int       `event:"toChainId"`
+	ToAddress   ethcmm.Address `event:"fromAddress"`
+	Amount      *big.Int       `event:"amount"`
+}
 
-	ev.toChainId = log.Topics[1].Big()
-	ev.fromAddress = ethcmm.BytesToAddress(log.Topics[2].Bytes())
-	ev.amount = log.Topics[3].Big()
+func (ev *SwapFilledEvent) ToSwapFilledTxLog(log *types.Log) *model.SwapFilledTxLog {
+	pack := &model.SwapFilledTxLog{
+		SwapId:    ethcmm.BigToHash(ev.FromChainId).Hex(),
+		ToChainId: ev.ToChainId.String(),
+		ToAddress: ev.ToAddress.String(),
+		Amount:    ev.Amount.String(),
 
-	return &ev, nil
+		BlockHash: log.BlockHash.Hex(),
+		TxHash:    log.TxHash.String(),
+		LogIndex:  log.Index,
+		Height:    int64(log.BlockNumber),
+	}
+	return pack
 }
 
 // =================  SphynxSwapPairRegister ===================
-var (
-	SwapPairRegisterEventName = "SphynxSwapPairRegister"
-	SwapPairRegisterEventHash = ethcmm.HexToHash("0x06101386f3a9dd45570dce2027311173d0e136955e5b912edece89cca5bb526d")
-)
+var SwapPairRegisterEventName = "SphynxSwapPairRegister"
 
 type SwapPairRegisterEvent struct {
-	Sponsor           ethcmm.Address
-	ContractAddr      ethcmm.Address
-	BEP20ContractAddr ethcmm.Address
-	Name              string
-	Symbol            string
-	Decimals          uint8
+	Sponsor           ethcmm.Address `event:"sponsor"`
+	ContractAddr      ethcmm.Address `event:"contractAddr"`
+	BEP20ContractAddr ethcmm.Address `event:"bep20ContractAddr"`
+	Name              string         `event:"name"`
+	Symbol            string         `event:"symbol"`
+	Decimals          uint8          `event:"decimals"`
 }
 
 func (ev *SwapPairRegisterEvent) ToSwapPairRegisterLog(log *types.Log) *model.SwapPairRegisterTxLog {
@@ -119,20 +158,8 @@ func (ev *SwapPairRegisterEvent) ToSwapPairRegisterLog(log *types.Log) *model.Sw
 
 		BlockHash: log.BlockHash.Hex(),
 		TxHash:    log.TxHash.String(),
+		LogIndex:  log.Index,
 		Height:    int64(log.BlockNumber),
 	}
 	return pack
 }
-
-func ParseSwapPairRegisterEvent(abi *abi.ABI, log *types.Log) (*SwapPairRegisterEvent, error) {
-	var ev SwapPairRegisterEvent
-
-	err := abi.Unpack(&ev, SwapPairRegisterEventName, log.Data)
-	if err != nil {
-		return nil, err
-	}
-	ev.Sponsor = ethcmm.BytesToAddress(log.Topics[1].Bytes())
-	ev.ContractAddr = ethcmm.BytesToAddress(log.Topics[2].Bytes())
-	ev.BEP20ContractAddr = ethcmm.BytesToAddress(log.Topics[3].Bytes())
-	return &ev, nil
-}
@@ -1,12 +1,13 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
+	"os"
 
 	"occ-swap-server/admin"
 
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/jinzhu/gorm"
 	_ "github.com/jinzhu/gorm/dialects/mysql"
 	_ "github.com/jinzhu/gorm/dialects/sqlite"
@@ -16,6 +17,7 @@ import (
 	"occ-swap-server/executor"
 	"occ-swap-server/model"
 	"occ-swap-server/observer"
+	"occ-swap-server/provider"
 	"occ-swap-server/swap"
 	"occ-swap-server/util"
 )
@@ -25,8 +27,14 @@ const (
 	flagConfigAwsRegion    = "aws-region"
 	flagConfigAwsSecretKey = "aws-secret-key"
 	flagConfigPath         = "config-path"
+
+	flagRescanChain = "chain"
+	flagRescanFrom  = "from"
+	flagRescanTo    = "to"
 )
 
+const cmdRescan = "rescan"
+
 const (
 	ConfigTypeLocal = "local"
 	ConfigTypeAws   = "aws"
@@ -37,6 +45,9 @@ func initFlags() {
 	flag.String(flagConfigType, "", "config type, local or aws")
 	flag.String(flagConfigAwsRegion, "", "aws s3 region")
 	flag.String(flagConfigAwsSecretKey, "", "aws s3 secret key")
+	flag.String(flagRescanChain, "", "chain to rescan")
+	flag.Int64(flagRescanFrom, 0, "first block to rescan")
+	flag.Int64(flagRescanTo, 0, "last block to rescan")
 
 	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
 	pflag.Parse()
@@ -48,6 +59,38 @@ func initFlags() {
 
 func printUsage() {
 	fmt.Print("usage: ./swap --config-type [local or aws] --config-path config_file_path\n")
+	fmt.Print("       ./swap rescan --config-type [local or aws] --config-path config_file_path --chain chain_name --from from_height --to to_height\n")
+}
+
+// rescan saves the events of the blocks [from, to] of the chain which the observer missed, and prints them
+func rescan(config *util.Config, db *gorm.DB) error {
+	chainName := viper.GetString(flagRescanChain)
+	from, to := viper.GetInt64(flagRescanFrom), viper.GetInt64(flagRescanTo)
+	// the heights default to 0, which is a valid height, so they must be given
+	if chainName == "" || !pflag.CommandLine.Changed(flagRescanFrom) || !pflag.CommandLine.Changed(flagRescanTo) ||
+		from < 0 || to < from {
+		printUsage()
+		return fmt.Errorf("invalid rescan flags, --%s, --%s and --%s are required and 0 <= from <= to", flagRescanChain, flagRescanFrom, flagRescanTo)
+	}
+	chainCfg := config.ChainConfig.GetChain(chainName)
+	if chainCfg == nil {
+		return fmt.Errorf("chain %s is not configured", chainName)
+	}
+
+	client, err := provider.Dial(chainCfg)
+	if err != nil {
+		return fmt.Errorf("new %s client error, err=%s", chainCfg.Name, err.Error())
+	}
+	chainExecutor, err := executor.NewExecutor(client, chainCfg, config)
+	if err != nil {
+		return fmt.Errorf("new %s executor error, err=%s", chainCfg.Name, err.Error())
+	}
+	report, err := observer.NewObserver(db, chainCfg, config, chainExecutor).Rescan(from, to)
+	if report != nil {
+		bz, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(bz))
+	}
+	return err
 }
 
 func main() {
@@ -56,12 +99,12 @@ func main() {
 	configType := viper.GetString(flagConfigType)
 	if configType == "" {
 		printUsage()
-		return
+		os.Exit(1)
 	}
 
 	if configType != ConfigTypeAws && configType != ConfigTypeLocal {
 		printUsage()
-		return
+		os.Exit(1)
 	}
 
 	var config *util.Config
@@ -69,26 +112,26 @@ func main() {
 		awsSecretKey := viper.GetString(flagConfigAwsSecretKey)
 		if awsSecretKey == "" {
 			printUsage()
-			return
+			os.Exit(1)
 		}
 
 		awsRegion := viper.GetString(flagConfigAwsRegion)
 		if awsRegion == "" {
 			printUsage()
-			return
+			os.Exit(1)
 		}
 
 		configContent, err := util.GetSecret(awsSecretKey, awsRegion)
 		if err != nil {
-			fmt.Printf("get aws config error, err=%s", err.Error())
-			return
+			fmt.Printf("get aws config error, err=%s\n", err.Error())
+			os.Exit(1)
 		}
 		config = util.ParseConfigFromJson(configContent)
 	} else {
 		configFilePath := viper.GetString(flagConfigPath)
 		if configFilePath == "" {
 			printUsage()
-			return
+			os.Exit(1)
 		}
 		config = util.ParseConfigFromFile(configFilePath)
 	}
@@ -105,45 +148,61 @@ func main() {
 	defer db.Close()
 	model.InitTables(db)
 
-	bscClient, err := ethclient.Dial(config.ChainConfig.BSCProvider)
-	if err != nil {
-		panic("new bsc client error")
+	if pflag.Arg(0) == cmdRescan {
+		if err := rescan(config, db); err != nil {
+			fmt.Printf("rescan error, err=%s\n", err.Error())
+			db.Close()
+			os.Exit(1)
+		}
+		return
 	}
 
-	ethClient, err := ethclient.Dial(config.ChainConfig.ETHProvider)
-	if err != nil {
-		panic("new eth client error")
+	clients := make(map[string]*provider.Pool, len(config.ChainConfig.Chains))
+	observers := make([]*observer.Observer, 0, len(config.ChainConfig.Chains))
+	for idx := range config.ChainConfig.Chains {
+		chainCfg := &config.ChainConfig.Chains[idx]
+
+		client, err := provider.Dial(chainCfg)
+		if err != nil {
+			panic(fmt.Sprintf("new %s client error, err=%s", chainCfg.Name, err.Error()))
+		}
+		clients[chainCfg.Name] = client
+
+		chainExecutor, err := executor.NewExecutor(client, chainCfg, config)
+		if err != nil {
+			panic(fmt.Sprintf("new %s executor error, err=%s", chainCfg.Name, err.Error()))
+		}
+		chainObserver := observer.NewObserver(db, chainCfg, config, chainExecutor)
+		chainObserver.Start()
+		observers = append(observers, chainObserver)
 	}
 
-	maticClient, err := ethclient.Dial(config.ChainConfig.MATICProvider)
+	swapEngine, err := swap.NewSwapEngine(db, config, clients)
 	if err != nil {
-		panic("new matic client error")
+		panic(fmt.Sprintf("create swap engine error, err=%s", err.Error()))
 	}
 
-	bscExecutor := executor.NewBSCExecutor(bscClient, config.ChainConfig.BSCSwapAgentAddr, config, 97)
-	bscObserver := observer.NewObserver(db, config.ChainConfig.BSCStartHeight, config.ChainConfig.BSCConfirmNum, config, bscExecutor)
-	bscObserver.Start()
-
-	ethExecutor := executor.NewBSCExecutor(ethClient, config.ChainConfig.ETHSwapAgentAddr, config, 4)
-	ethObserver := observer.NewObserver(db, config.ChainConfig.ETHStartHeight, config.ChainConfig.ETHConfirmNum, config, ethExecutor)
-	ethObserver.Start()
-
-	maticExecutor := executor.NewBSCExecutor(maticClient, config.ChainConfig.MATICSwapAgentAddr, config, 338)
-	maticObserver := observer.NewObserver(db, config.ChainConfig.MATICStartHeight, config.ChainConfig.MATICConfirmNum, config, maticExecutor)
-	maticObserver.Start()
+	swapEngine.Start()
 
-	swapEngine, err := swap.NewSwapEngine(db, config, bscClient, ethClient, maticClient)
+	swapPairEngine, err := swap.NewSwapPairEngine(db, config, swapEngine)
 	if err != nil {
-		panic(fmt.Sprintf("create swap engine error, err=%s", err.Error()))
+		panic(fmt.Sprintf("create swap pair engine error, err=%s", err.Error()))
 	}
 
-	swapEngine.Start()
+	swapPairEngine.Start()
 
 	signer, err := util.NewHmacSignerFromConfig(config)
 	if err != nil {
 		panic(fmt.Sprintf("new hmac singer error, err=%s", err.Error()))
 	}
-	admin := admin.NewAdmin(config, db, signer, swapEngine)
+	approvers, err := util.NewApproverSignersFromConfig(config)
+	if err != nil {
+		panic(fmt.Sprintf("new approver signers error, err=%s", err.Error()))
+	}
+	if len(approvers) < config.AdminConfig.RequiredApprovals {
+		panic(fmt.Sprintf("%d approver keys are less than required_approvals %d", len(approvers), config.AdminConfig.RequiredApprovals))
+	}
+	admin := admin.NewAdmin(config, db, signer, approvers, swapEngine, observers)
 	go admin.Serve()
 
 	select {}
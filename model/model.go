@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"github.com/jinzhu/gorm"
+
+	"occ-swap-server/common"
 )
 
 type TxPhase int
@@ -24,6 +26,8 @@ const (
 	FillTxSuccess FillTxStatus = 2
 	FillTxFailed  FillTxStatus = 3
 	FillTxMissing FillTxStatus = 4
+	// the fill tx is replaced by a tx with the same nonce and a higher gas price
+	FillTxReplaced FillTxStatus = 5
 
 	FillRetryTxCreated FillRetryTxStatus = 0
 	FillRetryTxSent    FillRetryTxStatus = 1
@@ -51,15 +55,120 @@ func (l *BlockLog) BeforeCreate() (err error) {
 	return nil
 }
 
+// SignerNonce is the next nonce to be used by a signer on a chain
+type SignerNonce struct {
+	Id         int64
+	Chain      string `gorm:"not null;unique_index:signer_nonce_chain_signer"`
+	Signer     string `gorm:"not null;unique_index:signer_nonce_chain_signer"`
+	Nonce      uint64 `gorm:"not null"`
+	UpdateTime int64
+	CreateTime int64
+}
+
+func (SignerNonce) TableName() string {
+	return "signer_nonces"
+}
+
+func (l *SignerNonce) BeforeCreate() (err error) {
+	l.CreateTime = time.Now().Unix()
+	l.UpdateTime = time.Now().Unix()
+	return nil
+}
+
+// Pause stops sending the fills of its scope until the admin lifts it, the lifted pauses are soft deleted
+type Pause struct {
+	gorm.Model
+
+	Scope common.PauseScope `gorm:"not null;index:pause_scope;unique_index:pause_active"`
+	// the chain name of chain pauses, the swap direction of direction pauses, empty for the global pause
+	Target string `gorm:"not null;unique_index:pause_active"`
+	// true while the pause is in force and null once it is lifted, a scope is paused at most once at a time
+	Active *bool `gorm:"unique_index:pause_active" json:"-"`
+	Reason string
+	// admin, or the anomaly which tripped the circuit breaker
+	PausedBy string `gorm:"not null"`
+}
+
+func (Pause) TableName() string {
+	return "pauses"
+}
+
+// InsertPause pauses the scope unless it is paused already, it returns whether a pause is inserted
+func InsertPause(db *gorm.DB, scope common.PauseScope, target, reason, pausedBy string) (bool, error) {
+	var paused int64
+	if err := db.Model(Pause{}).Where("scope = ? and target = ?", scope, target).Count(&paused).Error; err != nil {
+		return false, err
+	}
+	if paused > 0 {
+		return false, nil
+	}
+	active := true
+	pause := Pause{
+		Scope:    scope,
+		Target:   target,
+		Active:   &active,
+		Reason:   reason,
+		PausedBy: pausedBy,
+	}
+	if err := db.Create(&pause).Error; err != nil {
+		// a concurrent insert of the same scope is refused by the unique key of the active pauses
+		if db.Model(Pause{}).Where("scope = ? and target = ?", scope, target).Count(&paused).Error == nil && paused > 0 {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// LiftPauses lifts the pauses of the scope, it returns the number of the lifted pauses
+func LiftPauses(db *gorm.DB, scope common.PauseScope, target string) (int64, error) {
+	lift := db.Model(Pause{}).Where("scope = ? and target = ?", scope, target).UpdateColumns(
+		map[string]interface{}{
+			"active":     nil,
+			"deleted_at": time.Now(),
+		})
+	return lift.RowsAffected, lift.Error
+}
+
+// activatePauses marks the pauses in force before the unique key of the active pauses existed, the duplicate pauses
+// of a scope are lifted
+func activatePauses(db *gorm.DB) {
+	pauses := make([]Pause, 0)
+	db.Where("active is null").Order("id asc").Find(&pauses)
+	for _, pause := range pauses {
+		var paused int64
+		db.Model(Pause{}).Where("scope = ? and target = ? and active is not null", pause.Scope, pause.Target).Count(&paused)
+		if paused > 0 {
+			db.Model(Pause{}).Where("id = ?", pause.ID).UpdateColumn("deleted_at", time.Now())
+		} else {
+			db.Model(Pause{}).Where("id = ?", pause.ID).UpdateColumn("active", true)
+		}
+	}
+}
+
 func InitTables(db *gorm.DB) {
 	db.AutoMigrate(&SwapPair{})
 	db.AutoMigrate(&SwapFillTx{})
 	db.AutoMigrate(&Swap{})
 	db.AutoMigrate(&SwapStartTxLog{})
+	db.AutoMigrate(&SwapFilledTxLog{})
 	db.AutoMigrate(&BlockLog{})
 	db.AutoMigrate(&SwapPairCreatTx{})
 	db.AutoMigrate(&SwapPairRegisterTxLog{})
 	db.AutoMigrate(&SwapPairStateMachine{})
 	db.AutoMigrate(&RetrySwap{})
 	db.AutoMigrate(&RetrySwapTx{})
+	db.AutoMigrate(&SignerNonce{})
+	db.AutoMigrate(&SwapRefund{})
+	db.AutoMigrate(&SwapApproval{})
+	db.AutoMigrate(&Pause{})
+	// a tx may start several swaps, the refunds are unique by the start tx hash and log index
+	if db.Dialect().HasIndex(SwapRefund{}.TableName(), "swap_refund_start_tx_hash") {
+		db.Model(&SwapRefund{}).RemoveIndex("swap_refund_start_tx_hash")
+	}
+	// replaced by the unique index of the start tx hash and log index
+	if db.Dialect().HasIndex(Swap{}.TableName(), "swap_start_tx") {
+		db.Model(&Swap{}).RemoveIndex("swap_start_tx")
+	}
+	activatePauses(db)
 }
@@ -10,7 +10,7 @@ import (
 
 type SwapStartTxLog struct {
 	Id    int64
-	Chain string `gorm:"not null;index:swap_start_tx_log_chain"`
+	Chain string `gorm:"not null;index:swap_start_tx_log_chain;unique_index:swap_start_tx_log_event"`
 
 	TokenAddr   string `gorm:"not null"`
 	FromAddress string `gorm:"not null"`
@@ -19,7 +19,8 @@ type SwapStartTxLog struct {
 	ToChainId   string `gorm:"not null"`
 
 	Status       TxStatus `gorm:"not null;index:swap_start_tx_log_status"`
-	TxHash       string   `gorm:"not null;index:swap_start_tx_log_tx_hash"`
+	TxHash       string   `gorm:"not null;index:swap_start_tx_log_tx_hash;unique_index:swap_start_tx_log_event"`
+	LogIndex     uint     `gorm:"not null;default:0;unique_index:swap_start_tx_log_event"`
 	BlockHash    string   `gorm:"not null"`
 	Height       int64    `gorm:"not null"`
 	ConfirmedNum int64    `gorm:"not null"`
@@ -40,17 +41,71 @@ func (l *SwapStartTxLog) BeforeCreate() (err error) {
 	return nil
 }
 
+// SwapFilledTxLog is a SwapFilled event emitted by the swap agent of a chain when a swap is paid out
+type SwapFilledTxLog struct {
+	Id    int64
+	Chain string `gorm:"not null;index:swap_filled_tx_log_chain;unique_index:swap_filled_tx_log_event"`
+
+	// SwapId is the first argument of the fillSwap call, it identifies the paid out swap
+	SwapId    string `gorm:"not null;index:swap_filled_tx_log_swap_id"`
+	ToChainId string `gorm:"not null"`
+	ToAddress string `gorm:"not null"`
+	Amount    string `gorm:"not null"`
+
+	// start tx hash and log index of the swap paid out by this event, empty until the event is correlated
+	StartTxHash   string `gorm:"not null;index:swap_filled_tx_log_start_tx_hash"`
+	StartLogIndex uint   `gorm:"not null;default:0"`
+
+	Status       TxStatus `gorm:"not null;index:swap_filled_tx_log_status"`
+	TxHash       string   `gorm:"not null;index:swap_filled_tx_log_tx_hash;unique_index:swap_filled_tx_log_event"`
+	LogIndex     uint     `gorm:"not null;default:0;unique_index:swap_filled_tx_log_event"`
+	BlockHash    string   `gorm:"not null"`
+	Height       int64    `gorm:"not null"`
+	ConfirmedNum int64    `gorm:"not null"`
+
+	Phase TxPhase `gorm:"not null;index:swap_filled_tx_log_phase"`
+
+	UpdateTime int64
+	CreateTime int64
+}
+
+func (SwapFilledTxLog) TableName() string {
+	return "swap_filled_txs"
+}
+
+func (l *SwapFilledTxLog) BeforeCreate() (err error) {
+	l.CreateTime = time.Now().Unix()
+	l.UpdateTime = time.Now().Unix()
+	return nil
+}
+
 type SwapFillTx struct {
 	gorm.Model
 
-	Direction         common.SwapDirection `gorm:"not null"`
-	StartSwapTxHash   string               `gorm:"not null;index:swap_fill_tx_start_swap_tx_hash"`
-	FillSwapTxHash    string               `gorm:"not null;index:swap_fill_tx_fill_swap_tx_hash"`
-	GasPrice          string               `gorm:"not null"`
+	// the chain the fill tx is sent to
+	Chain           string               `gorm:"not null;index:swap_fill_tx_chain"`
+	Direction       common.SwapDirection `gorm:"not null"`
+	StartSwapTxHash string               `gorm:"not null;index:swap_fill_tx_start_swap_tx_hash"`
+	StartLogIndex   uint                 `gorm:"not null;default:0"`
+	// the swap id passed to fillSwap
+	SwapId         string `gorm:"index:swap_fill_tx_swap_id"`
+	FillSwapTxHash string `gorm:"not null;index:swap_fill_tx_fill_swap_tx_hash"`
+	Nonce          uint64 `gorm:"not null"`
+	// gas price of legacy txs, or the max fee per gas of dynamic fee txs
+	GasPrice  string `gorm:"not null"`
+	GasTipCap string
+	// base fee of the block the tx is mined in
+	BaseFee           string
 	ConsumedFeeAmount string
 	Height            int64
 	Status            FillTxStatus `gorm:"not null"`
 	TrackRetryCounter int64
+
+	// the chain height when the fill tx is sent
+	SentHeight int64
+	// the fill tx replaced by this one, the replacements of a fill tx share its nonce
+	ReplacedTxHash string
+	BumpCounter    int64
 }
 
 func (SwapFillTx) TableName() string {
@@ -74,6 +129,12 @@ type RetrySwap struct {
 
 	ToChainId string `gorm:"not null;index:retry_swap_tochainid"`
 
+	// log index of the start event and the id passed to fillSwap of the retried swap, SwapID is the id of its row
+	StartLogIndex uint `gorm:"not null;default:0"`
+	FillSwapId    string
+	// the retry swap was held by a velocity limit and released by the admin, it is not checked against the limits again
+	VelocityReleased bool `gorm:"not null;default:false"`
+
 	RecordHash string `gorm:"not null"`
 	ErrorMsg   string
 }
@@ -85,16 +146,24 @@ func (RetrySwap) TableName() string {
 type RetrySwapTx struct {
 	gorm.Model
 
-	RetrySwapID         uint                 `gorm:"not null;index:retry_swap_tx_retry_swap_id"`
-	StartTxHash         string               `gorm:"not null;index:retry_swap_tx_start_tx_hash"`
+	RetrySwapID   uint   `gorm:"not null;index:retry_swap_tx_retry_swap_id"`
+	StartTxHash   string `gorm:"not null;index:retry_swap_tx_start_tx_hash"`
+	StartLogIndex uint   `gorm:"not null;default:0"`
+	// the swap id passed to fillSwap
+	SwapId              string               `gorm:"index:retry_swap_tx_swap_id"`
+	Chain               string               `gorm:"not null;index:retry_swap_tx_chain"`
 	Direction           common.SwapDirection `gorm:"not null"`
 	TrackRetryCounter   int64
 	RetryFillSwapTxHash string            `gorm:"not null"`
 	Status              FillRetryTxStatus `gorm:"not null"`
 	ErrorMsg            string            `gorm:"not null"`
-	GasPrice            string
-	ConsumedFeeAmount   string
-	Height              int64
+	Nonce               uint64            `gorm:"not null"`
+	// gas price of legacy txs, or the max fee per gas of dynamic fee txs
+	GasPrice          string
+	GasTipCap         string
+	BaseFee           string
+	ConsumedFeeAmount string
+	Height            int64
 }
 
 func (RetrySwapTx) TableName() string {
@@ -111,18 +180,27 @@ type Swap struct {
 
 	BEP20Addr string `gorm:"not null;index:swap_bep20_addr"`
 	ERC20Addr string `gorm:"not null;index:swap_erc20_addr"`
-	Symbol    string
-	Amount    string               `gorm:"not null;index:swap_amount"`
-	Decimals  int                  `gorm:"not null"`
-	Direction common.SwapDirection `gorm:"not null;index:swap_direction"`
+	// the token paid out on the destination chain, one of BEP20Addr and ERC20Addr
+	ToTokenAddr string
+	Symbol      string
+	Amount      string               `gorm:"not null;index:swap_amount"`
+	Decimals    int                  `gorm:"not null"`
+	Direction   common.SwapDirection `gorm:"not null;index:swap_direction"`
 
-	// The tx hash confirmed deposit
-	StartTxHash string `gorm:"not null;index:swap_start_tx_hash"`
+	// The tx hash confirmed deposit and the index of its start event, a tx may start several swaps
+	StartTxHash   string `gorm:"not null;unique_index:swap_start_tx_event"`
+	StartLogIndex uint   `gorm:"not null;default:0;unique_index:swap_start_tx_event"`
+	// the id passed to fillSwap, empty for the swaps created before it is recorded, their id is the start tx hash
+	SwapId string `gorm:"index:swap_swap_id"`
 	// The tx hash confirmed withdraw
 	FillTxHash string `gorm:"not null;index:swap_fill_tx_hash"`
 
 	// used to log more message about how this swap failed or invalid
 	Log string
+	// the swap was held by a velocity limit and released by the admin, it is not checked against the limits again
+	VelocityReleased bool `gorm:"not null;default:false"`
+	// the swap was above the approval threshold of its token and approved by enough approvers
+	Approved bool `gorm:"not null;default:false"`
 
 	RecordHash string `gorm:"not null"`
 }
@@ -130,3 +208,57 @@ type Swap struct {
 func (Swap) TableName() string {
 	return "swaps"
 }
+
+// SwapApproval is the decision of an approver on a swap waiting for approvals, SwapRecordHash is the record hash of
+// the swap when it was decided so the decision only counts for that state of the swap
+type SwapApproval struct {
+	gorm.Model
+
+	SwapID         uint   `gorm:"not null;index:swap_approval_swap_id"`
+	StartTxHash    string `gorm:"not null"`
+	StartLogIndex  uint   `gorm:"not null;default:0"`
+	SwapRecordHash string `gorm:"not null;unique_index:swap_approval_approver"`
+	// the api key of the approver
+	Approver string                      `gorm:"not null;unique_index:swap_approval_approver"`
+	Decision common.SwapApprovalDecision `gorm:"not null"`
+	Reason   string
+
+	RecordHash string `gorm:"not null"`
+}
+
+func (SwapApproval) TableName() string {
+	return "swap_approvals"
+}
+
+// SwapRefund pays a rejected swap back to its sponsor on the source chain
+type SwapRefund struct {
+	gorm.Model
+
+	// the source chain of the swap, the refund is sent to it
+	Chain         string `gorm:"not null;index:swap_refund_chain"`
+	StartTxHash   string `gorm:"not null;unique_index:swap_refund_start_tx"`
+	StartLogIndex uint   `gorm:"not null;default:0;unique_index:swap_refund_start_tx"`
+	// the swap id of the refunded swap, empty for the refunds created before it is recorded
+	SwapId    string
+	Sponsor   string `gorm:"not null"`
+	TokenAddr string `gorm:"not null"`
+	Amount    string `gorm:"not null"`
+	Reason    string
+
+	Status       common.SwapRefundStatus `gorm:"not null;index:swap_refund_status"`
+	RefundTxHash string                  `gorm:"index:swap_refund_refund_tx_hash"`
+	Nonce        uint64
+	GasPrice     string
+	ErrorMsg     string
+
+	Height            int64
+	BaseFee           string
+	ConsumedFeeAmount string
+	TrackRetryCounter int64
+
+	RecordHash string
+}
+
+func (SwapRefund) TableName() string {
+	return "swap_refunds"
+}
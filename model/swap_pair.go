@@ -41,6 +41,7 @@ type SwapPairRegisterTxLog struct {
 
 	Status       TxStatus `gorm:"not null;index:swappair_register_tx_log_status"`
 	TxHash       string   `gorm:"not null;index:swappair_register_tx_log_tx_hash"`
+	LogIndex     uint     `gorm:"not null;default:0"`
 	BlockHash    string   `gorm:"not null"`
 	Height       int64    `gorm:"not null"`
 	ConfirmedNum int64    `gorm:"not null"`
@@ -64,6 +65,9 @@ func (l *SwapPairRegisterTxLog) BeforeCreate() (err error) {
 type SwapPairCreatTx struct {
 	gorm.Model
 
+	// the chain the swap pair is created on
+	Chain string `gorm:"index:swap_pair_creat_tx_chain"`
+
 	SwapPairRegisterTxHash string `gorm:"unique;not null"`
 	SwapPairCreatTxHash    string `gorm:"unique;not null"`
 
@@ -74,6 +78,7 @@ type SwapPairCreatTx struct {
 	Name     string `gorm:"not null"`
 	Decimals int    `gorm:"not null"`
 
+	Nonce             uint64
 	GasPrice          string `gorm:"not null"`
 	ConsumedFeeAmount string
 	Height            int64
@@ -90,6 +95,10 @@ type SwapPairStateMachine struct {
 
 	Status common.SwapPairStatus `gorm:"not null;index:swap_pair_sm_status"`
 
+	// the chain the swap pair is registered on and the chain it is created on
+	Chain   string
+	ToChain string
+
 	ERC20Addr string `gorm:"not null"`
 	BEP20Addr string `gorm:"not null"`
 
@@ -1,6 +1,7 @@
 package observer
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -15,20 +16,45 @@ import (
 type Observer struct {
 	DB *gorm.DB
 
-	StartHeight int64
-	ConfirmNum  int64
+	StartHeight   int64
+	ConfirmNum    int64
+	FetchInterval int64
+
+	// the observer scans ranges of blocks while it is more than CatchUpDistance blocks behind the head, the range
+	// shrinks when the provider refuses it and grows back to MaxFetchRange
+	MaxFetchRange   int64
+	CatchUpDistance int64
+	fetchRange      int64
+	catchingUp      bool
+
+	headHeight     int64
+	headUpdateTime time.Time
 
 	Config   *util.Config
 	Executor executor.Executor
 }
 
 // NewObserver returns the observer instance
-func NewObserver(db *gorm.DB, startHeight, confirmNum int64, cfg *util.Config, executor executor.Executor) *Observer {
+func NewObserver(db *gorm.DB, chainCfg *util.ChainInfo, cfg *util.Config, executor executor.Executor) *Observer {
+	maxFetchRange := chainCfg.MaxFetchRange
+	if maxFetchRange == 0 {
+		maxFetchRange = common.ObserverDefaultMaxFetchRange
+	}
+	catchUpDistance := chainCfg.CatchUpDistance
+	if catchUpDistance == 0 {
+		catchUpDistance = common.ObserverDefaultCatchUpDistance
+	}
+
 	return &Observer{
 		DB: db,
 
-		StartHeight: startHeight,
-		ConfirmNum:  confirmNum,
+		StartHeight:   chainCfg.StartHeight,
+		ConfirmNum:    chainCfg.ConfirmNum,
+		FetchInterval: chainCfg.ObserverFetchInterval,
+
+		MaxFetchRange:   maxFetchRange,
+		CatchUpDistance: catchUpDistance,
+		fetchRange:      maxFetchRange,
 
 		Config:   cfg,
 		Executor: executor,
@@ -37,23 +63,32 @@ func NewObserver(db *gorm.DB, startHeight, confirmNum int64, cfg *util.Config, e
 
 // Start starts the routines of observer
 func (ob *Observer) Start() {
+	if subscriber, ok := ob.Executor.(executor.HeadSubscriber); ok {
+		subscriber.Subscribe()
+	}
 	go ob.Fetch(ob.StartHeight)
 	go ob.Prune()
 	go ob.Alert()
 }
 
 func (ob *Observer) fetchSleep() {
-	if ob.Executor.GetChainName() == common.ChainBSC {
-		time.Sleep(time.Duration(ob.Config.ChainConfig.BSCObserverFetchInterval) * time.Second)
-	} else if ob.Executor.GetChainName() == common.ChainETH {
-		time.Sleep(time.Duration(ob.Config.ChainConfig.ETHObserverFetchInterval) * time.Second)
-	} else if ob.Executor.GetChainName() == common.ChainMATIC {
-		time.Sleep(time.Duration(ob.Config.ChainConfig.MATICObserverFetchInterval) * time.Second)
-	}
+	time.Sleep(time.Duration(ob.FetchInterval) * time.Second)
+}
 
+// waitNextBlock waits for a new head while the executor is subscribed, and polls otherwise
+func (ob *Observer) waitNextBlock() {
+	subscriber, ok := ob.Executor.(executor.HeadSubscriber)
+	if !ok || !subscriber.Subscribed() {
+		ob.fetchSleep()
+		return
+	}
+	select {
+	case <-subscriber.NewHeads():
+	case <-time.After(common.ObserverSubscriptionMaxWait):
+	}
 }
 
-// Fetch starts the main routine for fetching blocks of BSC
+// Fetch starts the main routine for fetching blocks of the observed chain
 func (ob *Observer) Fetch(startHeight int64) {
 	for {
 		curBlockLog, err := ob.GetCurrentBlockLog()
@@ -68,13 +103,52 @@ func (ob *Observer) Fetch(startHeight int64) {
 			nextHeight = startHeight
 		}
 
-		util.Logger.Debugf("fetch %s block, height=%d", ob.Executor.GetChainName(), nextHeight)
-		err = ob.fetchBlock(curBlockLog.Height, nextHeight, curBlockLog.BlockHash)
+		if toHeight := ob.catchUpRangeEnd(nextHeight); toHeight > nextHeight {
+			util.Logger.Debugf("fetch %s blocks, height=[%d, %d]", ob.Executor.GetChainName(), nextHeight, toHeight)
+			err = ob.fetchRangeBlocks(curBlockLog.Height, nextHeight, toHeight, curBlockLog.BlockHash)
+		} else {
+			util.Logger.Debugf("fetch %s block, height=%d", ob.Executor.GetChainName(), nextHeight)
+			err = ob.fetchBlock(curBlockLog.Height, nextHeight, curBlockLog.BlockHash)
+		}
 		if err != nil {
 			util.Logger.Debugf("fetch %s block error, err=%s", ob.Executor.GetChainName(), err.Error())
-			ob.fetchSleep()
+			ob.waitNextBlock()
+		}
+	}
+}
+
+// catchUpRangeEnd returns the last block of the range to scan, it is not larger than nextHeight in the steady
+// state. The head is queried at most once per fetch interval, the range ends confirm_num blocks behind it so
+// it is never reorganized
+func (ob *Observer) catchUpRangeEnd(nextHeight int64) int64 {
+	if time.Since(ob.headUpdateTime) >= time.Duration(ob.FetchInterval)*time.Second {
+		headHeight, err := ob.Executor.GetHeight()
+		if err != nil {
+			util.Logger.Debugf("get %s head height error, err=%s", ob.Executor.GetChainName(), err.Error())
+		} else {
+			ob.headHeight = headHeight
+			ob.headUpdateTime = time.Now()
 		}
 	}
+
+	catchingUp := ob.headHeight-nextHeight > ob.CatchUpDistance
+	if catchingUp != ob.catchingUp {
+		ob.catchingUp = catchingUp
+		if catchingUp {
+			util.Logger.Infof("%s observer switches to catch-up mode, height=%d, head=%d", ob.Executor.GetChainName(), nextHeight, ob.headHeight)
+		} else {
+			util.Logger.Infof("%s observer switches to steady mode, height=%d, head=%d", ob.Executor.GetChainName(), nextHeight, ob.headHeight)
+		}
+	}
+	if !catchingUp {
+		return nextHeight
+	}
+
+	toHeight := nextHeight + ob.fetchRange - 1
+	if safeHeight := ob.headHeight - ob.ConfirmNum; toHeight > safeHeight {
+		toHeight = safeHeight
+	}
+	return toHeight
 }
 
 // fetchBlock fetches the next block of BSC and saves it to database. if the next block hash
@@ -84,14 +158,45 @@ func (ob *Observer) fetchBlock(curHeight, nextHeight int64, curBlockHash string)
 	if err != nil {
 		return fmt.Errorf("get block info error, height=%d, err=%s", nextHeight, err.Error())
 	}
+	return ob.saveBlockAndEventLogs(curHeight, curBlockHash, blockAndEventLogs)
+}
+
+// fetchRangeBlocks fetches the events of the blocks [fromHeight, toHeight] with one log query, the range is halved
+// if the provider refuses it and doubled after each success
+func (ob *Observer) fetchRangeBlocks(curHeight, fromHeight, toHeight int64, curBlockHash string) error {
+	blockAndEventLogs, err := ob.Executor.GetRangeBlockAndTxEvents(fromHeight, toHeight)
+	if errors.Is(err, executor.ErrRangeTooLarge) {
+		ob.fetchRange = (toHeight - fromHeight + 1) / 2
+		if ob.fetchRange < 1 {
+			ob.fetchRange = 1
+		}
+		util.Logger.Infof("shrink %s fetch range to %d blocks, err=%s", ob.Executor.GetChainName(), ob.fetchRange, err.Error())
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("get blocks info error, height=[%d, %d], err=%s", fromHeight, toHeight, err.Error())
+	}
+
+	if err := ob.saveBlockAndEventLogs(curHeight, curBlockHash, blockAndEventLogs); err != nil {
+		return err
+	}
+	if ob.fetchRange < ob.MaxFetchRange {
+		ob.fetchRange *= 2
+		if ob.fetchRange > ob.MaxFetchRange {
+			ob.fetchRange = ob.MaxFetchRange
+		}
+	}
+	return nil
+}
 
-	parentHash := blockAndEventLogs.ParentBlockHash
-	if curHeight != 0 && ((parentHash == curBlockHash && blockAndEventLogs.Chain == "ETH") || (parentHash != curBlockHash && blockAndEventLogs.Chain == "BSC")) {
-		return ob.DeleteBlockAndTxEvents(curHeight)
+// saveBlockAndEventLogs saves the fetched blocks and their events, only the header of the last block is saved
+func (ob *Observer) saveBlockAndEventLogs(curHeight int64, curBlockHash string, blockAndEventLogs *common.BlockAndEventLogs) error {
+	if curHeight != 0 && blockAndEventLogs.FromParentBlockHash != curBlockHash {
+		return ob.Rollback(curHeight)
 	} else {
 		nextBlockLog := model.BlockLog{
 			BlockHash:  blockAndEventLogs.BlockHash,
-			ParentHash: parentHash,
+			ParentHash: blockAndEventLogs.ParentBlockHash,
 			Height:     blockAndEventLogs.Height,
 			BlockTime:  blockAndEventLogs.BlockTime,
 			Chain:      blockAndEventLogs.Chain,
@@ -110,55 +215,37 @@ func (ob *Observer) fetchBlock(curHeight, nextHeight int64, curBlockHash string)
 		if err != nil {
 			return err
 		}
-	}
-	return nil
-}
-
-// DeleteBlockAndTxEvents deletes the block and txs of the given height
-func (ob *Observer) DeleteBlockAndTxEvents(height int64) error {
-	tx := ob.DB.Begin()
-	if err := tx.Error; err != nil {
-		return err
-	}
-
-	if err := tx.Where("height = ?", height).Delete(model.BlockLog{}).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
-
-	txEventLogList := make([]model.SwapStartTxLog, 0)
-	ob.DB.Where("chain = ? and height = ? and status = ?", ob.Executor.GetChainName(), height, model.TxStatusInit).Find(&txEventLogList)
-	for _, txEventLog := range txEventLogList {
-		if err := tx.Where("start_tx_hash = ?", txEventLog.TxHash).Delete(model.Swap{}).Error; err != nil {
-			tx.Rollback()
+		err = ob.UpdateSwapFilledConfirmedNum(nextBlockLog.Height)
+		if err != nil {
 			return err
 		}
 	}
+	return nil
+}
 
-	if err := tx.Where("chain = ? and height = ? and status = ?", ob.Executor.GetChainName(), height, model.TxStatusInit).Delete(model.SwapStartTxLog{}).Error; err != nil {
-		tx.Rollback()
+func (ob *Observer) UpdateSwapStartConfirmedNum(height int64) error {
+	err := ob.DB.Model(model.SwapStartTxLog{}).Where("chain = ? and status = ?", ob.Executor.GetChainName(), model.TxStatusInit).Updates(
+		map[string]interface{}{
+			"confirmed_num": gorm.Expr("? - height", height+1),
+		}).Error
+	if err != nil {
 		return err
 	}
 
-	registerLogList := make([]model.SwapPairRegisterTxLog, 0)
-	ob.DB.Where("chain = ? and height = ? and status = ?", ob.Executor.GetChainName(), height, model.TxStatusInit).Find(&registerLogList)
-	for _, registerLog := range registerLogList {
-		if err := tx.Where("swap_pair_register_tx_hash = ?", registerLog.TxHash).Delete(model.SwapPairStateMachine{}).Error; err != nil {
-			tx.Rollback()
-			return err
-		}
-	}
-
-	if err := tx.Where("chain = ? and height = ? and status = ?", ob.Executor.GetChainName(), height, model.TxStatusInit).Delete(model.SwapPairRegisterTxLog{}).Error; err != nil {
-		tx.Rollback()
+	err = ob.DB.Model(model.SwapStartTxLog{}).Where("chain = ? and status = ? and confirmed_num >= ?",
+		ob.Executor.GetChainName(), model.TxStatusInit, ob.ConfirmNum).Updates(
+		map[string]interface{}{
+			"status": model.TxStatusConfirmed,
+		}).Error
+	if err != nil {
 		return err
 	}
 
-	return tx.Commit().Error
+	return nil
 }
 
-func (ob *Observer) UpdateSwapStartConfirmedNum(height int64) error {
-	err := ob.DB.Model(model.SwapStartTxLog{}).Where("chain = ? and status = ?", ob.Executor.GetChainName(), model.TxStatusInit).Updates(
+func (ob *Observer) UpdateSwapPairRegisterConfirmedNum(height int64) error {
+	err := ob.DB.Model(model.SwapPairRegisterTxLog{}).Where("chain = ? and status = ?", ob.Executor.GetChainName(), model.TxStatusInit).Updates(
 		map[string]interface{}{
 			"confirmed_num": gorm.Expr("? - height", height+1),
 		}).Error
@@ -166,7 +253,7 @@ func (ob *Observer) UpdateSwapStartConfirmedNum(height int64) error {
 		return err
 	}
 
-	err = ob.DB.Model(model.SwapStartTxLog{}).Where("chain = ? and status = ? and confirmed_num >= ?",
+	err = ob.DB.Model(model.SwapPairRegisterTxLog{}).Where("chain = ? and status = ? and confirmed_num >= ?",
 		ob.Executor.GetChainName(), model.TxStatusInit, ob.ConfirmNum).Updates(
 		map[string]interface{}{
 			"status": model.TxStatusConfirmed,
@@ -178,8 +265,8 @@ func (ob *Observer) UpdateSwapStartConfirmedNum(height int64) error {
 	return nil
 }
 
-func (ob *Observer) UpdateSwapPairRegisterConfirmedNum(height int64) error {
-	err := ob.DB.Model(model.SwapPairRegisterTxLog{}).Where("chain = ? and status = ?", ob.Executor.GetChainName(), model.TxStatusInit).Updates(
+func (ob *Observer) UpdateSwapFilledConfirmedNum(height int64) error {
+	err := ob.DB.Model(model.SwapFilledTxLog{}).Where("chain = ? and status = ?", ob.Executor.GetChainName(), model.TxStatusInit).Updates(
 		map[string]interface{}{
 			"confirmed_num": gorm.Expr("? - height", height+1),
 		}).Error
@@ -187,7 +274,7 @@ func (ob *Observer) UpdateSwapPairRegisterConfirmedNum(height int64) error {
 		return err
 	}
 
-	err = ob.DB.Model(model.SwapPairRegisterTxLog{}).Where("chain = ? and status = ? and confirmed_num >= ?",
+	err = ob.DB.Model(model.SwapFilledTxLog{}).Where("chain = ? and status = ? and confirmed_num >= ?",
 		ob.Executor.GetChainName(), model.TxStatusInit, ob.ConfirmNum).Updates(
 		map[string]interface{}{
 			"status": model.TxStatusConfirmed,
@@ -247,7 +334,35 @@ func (ob *Observer) GetCurrentBlockLog() (*model.BlockLog, error) {
 	return &blockLog, nil
 }
 
-// Alert sends alerts to tg group if there is no new block fetched in a specific time
+// Status is the progress of the observer and the health of its executor
+type Status struct {
+	Chain     string           `json:"chain"`
+	Height    int64            `json:"height"`
+	BlockTime int64            `json:"block_time"`
+	Lag       int64            `json:"lag"`
+	Executor  *executor.Health `json:"executor"`
+}
+
+// Status returns the last fetched block and the health of the executor
+func (ob *Observer) Status() (*Status, error) {
+	curBlockLog, err := ob.GetCurrentBlockLog()
+	if err != nil {
+		return nil, err
+	}
+	health := ob.Executor.GetHealth()
+	status := &Status{
+		Chain:     ob.Executor.GetChainName(),
+		Height:    curBlockLog.Height,
+		BlockTime: curBlockLog.BlockTime,
+		Executor:  health,
+	}
+	if health.Head > curBlockLog.Height {
+		status.Lag = health.Head - curBlockLog.Height
+	}
+	return status, nil
+}
+
+// Alert sends alerts to tg group if there is no new block fetched in a specific time or the executor is unhealthy
 func (ob *Observer) Alert() {
 	for {
 		curOtherChainBlockLog, err := ob.GetCurrentBlockLog()
@@ -264,6 +379,9 @@ func (ob *Observer) Alert() {
 				util.SendTelegramMessage(msg)
 			}
 		}
+		if health := ob.Executor.GetHealth(); !health.Healthy {
+			util.SendTelegramMessage(fmt.Sprintf("%s executor is unhealthy, err=%s", health.Chain, health.Err))
+		}
 
 		time.Sleep(common.ObserverAlertInterval)
 	}
@@ -0,0 +1,158 @@
+package observer
+
+import (
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+
+	"occ-swap-server/common"
+	"occ-swap-server/model"
+	"occ-swap-server/util"
+)
+
+// Rollback is called when the parent of the next block is not the block of curHeight. It walks back through the
+// saved blocks until one is still canonical, then deletes the blocks and the unconfirmed txs after it in one
+// transaction. It refuses to roll back confirmed txs, the observer stops until the operators step in. All fills are
+// paused by a reorg deeper than the confirmations of the chain, the confirmed events of other chains may be orphaned too
+func (ob *Observer) Rollback(curHeight int64) error {
+	ancestor, err := ob.findCommonAncestor(curHeight)
+	if err != nil {
+		msg := fmt.Sprintf("Urgent alert: reorg on %s from height %d, no common ancestor found: %s",
+			ob.Executor.GetChainName(), curHeight, err.Error())
+		util.Logger.Errorf(msg)
+		util.SendTelegramMessage(msg)
+		ob.pauseFills(msg)
+		return err
+	}
+	depth := curHeight - ancestor.Height
+
+	confirmed, err := ob.countConfirmedTxsAfter(ancestor.Height)
+	if err != nil {
+		return err
+	}
+	if confirmed > 0 {
+		msg := fmt.Sprintf("Urgent alert: reorg on %s of depth %d, common ancestor height %d, %d confirmed txs are orphaned, refuse to roll back",
+			ob.Executor.GetChainName(), depth, ancestor.Height, confirmed)
+		util.Logger.Errorf(msg)
+		util.SendTelegramMessage(msg)
+		ob.pauseFills(msg)
+		return fmt.Errorf("reorg of depth %d orphans %d confirmed txs", depth, confirmed)
+	}
+
+	if err := ob.DeleteBlockAndTxEvents(ancestor.Height); err != nil {
+		return err
+	}
+	msg := fmt.Sprintf("reorg on %s of depth %d, roll back to height %d, block hash %s",
+		ob.Executor.GetChainName(), depth, ancestor.Height, ancestor.BlockHash)
+	util.Logger.Infof(msg)
+	util.SendTelegramMessage(msg)
+	if depth > ob.ConfirmNum {
+		ob.pauseFills(fmt.Sprintf("reorg on %s of depth %d is deeper than the %d confirmations, roll back to height %d",
+			ob.Executor.GetChainName(), depth, ob.ConfirmNum, ancestor.Height))
+	}
+	return nil
+}
+
+// pauseFills pauses all fills when the observer can not follow a reorg, the swaps from the chain may be paid out for
+// orphaned deposits. The observers keep running, the pause is lifted by the admin
+func (ob *Observer) pauseFills(reason string) {
+	inserted, err := model.InsertPause(ob.DB, common.PauseScopeGlobal, "", reason, common.PausedByReorg)
+	if err != nil {
+		util.Logger.Errorf("pause fills error: %s", err.Error())
+		util.SendTelegramMessage(fmt.Sprintf("Urgent alert: pause fills error: %s", err.Error()))
+		return
+	}
+	if inserted {
+		util.SendTelegramMessage(fmt.Sprintf("Urgent alert: all fills are paused by %s on %s", common.PausedByReorg, ob.Executor.GetChainName()))
+	}
+}
+
+// findCommonAncestor returns the highest saved block which is still canonical, the blocks saved by a range scan are
+// sparse so the walk goes through the saved blocks rather than every height
+func (ob *Observer) findCommonAncestor(curHeight int64) (*model.BlockLog, error) {
+	blockLogs := make([]model.BlockLog, 0)
+	err := ob.DB.Where("chain = ? and height <= ?", ob.Executor.GetChainName(), curHeight).Order("height desc").Find(&blockLogs).Error
+	if err != nil {
+		return nil, err
+	}
+	for idx := range blockLogs {
+		blockHash, err := ob.Executor.GetBlockHash(blockLogs[idx].Height)
+		if err != nil {
+			return nil, err
+		}
+		if blockHash == blockLogs[idx].BlockHash {
+			return &blockLogs[idx], nil
+		}
+	}
+	return nil, fmt.Errorf("none of the %d saved blocks is canonical", len(blockLogs))
+}
+
+// countConfirmedTxsAfter counts the confirmed txs of the blocks after the given height
+func (ob *Observer) countConfirmedTxsAfter(height int64) (int64, error) {
+	var total int64
+	for _, table := range []interface{}{model.SwapStartTxLog{}, model.SwapFilledTxLog{}, model.SwapPairRegisterTxLog{}} {
+		var count int64
+		err := ob.DB.Model(table).Where("chain = ? and height > ? and status = ?",
+			ob.Executor.GetChainName(), height, model.TxStatusConfirmed).Count(&count).Error
+		if err != nil {
+			return 0, err
+		}
+		total += count
+	}
+	return total, nil
+}
+
+// DeleteBlockAndTxEvents deletes the blocks after the given height and their unconfirmed txs with the swaps, refunds
+// and swap pair state machines created from them
+func (ob *Observer) DeleteBlockAndTxEvents(height int64) error {
+	chainName := ob.Executor.GetChainName()
+
+	tx := ob.DB.Begin()
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("chain = ? and height > ?", chainName, height).Delete(model.BlockLog{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	orphanedStartTxs := tx.Model(model.SwapStartTxLog{}).Select("tx_hash").
+		Where("chain = ? and height > ? and status = ?", chainName, height, model.TxStatusInit).QueryExpr()
+	// the swaps are unique by their start event, which may be replayed at the same log index
+	if err := tx.Unscoped().Where("start_tx_hash in (?)", orphanedStartTxs).Delete(model.Swap{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	// refunds are only sent for confirmed deposits, the pending ones are dropped with the swap
+	if err := tx.Unscoped().Where("start_tx_hash in (?)", orphanedStartTxs).Delete(model.SwapRefund{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := deleteUnconfirmedTxs(tx, model.SwapStartTxLog{}, chainName, height); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := deleteUnconfirmedTxs(tx, model.SwapFilledTxLog{}, chainName, height); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	orphanedRegisterTxs := tx.Model(model.SwapPairRegisterTxLog{}).Select("tx_hash").
+		Where("chain = ? and height > ? and status = ?", chainName, height, model.TxStatusInit).QueryExpr()
+	if err := tx.Where("pair_register_tx_hash in (?)", orphanedRegisterTxs).Delete(model.SwapPairStateMachine{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := deleteUnconfirmedTxs(tx, model.SwapPairRegisterTxLog{}, chainName, height); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func deleteUnconfirmedTxs(tx *gorm.DB, table interface{}, chainName string, height int64) error {
+	return tx.Where("chain = ? and height > ? and status = ?", chainName, height, model.TxStatusInit).Delete(table).Error
+}
@@ -0,0 +1,143 @@
+package observer
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+
+	"occ-swap-server/executor"
+	"occ-swap-server/model"
+	"occ-swap-server/util"
+)
+
+// RescanReport lists the events discovered by a rescan, they are identified by tx hash and log index
+type RescanReport struct {
+	Chain string `json:"chain"`
+	From  int64  `json:"from"`
+	To    int64  `json:"to"`
+
+	SwapStarts        []string `json:"swap_starts"`
+	SwapFills         []string `json:"swap_fills"`
+	SwapPairRegisters []string `json:"swap_pair_registers"`
+}
+
+// Rescan runs the executor over the blocks [from, to] again and saves the events which are missing, the saved events
+// are left as they are. The blocks must be fetched by the observer already, the cursor of the observer is not moved
+func (ob *Observer) Rescan(from, to int64) (*RescanReport, error) {
+	if from > to {
+		return nil, fmt.Errorf("from %d is larger than to %d", from, to)
+	}
+	curBlockLog, err := ob.GetCurrentBlockLog()
+	if err != nil {
+		return nil, err
+	}
+	if to > curBlockLog.Height {
+		return nil, fmt.Errorf("to %d is beyond the block %d fetched by the observer", to, curBlockLog.Height)
+	}
+
+	report := &RescanReport{
+		Chain:             ob.Executor.GetChainName(),
+		From:              from,
+		To:                to,
+		SwapStarts:        make([]string, 0),
+		SwapFills:         make([]string, 0),
+		SwapPairRegisters: make([]string, 0),
+	}
+	fetchRange := ob.MaxFetchRange
+	for height := from; height <= to; {
+		end := height + fetchRange - 1
+		if end > to {
+			end = to
+		}
+		blockAndEventLogs, err := ob.Executor.GetRangeBlockAndTxEvents(height, end)
+		if errors.Is(err, executor.ErrRangeTooLarge) && end > height {
+			fetchRange = (end - height + 1) / 2
+			continue
+		}
+		if err != nil {
+			return report, fmt.Errorf("get blocks info error, height=[%d, %d], err=%s", height, end, err.Error())
+		}
+
+		writeDBErr := func() error {
+			tx := ob.DB.Begin()
+			if err := tx.Error; err != nil {
+				return err
+			}
+			for _, event := range blockAndEventLogs.Events {
+				if err := ob.upsertEvent(tx, event, report); err != nil {
+					tx.Rollback()
+					return err
+				}
+			}
+			return tx.Commit().Error
+		}()
+		if writeDBErr != nil {
+			return report, writeDBErr
+		}
+		util.Logger.Infof("rescan %s blocks [%d, %d]", report.Chain, height, end)
+		height = end + 1
+	}
+
+	// the confirmations of the new events are counted from the fetched block
+	if err := ob.UpdateSwapStartConfirmedNum(curBlockLog.Height); err != nil {
+		return report, err
+	}
+	if err := ob.UpdateSwapPairRegisterConfirmedNum(curBlockLog.Height); err != nil {
+		return report, err
+	}
+	if err := ob.UpdateSwapFilledConfirmedNum(curBlockLog.Height); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// upsertEvent saves the event unless it is saved already. An event saved before the log index was recorded has log
+// index 0, it is matched by its content and left as it is since the swaps started by it refer to log index 0
+func (ob *Observer) upsertEvent(tx *gorm.DB, event interface{}, report *RescanReport) error {
+	var table interface{}
+	var txHash, content string
+	var logIndex uint
+	var contentArgs []interface{}
+	var discovered *[]string
+	switch ev := event.(type) {
+	case *model.SwapStartTxLog:
+		table, txHash, logIndex, discovered = model.SwapStartTxLog{}, ev.TxHash, ev.LogIndex, &report.SwapStarts
+		content, contentArgs = "from_address = ? and amount = ? and to_chain_id = ?", []interface{}{ev.FromAddress, ev.Amount, ev.ToChainId}
+	case *model.SwapFilledTxLog:
+		table, txHash, logIndex, discovered = model.SwapFilledTxLog{}, ev.TxHash, ev.LogIndex, &report.SwapFills
+		content, contentArgs = "swap_id = ? and to_address = ? and amount = ?", []interface{}{ev.SwapId, ev.ToAddress, ev.Amount}
+	case *model.SwapPairRegisterTxLog:
+		table, txHash, logIndex, discovered = model.SwapPairRegisterTxLog{}, ev.TxHash, ev.LogIndex, &report.SwapPairRegisters
+		content, contentArgs = "erc20_addr = ? and bep20_addr = ?", []interface{}{ev.ERC20Addr, ev.BEP20Addr}
+	default:
+		return fmt.Errorf("unknown event %T", event)
+	}
+	chainName := ob.Executor.GetChainName()
+	eventId := fmt.Sprintf("%s#%d", txHash, logIndex)
+
+	var count int64
+	if err := tx.Model(table).Where("chain = ? and tx_hash = ? and log_index = ?", chainName, txHash, logIndex).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	if logIndex != 0 {
+		err := tx.Model(table).Where("chain = ? and tx_hash = ? and log_index = 0 and "+content,
+			append([]interface{}{chainName, txHash}, contentArgs...)...).Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+	}
+
+	if err := tx.Create(event).Error; err != nil {
+		return err
+	}
+	*discovered = append(*discovered, eventId)
+	return nil
+}
@@ -0,0 +1,537 @@
+package provider
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	ethcom "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"occ-swap-server/common"
+	"occ-swap-server/util"
+)
+
+// ErrNoQuorum is returned when not enough providers agree on a quorum read
+var ErrNoQuorum = errors.New("providers do not reach quorum")
+
+// ErrSendUncertain is returned when no provider answered a broadcast, any of them may have relayed the tx anyway
+var ErrSendUncertain = errors.New("broadcast result is uncertain")
+
+// the errors of the providers refusing a tx, from the most specific on. A refused nonce tells the sender more than
+// any other refusal
+var sendTxErrors = [][]string{
+	{"nonce too low", "nonce too high", "replacement transaction underpriced"},
+}
+
+// the errors of the providers which know the tx already, it is in their pool or mined
+var knownTxErrors = []string{"already known", "known transaction", "already imported"}
+
+// sendTxErrorRank returns how specific the error of a provider refusing a tx is, other refusals rank lowest
+func sendTxErrorRank(err error) int {
+	msg := strings.ToLower(err.Error())
+	for idx, patterns := range sendTxErrors {
+		for _, pattern := range patterns {
+			if strings.Contains(msg, pattern) {
+				return len(sendTxErrors) - idx
+			}
+		}
+	}
+	return 0
+}
+
+// isKnownTx tells whether the provider refused the tx because it knows the tx already
+func isKnownTx(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, pattern := range knownTxErrors {
+		if strings.Contains(msg, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// isRefused tells whether the provider answered the broadcast with an error, a transport error tells nothing about
+// the tx
+func isRefused(err error) bool {
+	var rpcErr rpc.Error
+	return errors.As(err, &rpcErr)
+}
+
+// broadcastResult returns the result of a broadcast from the errors of the providers: it succeeds if any of them
+// accepted the tx or knows it already, it is uncertain if none of them answered, and otherwise it fails with the most
+// specific refusal
+func broadcastResult(errs []error) error {
+	var sendErr, transportErr error
+	for _, err := range errs {
+		if err == nil || isKnownTx(err) {
+			return nil
+		}
+		if !isRefused(err) {
+			transportErr = err
+			continue
+		}
+		if sendErr == nil || sendTxErrorRank(err) > sendTxErrorRank(sendErr) {
+			sendErr = err
+		}
+	}
+	if sendErr == nil && transportErr != nil {
+		return fmt.Errorf("%w, last error: %s", ErrSendUncertain, transportErr.Error())
+	}
+	return sendErr
+}
+
+type endpoint struct {
+	url    string
+	client *ethclient.Client
+
+	healthy bool
+	head    int64
+	lastErr string
+}
+
+// Status is the health of a provider seen by the last health check
+type Status struct {
+	Url     string `json:"url"`
+	Healthy bool   `json:"healthy"`
+	Head    int64  `json:"head"`
+	LastErr string `json:"last_err,omitempty"`
+}
+
+// Pool is the client of a chain with several providers. Calls fail over the healthy providers, a provider is
+// unhealthy if it fails or its head lags behind the others, and quorum reads require several providers to agree
+type Pool struct {
+	mutex sync.RWMutex
+
+	chain      string
+	endpoints  []*endpoint
+	strategy   string
+	quorum     int
+	maxHeadLag int64
+
+	// the start of the round robin
+	next uint64
+}
+
+// Dial connects to the providers of the chain and starts the health check
+func Dial(chainCfg *util.ChainInfo) (*Pool, error) {
+	urls := append([]string{chainCfg.Provider}, chainCfg.Providers...)
+	endpoints := make([]*endpoint, 0, len(urls))
+	for _, url := range urls {
+		client, err := ethclient.Dial(url)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, &endpoint{url: url, client: client, healthy: true})
+	}
+
+	maxHeadLag := chainCfg.ProviderMaxHeadLag
+	if maxHeadLag == 0 {
+		maxHeadLag = common.ProviderDefaultMaxHeadLag
+	}
+	pool := &Pool{
+		chain:      chainCfg.Name,
+		endpoints:  endpoints,
+		strategy:   chainCfg.ProviderStrategy,
+		quorum:     chainCfg.ProviderQuorum,
+		maxHeadLag: maxHeadLag,
+	}
+	go pool.healthCheck()
+	return pool, nil
+}
+
+func (p *Pool) healthCheck() {
+	for {
+		p.checkHealth()
+		time.Sleep(common.ProviderHealthCheckInterval)
+	}
+}
+
+// checkHealth queries the head of every provider, a provider lagging more than maxHeadLag blocks behind the highest
+// head is unhealthy
+func (p *Pool) checkHealth() {
+	heads := make([]int64, len(p.endpoints))
+	errs := make([]error, len(p.endpoints))
+	var wg sync.WaitGroup
+	for idx := range p.endpoints {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			ctxWithTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			head, err := p.endpoints[idx].client.BlockNumber(ctxWithTimeout)
+			heads[idx], errs[idx] = int64(head), err
+		}(idx)
+	}
+	wg.Wait()
+
+	var maxHead int64
+	for idx := range heads {
+		if errs[idx] == nil && heads[idx] > maxHead {
+			maxHead = heads[idx]
+		}
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for idx, ep := range p.endpoints {
+		healthy := true
+		if errs[idx] != nil {
+			healthy = false
+			ep.lastErr = errs[idx].Error()
+		} else {
+			ep.head = heads[idx]
+			if maxHead-heads[idx] > p.maxHeadLag {
+				healthy = false
+				ep.lastErr = fmt.Sprintf("head %d lags behind %d", heads[idx], maxHead)
+			}
+		}
+		if healthy != ep.healthy {
+			if healthy {
+				util.Logger.Infof("provider %s of %s is healthy again", ep.url, p.chain)
+			} else {
+				util.Logger.Errorf("provider %s of %s is unhealthy: %s", ep.url, p.chain, ep.lastErr)
+				util.SendTelegramMessage(fmt.Sprintf("provider %s of %s is unhealthy: %s", ep.url, p.chain, ep.lastErr))
+			}
+		}
+		ep.healthy = healthy
+	}
+}
+
+// Status returns the health of the providers
+func (p *Pool) Status() []Status {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	statuses := make([]Status, 0, len(p.endpoints))
+	for _, ep := range p.endpoints {
+		statuses = append(statuses, Status{Url: ep.url, Healthy: ep.healthy, Head: ep.head, LastErr: ep.lastErr})
+	}
+	return statuses
+}
+
+// candidates returns the healthy providers in the order of the strategy, all providers are returned if none is
+// healthy so a wrong health view never halts the chain
+func (p *Pool) candidates() []*endpoint {
+	p.mutex.RLock()
+	healthy := make([]*endpoint, 0, len(p.endpoints))
+	for _, ep := range p.endpoints {
+		if ep.healthy {
+			healthy = append(healthy, ep)
+		}
+	}
+	p.mutex.RUnlock()
+	if len(healthy) == 0 {
+		healthy = append(healthy, p.endpoints...)
+	}
+
+	if p.strategy == common.ProviderStrategyRoundRobin && len(healthy) > 1 {
+		start := int(atomic.AddUint64(&p.next, 1) % uint64(len(healthy)))
+		rotated := make([]*endpoint, 0, len(healthy))
+		healthy = append(append(rotated, healthy[start:]...), healthy[:start]...)
+	}
+	return healthy
+}
+
+// call fails over the candidates until one succeeds, not found is an answer rather than a failure
+func (p *Pool) call(fn func(client *ethclient.Client) error) error {
+	var lastErr error
+	for _, ep := range p.candidates() {
+		err := fn(ep.client)
+		if err == nil || errors.Is(err, ethereum.NotFound) {
+			return err
+		}
+		util.Logger.Debugf("call provider %s of %s error, err=%s", ep.url, p.chain, err.Error())
+		lastErr = err
+	}
+	return lastErr
+}
+
+func (p *Pool) ChainID(ctx context.Context) (*big.Int, error) {
+	var result *big.Int
+	err := p.call(func(client *ethclient.Client) (err error) {
+		result, err = client.ChainID(ctx)
+		return
+	})
+	return result, err
+}
+
+func (p *Pool) BlockNumber(ctx context.Context) (uint64, error) {
+	var result uint64
+	err := p.call(func(client *ethclient.Client) (err error) {
+		result, err = client.BlockNumber(ctx)
+		return
+	})
+	return result, err
+}
+
+func (p *Pool) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	var result *types.Header
+	err := p.call(func(client *ethclient.Client) (err error) {
+		result, err = client.HeaderByNumber(ctx, number)
+		return
+	})
+	return result, err
+}
+
+func (p *Pool) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	var result *types.Block
+	err := p.call(func(client *ethclient.Client) (err error) {
+		result, err = client.BlockByNumber(ctx, number)
+		return
+	})
+	return result, err
+}
+
+func (p *Pool) TransactionByHash(ctx context.Context, hash ethcom.Hash) (*types.Transaction, bool, error) {
+	var result *types.Transaction
+	var isPending bool
+	err := p.call(func(client *ethclient.Client) (err error) {
+		result, isPending, err = client.TransactionByHash(ctx, hash)
+		return
+	})
+	return result, isPending, err
+}
+
+func (p *Pool) TransactionReceipt(ctx context.Context, txHash ethcom.Hash) (*types.Receipt, error) {
+	var result *types.Receipt
+	err := p.call(func(client *ethclient.Client) (err error) {
+		result, err = client.TransactionReceipt(ctx, txHash)
+		return
+	})
+	return result, err
+}
+
+func (p *Pool) BalanceAt(ctx context.Context, account ethcom.Address, blockNumber *big.Int) (*big.Int, error) {
+	var result *big.Int
+	err := p.call(func(client *ethclient.Client) (err error) {
+		result, err = client.BalanceAt(ctx, account, blockNumber)
+		return
+	})
+	return result, err
+}
+
+func (p *Pool) NonceAt(ctx context.Context, account ethcom.Address, blockNumber *big.Int) (uint64, error) {
+	var result uint64
+	err := p.call(func(client *ethclient.Client) (err error) {
+		result, err = client.NonceAt(ctx, account, blockNumber)
+		return
+	})
+	return result, err
+}
+
+func (p *Pool) PendingNonceAt(ctx context.Context, account ethcom.Address) (uint64, error) {
+	var result uint64
+	err := p.call(func(client *ethclient.Client) (err error) {
+		result, err = client.PendingNonceAt(ctx, account)
+		return
+	})
+	return result, err
+}
+
+func (p *Pool) CodeAt(ctx context.Context, contract ethcom.Address, blockNumber *big.Int) ([]byte, error) {
+	var result []byte
+	err := p.call(func(client *ethclient.Client) (err error) {
+		result, err = client.CodeAt(ctx, contract, blockNumber)
+		return
+	})
+	return result, err
+}
+
+func (p *Pool) PendingCodeAt(ctx context.Context, contract ethcom.Address) ([]byte, error) {
+	var result []byte
+	err := p.call(func(client *ethclient.Client) (err error) {
+		result, err = client.PendingCodeAt(ctx, contract)
+		return
+	})
+	return result, err
+}
+
+func (p *Pool) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	var result []byte
+	err := p.call(func(client *ethclient.Client) (err error) {
+		result, err = client.CallContract(ctx, msg, blockNumber)
+		return
+	})
+	return result, err
+}
+
+func (p *Pool) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	var result uint64
+	err := p.call(func(client *ethclient.Client) (err error) {
+		result, err = client.EstimateGas(ctx, msg)
+		return
+	})
+	return result, err
+}
+
+func (p *Pool) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	var result *big.Int
+	err := p.call(func(client *ethclient.Client) (err error) {
+		result, err = client.SuggestGasPrice(ctx)
+		return
+	})
+	return result, err
+}
+
+func (p *Pool) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	var result *big.Int
+	err := p.call(func(client *ethclient.Client) (err error) {
+		result, err = client.SuggestGasTipCap(ctx)
+		return
+	})
+	return result, err
+}
+
+func (p *Pool) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	var result *ethereum.FeeHistory
+	err := p.call(func(client *ethclient.Client) (err error) {
+		result, err = client.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
+		return
+	})
+	return result, err
+}
+
+func (p *Pool) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	var result []types.Log
+	err := p.call(func(client *ethclient.Client) (err error) {
+		result, err = client.FilterLogs(ctx, query)
+		return
+	})
+	return result, err
+}
+
+func (p *Pool) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	var result ethereum.Subscription
+	err := p.call(func(client *ethclient.Client) (err error) {
+		result, err = client.SubscribeFilterLogs(ctx, query, ch)
+		return
+	})
+	return result, err
+}
+
+// SendTransaction broadcasts the tx to all healthy providers, it succeeds if any of them accepts the tx or knows it
+// already. It returns ErrSendUncertain if none of them could be reached, the tx may be relayed anyway, and otherwise the
+// most specific refusal of the providers
+func (p *Pool) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	candidates := p.candidates()
+	errs := make([]error, len(candidates))
+	var wg sync.WaitGroup
+	for idx := range candidates {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			errs[idx] = candidates[idx].client.SendTransaction(ctx, tx)
+		}(idx)
+	}
+	wg.Wait()
+
+	for idx, err := range errs {
+		if err != nil {
+			util.Logger.Debugf("send tx %s to provider %s of %s error, err=%s", tx.Hash().String(), candidates[idx].url, p.chain, err.Error())
+		}
+	}
+	return broadcastResult(errs)
+}
+
+// quorumCall runs the call on all healthy providers and returns the result at least quorum of them agree on, the
+// results are compared by the key returned with them. The call fails over like the other calls without a quorum
+func (p *Pool) quorumCall(fn func(client *ethclient.Client) (interface{}, ethcom.Hash, error)) (interface{}, error) {
+	if p.quorum <= 1 {
+		var result interface{}
+		err := p.call(func(client *ethclient.Client) (err error) {
+			result, _, err = fn(client)
+			return
+		})
+		return result, err
+	}
+
+	candidates := p.candidates()
+	results := make([]interface{}, len(candidates))
+	keys := make([]ethcom.Hash, len(candidates))
+	errs := make([]error, len(candidates))
+	var wg sync.WaitGroup
+	for idx := range candidates {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			results[idx], keys[idx], errs[idx] = fn(candidates[idx].client)
+		}(idx)
+	}
+	wg.Wait()
+
+	votes := make(map[ethcom.Hash]int)
+	var lastErr error
+	for idx := range candidates {
+		if errs[idx] != nil {
+			lastErr = errs[idx]
+			continue
+		}
+		votes[keys[idx]]++
+		if votes[keys[idx]] >= p.quorum {
+			return results[idx], nil
+		}
+	}
+	if lastErr != nil {
+		return nil, fmt.Errorf("%w: %d of %d providers are required, err=%s", ErrNoQuorum, p.quorum, len(candidates), lastErr.Error())
+	}
+	return nil, fmt.Errorf("%w: %d of %d providers are required", ErrNoQuorum, p.quorum, len(candidates))
+}
+
+// QuorumHeaderByNumber returns the header provider_quorum providers agree on
+func (p *Pool) QuorumHeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	result, err := p.quorumCall(func(client *ethclient.Client) (interface{}, ethcom.Hash, error) {
+		header, err := client.HeaderByNumber(ctx, number)
+		if err != nil {
+			return nil, ethcom.Hash{}, err
+		}
+		return header, header.Hash(), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*types.Header), nil
+}
+
+// QuorumFilterLogs returns the logs provider_quorum providers agree on, logs are compared by their block hash, tx
+// hash, index and content
+func (p *Pool) QuorumFilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	result, err := p.quorumCall(func(client *ethclient.Client) (interface{}, ethcom.Hash, error) {
+		logs, err := client.FilterLogs(ctx, query)
+		if err != nil {
+			return nil, ethcom.Hash{}, err
+		}
+		hasher := crypto.NewKeccakState()
+		for _, log := range logs {
+			hasher.Write(log.BlockHash.Bytes())
+			hasher.Write(log.TxHash.Bytes())
+			index := make([]byte, 8)
+			binary.BigEndian.PutUint64(index, uint64(log.Index))
+			hasher.Write(index)
+			for _, topic := range log.Topics {
+				hasher.Write(topic.Bytes())
+			}
+			hasher.Write(crypto.Keccak256(log.Data))
+		}
+		return logs, ethcom.BytesToHash(hasher.Sum(nil)), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]types.Log), nil
+}
+
+// Quorum returns the number of providers quorum reads require, reads of one provider are taken if it is not larger
+// than 1
+func (p *Pool) Quorum() int {
+	return p.quorum
+}
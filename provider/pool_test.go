@@ -0,0 +1,83 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// rpcError is the error of a provider answering the call, like the json-rpc errors of the clients
+type rpcError struct {
+	msg string
+}
+
+func (err rpcError) Error() string  { return err.msg }
+func (err rpcError) ErrorCode() int { return -32000 }
+
+func TestBroadcastResult(t *testing.T) {
+	transportErr := errors.New("dial tcp 127.0.0.1:8545: connect: connection refused")
+	cases := []struct {
+		name      string
+		errs      []error
+		wantErr   string
+		uncertain bool
+	}{
+		{
+			name: "accepted by one provider",
+			errs: []error{transportErr, nil, rpcError{"nonce too low"}},
+		},
+		{
+			name: "known by one provider",
+			errs: []error{rpcError{"insufficient funds for gas * price + value"}, rpcError{"already known"}},
+		},
+		{
+			name: "known transaction of older clients",
+			errs: []error{rpcError{"known transaction: 0x01"}},
+		},
+		{
+			name: "known by a provider not answering in json",
+			errs: []error{transportErr, errors.New("transaction already imported")},
+		},
+		{
+			name:      "no provider answers",
+			errs:      []error{transportErr, context.DeadlineExceeded},
+			wantErr:   "deadline exceeded",
+			uncertain: true,
+		},
+		{
+			name:    "nonce refusal over other refusals",
+			errs:    []error{rpcError{"intrinsic gas too low"}, rpcError{"nonce too low"}, rpcError{"insufficient funds"}},
+			wantErr: "nonce too low",
+		},
+		{
+			name:    "underpriced replacement over transport errors",
+			errs:    []error{transportErr, rpcError{"replacement transaction underpriced"}, transportErr},
+			wantErr: "replacement transaction underpriced",
+		},
+		{
+			name:    "first refusal of the same rank",
+			errs:    []error{rpcError{"intrinsic gas too low"}, rpcError{"insufficient funds"}},
+			wantErr: "intrinsic gas too low",
+		},
+	}
+
+	for _, c := range cases {
+		err := broadcastResult(c.errs)
+		if c.wantErr == "" {
+			if err != nil {
+				t.Fatalf("%s: broadcast fails: %s", c.name, err.Error())
+			}
+			continue
+		}
+		if err == nil {
+			t.Fatalf("%s: broadcast succeeds, want error %q", c.name, c.wantErr)
+		}
+		if !strings.Contains(err.Error(), c.wantErr) {
+			t.Fatalf("%s: error = %q, want %q", c.name, err.Error(), c.wantErr)
+		}
+		if errors.Is(err, ErrSendUncertain) != c.uncertain {
+			t.Fatalf("%s: uncertain = %v, want %v", c.name, !c.uncertain, c.uncertain)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package screening
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"occ-swap-server/util"
+)
+
+// BlocklistScreener flags the addresses listed in a local file. Each line of the file is an address, optionally
+// followed by a comma and the reason it is listed for, blank lines and lines starting with # are skipped. The file is
+// reloaded on the first screening after it changes, so it can be updated without a restart
+type BlocklistScreener struct {
+	mutex sync.Mutex
+	file  string
+
+	modTime time.Time
+	size    int64
+	// key is the lower case address, value is the reason
+	addresses map[string]string
+}
+
+// NewBlocklistScreener loads the blocklist file
+func NewBlocklistScreener(file string) (*BlocklistScreener, error) {
+	screener := &BlocklistScreener{file: file}
+	if err := screener.reload(); err != nil {
+		return nil, err
+	}
+	return screener, nil
+}
+
+// reload reads the file again if it changed since it was loaded, the caller must hold the mutex unless the screener
+// is not shared yet
+func (s *BlocklistScreener) reload() error {
+	info, err := os.Stat(s.file)
+	if err != nil {
+		return err
+	}
+	if s.addresses != nil && info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return nil
+	}
+
+	file, err := os.Open(s.file)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	addresses := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.SplitN(line, ",", 2)
+		address := strings.ToLower(strings.TrimSpace(fields[0]))
+		if address == "" {
+			return fmt.Errorf("empty address at line %d of blocklist file %s", lineNum, s.file)
+		}
+		reason := "listed in blocklist"
+		if len(fields) == 2 && strings.TrimSpace(fields[1]) != "" {
+			reason = strings.TrimSpace(fields[1])
+		}
+		addresses[address] = reason
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if s.addresses != nil {
+		util.Logger.Infof("reload blocklist file %s, %d addresses", s.file, len(addresses))
+	}
+	s.addresses = addresses
+	s.modTime = info.ModTime()
+	s.size = info.Size()
+	return nil
+}
+
+func (s *BlocklistScreener) Screen(address string) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// a broken file must not let the listed addresses through
+	if err := s.reload(); err != nil {
+		return "", fmt.Errorf("reload blocklist file %s error: %s", s.file, err.Error())
+	}
+	return s.addresses[strings.ToLower(strings.TrimSpace(address))], nil
+}
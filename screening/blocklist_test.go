@@ -0,0 +1,80 @@
+package screening
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeBlocklist(t *testing.T, file, content string, modTime time.Time) {
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("write blocklist error: %s", err.Error())
+	}
+	// the reload is driven by the modification time, do not depend on the resolution of the file system
+	if err := os.Chtimes(file, modTime, modTime); err != nil {
+		t.Fatalf("touch blocklist error: %s", err.Error())
+	}
+}
+
+func screen(t *testing.T, screener Screener, address string) string {
+	reason, err := screener.Screen(address)
+	if err != nil {
+		t.Fatalf("screen %s error: %s", address, err.Error())
+	}
+	return reason
+}
+
+func TestBlocklistScreener(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "blocklist.csv")
+	writeBlocklist(t, file, "# sanctioned\n\n0xAbC,ofac\n 0xdef \n", time.Now().Add(-time.Hour))
+
+	screener, err := NewBlocklistScreener(file)
+	if err != nil {
+		t.Fatalf("load blocklist error: %s", err.Error())
+	}
+	if reason := screen(t, screener, "0xabc"); reason != "ofac" {
+		t.Fatalf("reason of 0xabc = %q, want %q", reason, "ofac")
+	}
+	if reason := screen(t, screener, "0xDEF"); reason == "" {
+		t.Fatalf("listed address 0xdef without a reason is cleared")
+	}
+	if reason := screen(t, screener, "0x123"); reason != "" {
+		t.Fatalf("unlisted address 0x123 is flagged for %q", reason)
+	}
+}
+
+func TestBlocklistScreenerReload(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "blocklist.csv")
+	loadTime := time.Now().Add(-time.Hour)
+	writeBlocklist(t, file, "0xabc,ofac\n", loadTime)
+
+	screener, err := NewBlocklistScreener(file)
+	if err != nil {
+		t.Fatalf("load blocklist error: %s", err.Error())
+	}
+	if reason := screen(t, screener, "0x123"); reason != "" {
+		t.Fatalf("unlisted address 0x123 is flagged for %q", reason)
+	}
+
+	// the same size and a new modification time
+	writeBlocklist(t, file, "0x123,scam\n", loadTime.Add(time.Minute))
+	if reason := screen(t, screener, "0x123"); reason != "scam" {
+		t.Fatalf("reason of 0x123 after reload = %q, want %q", reason, "scam")
+	}
+	if reason := screen(t, screener, "0xabc"); reason != "" {
+		t.Fatalf("address 0xabc removed from the blocklist is flagged for %q", reason)
+	}
+
+	// a broken file must not let the listed addresses through
+	writeBlocklist(t, file, "0x123,scam\n,no address\n", loadTime.Add(2*time.Minute))
+	if _, err := screener.Screen("0x123"); err == nil {
+		t.Fatalf("broken blocklist clears the address")
+	}
+	if err := os.Remove(file); err != nil {
+		t.Fatalf("remove blocklist error: %s", err.Error())
+	}
+	if _, err := screener.Screen("0x123"); err == nil {
+		t.Fatalf("missing blocklist clears the address")
+	}
+}
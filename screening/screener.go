@@ -0,0 +1,73 @@
+package screening
+
+import (
+	"fmt"
+	"sync"
+
+	"occ-swap-server/common"
+	"occ-swap-server/util"
+)
+
+// Screener checks an address against a sanctions list or a screening service
+type Screener interface {
+	// Screen returns the reason the address is flagged for, it is empty if the address is clear. An error means the
+	// address could not be screened, it is not clear
+	Screen(address string) (string, error)
+}
+
+// Factory creates a screener of the screening config
+type Factory func(cfg *util.ScreenerConfig) (Screener, error)
+
+var (
+	factoriesMutex sync.RWMutex
+	factories      = map[string]Factory{
+		common.ScreenerTypeBlocklist: func(cfg *util.ScreenerConfig) (Screener, error) {
+			return NewBlocklistScreener(cfg.BlocklistFile)
+		},
+		common.ScreenerTypeWebhook: func(cfg *util.ScreenerConfig) (Screener, error) {
+			return NewWebhookScreener(cfg.WebhookUrl, cfg.WebhookTimeout), nil
+		},
+	}
+)
+
+// RegisterScreener registers the factory of a screener type, it replaces the factory registered before
+func RegisterScreener(screenerType string, factory Factory) {
+	factoriesMutex.Lock()
+	defer factoriesMutex.Unlock()
+	factories[screenerType] = factory
+}
+
+// chainScreener flags an address if any of its screeners flags it
+type chainScreener []Screener
+
+func (screeners chainScreener) Screen(address string) (string, error) {
+	for _, screener := range screeners {
+		reason, err := screener.Screen(address)
+		if err != nil || reason != "" {
+			return reason, err
+		}
+	}
+	return "", nil
+}
+
+// NewScreener creates the screeners of the screening config, the returned screener flags an address if any of them
+// flags it and flags nothing if none is configured
+func NewScreener(cfg *util.ScreeningConfig) (Screener, error) {
+	screeners := make(chainScreener, 0, len(cfg.Screeners))
+	for idx := range cfg.Screeners {
+		screenerCfg := &cfg.Screeners[idx]
+
+		factoriesMutex.RLock()
+		factory, ok := factories[screenerCfg.Type]
+		factoriesMutex.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("unknown screener type of screener %d: %s", idx, screenerCfg.Type)
+		}
+		screener, err := factory(screenerCfg)
+		if err != nil {
+			return nil, err
+		}
+		screeners = append(screeners, screener)
+	}
+	return screeners, nil
+}
@@ -0,0 +1,71 @@
+package screening
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"occ-swap-server/common"
+)
+
+// webhookRequest is posted to the screening service for every address
+type webhookRequest struct {
+	Address string `json:"address"`
+}
+
+// webhookResponse is expected from the screening service, the reason is only read if the address is flagged
+type webhookResponse struct {
+	Flagged bool   `json:"flagged"`
+	Reason  string `json:"reason"`
+}
+
+// WebhookScreener asks an external screening service about the addresses
+type WebhookScreener struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookScreener creates the screener posting to the url, the default timeout is used if timeout is 0
+func NewWebhookScreener(url string, timeout int64) *WebhookScreener {
+	if timeout == 0 {
+		timeout = common.ScreenerDefaultWebhookTimeout
+	}
+	return &WebhookScreener{
+		url:    url,
+		client: &http.Client{Timeout: time.Duration(timeout) * time.Second},
+	}
+}
+
+func (s *WebhookScreener) Screen(address string) (string, error) {
+	payload, err := json.Marshal(webhookRequest{Address: address})
+	if err != nil {
+		return "", err
+	}
+	res, err := s.client.Post(s.url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("screening service responds status %d: %s", res.StatusCode, string(body))
+	}
+	var result webhookResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("parse response of screening service error: %s", err.Error())
+	}
+	if !result.Flagged {
+		return "", nil
+	}
+	if result.Reason == "" {
+		return "flagged by screening service", nil
+	}
+	return result.Reason, nil
+}
@@ -0,0 +1,124 @@
+package screening
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newStubService starts a local screening service flagging the addresses of flagged with their reasons
+func newStubService(t *testing.T, flagged map[string]string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req webhookRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		reason, ok := flagged[req.Address]
+		json.NewEncoder(w).Encode(webhookResponse{Flagged: ok, Reason: reason})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWebhookScreenerFlagged(t *testing.T) {
+	server := newStubService(t, map[string]string{
+		"0x01": "sanctioned",
+		"0x02": "",
+	})
+	screener := NewWebhookScreener(server.URL, 0)
+
+	reason, err := screener.Screen("0x01")
+	if err != nil {
+		t.Fatalf("screen error: %s", err.Error())
+	}
+	if reason != "sanctioned" {
+		t.Fatalf("reason = %q, want %q", reason, "sanctioned")
+	}
+
+	// a flagged address without a reason is still flagged
+	reason, err = screener.Screen("0x02")
+	if err != nil {
+		t.Fatalf("screen error: %s", err.Error())
+	}
+	if reason == "" {
+		t.Fatalf("address flagged without a reason is cleared")
+	}
+}
+
+func TestWebhookScreenerClear(t *testing.T) {
+	server := newStubService(t, map[string]string{"0x01": "sanctioned"})
+	screener := NewWebhookScreener(server.URL, 0)
+
+	reason, err := screener.Screen("0x03")
+	if err != nil {
+		t.Fatalf("screen error: %s", err.Error())
+	}
+	if reason != "" {
+		t.Fatalf("clear address is flagged for %q", reason)
+	}
+}
+
+func TestWebhookScreenerTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+		json.NewEncoder(w).Encode(webhookResponse{})
+	}))
+	defer server.Close()
+	defer close(release)
+
+	screener := NewWebhookScreener(server.URL, 1)
+	screener.client.Timeout = 100 * time.Millisecond
+
+	reason, err := screener.Screen("0x01")
+	if err == nil {
+		t.Fatalf("timed out screening clears the address, reason %q", reason)
+	}
+}
+
+func TestWebhookScreenerNon200(t *testing.T) {
+	for _, status := range []int{http.StatusInternalServerError, http.StatusTooManyRequests, http.StatusNotFound} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// the body says clear, the status must win
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(webhookResponse{})
+		}))
+		screener := NewWebhookScreener(server.URL, 0)
+
+		_, err := screener.Screen("0x01")
+		server.Close()
+		if err == nil {
+			t.Fatalf("status %d clears the address", status)
+		}
+	}
+}
+
+func TestWebhookScreenerMalformedResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("not json"))
+	}))
+	defer server.Close()
+	screener := NewWebhookScreener(server.URL, 0)
+
+	if _, err := screener.Screen("0x01"); err == nil {
+		t.Fatalf("malformed response clears the address")
+	}
+}
+
+func TestChainScreenerBlocksOnError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	screener := chainScreener{NewWebhookScreener(server.URL, 0)}
+
+	if _, err := screener.Screen("0x01"); err == nil {
+		t.Fatalf("error of a screener clears the address")
+	}
+}
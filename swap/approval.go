@@ -0,0 +1,189 @@
+package swap
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+
+	"occ-swap-server/common"
+	"occ-swap-server/model"
+	"occ-swap-server/util"
+)
+
+// PendingApproval is a swap waiting for approvals and the approvers who approved it so far
+type PendingApproval struct {
+	Swap              model.Swap `json:"swap"`
+	Approvers         []string   `json:"approvers"`
+	RequiredApprovals int        `json:"required_approvals"`
+}
+
+// aboveThreshold returns true if the swap pays out more than the approval threshold of its token
+func (engine *SwapEngine) aboveThreshold(swap *model.Swap, threshold *big.Int) bool {
+	amount, ok := big.NewInt(0).SetString(swap.Amount, 10)
+	// an invalid amount is rejected before it is filled, there is nothing to approve
+	return ok && amount.Cmp(threshold) > 0
+}
+
+func (engine *SwapEngine) getSwapApprovalHMAC(approval *model.SwapApproval) string {
+	material := fmt.Sprintf("%d#%s#%d#%s#%s#%s#%s",
+		approval.SwapID, approval.StartTxHash, approval.StartLogIndex, approval.SwapRecordHash, approval.Approver, approval.Decision, approval.Reason)
+	mac := hmac.New(sha256.New, []byte(engine.hmacCKey))
+	mac.Write([]byte(material))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (engine *SwapEngine) verifySwapApproval(approval *model.SwapApproval) bool {
+	return approval.RecordHash == engine.getSwapApprovalHMAC(approval)
+}
+
+// alertPendingApproval reports the swap waiting for approvals
+func (engine *SwapEngine) alertPendingApproval(chain *ChainIns, swap *model.Swap) {
+	msg := fmt.Sprintf("swap is waiting for %d approvals, swap ID %d, chain %s, start tx hash %s, sponsor %s, amount %s",
+		engine.config.AdminConfig.RequiredApprovals, swap.ID, chain.Name, swap.StartTxHash, swap.Sponsor, swap.Amount)
+	util.Logger.Infof("%s", msg)
+	util.SendTelegramMessage(msg)
+}
+
+// getApprovers returns the approvers who approved the swap in its current state, the decisions failing the hmac
+// verification are ignored
+func (engine *SwapEngine) getApprovers(tx *gorm.DB, swap *model.Swap) ([]string, error) {
+	approvals := make([]model.SwapApproval, 0)
+	err := tx.Where("swap_id = ? and swap_record_hash = ? and decision = ?", swap.ID, swap.RecordHash, SwapApprovalApprove).
+		Order("id asc").Find(&approvals).Error
+	if err != nil {
+		return nil, err
+	}
+	approvers := make([]string, 0, len(approvals))
+	for _, approval := range approvals {
+		if !engine.verifySwapApproval(&approval) {
+			util.Logger.Errorf("verify hmac of swap approval failed, swap ID %d, approver %s", approval.SwapID, approval.Approver)
+			continue
+		}
+		approvers = append(approvers, approval.Approver)
+	}
+	return approvers, nil
+}
+
+// GetPendingApprovals returns the swaps waiting for approvals
+func (engine *SwapEngine) GetPendingApprovals() ([]*PendingApproval, error) {
+	swaps := make([]model.Swap, 0)
+	if err := engine.db.Where("status = ?", SwapPendingApproval).Order("id asc").Find(&swaps).Error; err != nil {
+		return nil, err
+	}
+
+	pendings := make([]*PendingApproval, 0, len(swaps))
+	for _, swap := range swaps {
+		approvers, err := engine.getApprovers(engine.db, &swap)
+		if err != nil {
+			return nil, err
+		}
+		pendings = append(pendings, &PendingApproval{
+			Swap:              swap,
+			Approvers:         approvers,
+			RequiredApprovals: engine.config.AdminConfig.RequiredApprovals,
+		})
+	}
+	return pendings, nil
+}
+
+// ApproveSwaps records the approvals of the approver on the swaps of the list, a swap approved by enough distinct
+// approvers is queued again. It returns the ids of the approved swaps and the ids of the swaps which can not be
+// approved by the approver
+func (engine *SwapEngine) ApproveSwaps(approver string, swapIDList []uint) ([]uint, []uint, error) {
+	return engine.decideSwaps(approver, swapIDList, SwapApprovalApprove, "")
+}
+
+// RejectSwaps records the rejections of the approver on the swaps of the list, a rejected swap is never filled and
+// can only be refunded. It returns the ids of the rejected swaps and the ids of the swaps which can not be rejected
+func (engine *SwapEngine) RejectSwaps(approver string, swapIDList []uint, reason string) ([]uint, []uint, error) {
+	return engine.decideSwaps(approver, swapIDList, SwapApprovalReject, reason)
+}
+
+func (engine *SwapEngine) decideSwaps(approver string, swapIDList []uint, decision common.SwapApprovalDecision, reason string) ([]uint, []uint, error) {
+	decidedSwapList := make([]uint, 0, len(swapIDList))
+	refusedSwapList := make([]uint, 0, len(swapIDList))
+	alerts := make([]string, 0)
+	writeDBErr := func() error {
+		tx := engine.db.Begin()
+		if err := tx.Error; err != nil {
+			return err
+		}
+		// concurrent decisions on the same swap wait for each other, so the last approval always sees the others
+		query := tx.Where("id in (?)", swapIDList)
+		if engine.db.Dialect().GetName() == common.DBDialectMysql {
+			query = query.Set("gorm:query_option", "FOR UPDATE")
+		}
+		swaps := make([]model.Swap, 0)
+		if err := query.Find(&swaps).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+		if len(swaps) == 0 {
+			tx.Rollback()
+			return fmt.Errorf("no matched swap")
+		}
+
+		for _, swap := range swaps {
+			if !engine.verifySwap(&swap) || swap.Status != SwapPendingApproval {
+				refusedSwapList = append(refusedSwapList, swap.ID)
+				continue
+			}
+			var decided int64
+			tx.Model(model.SwapApproval{}).Where("swap_record_hash = ? and approver = ?", swap.RecordHash, approver).Count(&decided)
+			if decided > 0 {
+				refusedSwapList = append(refusedSwapList, swap.ID)
+				continue
+			}
+
+			approval := model.SwapApproval{
+				SwapID:         swap.ID,
+				StartTxHash:    swap.StartTxHash,
+				StartLogIndex:  swap.StartLogIndex,
+				SwapRecordHash: swap.RecordHash,
+				Approver:       approver,
+				Decision:       decision,
+				Reason:         reason,
+			}
+			approval.RecordHash = engine.getSwapApprovalHMAC(&approval)
+			if err := tx.Create(&approval).Error; err != nil {
+				tx.Rollback()
+				return err
+			}
+			util.Logger.Infof("approver %s decides to %s swap, swap ID %d, start tx hash %s", approver, decision, swap.ID, swap.StartTxHash)
+
+			if decision == SwapApprovalReject {
+				swap.Status = SwapQuoteRejected
+				swap.Log = fmt.Sprintf("rejected by approver %s: %s", approver, reason)
+				engine.updateSwap(tx, &swap)
+				alerts = append(alerts, fmt.Sprintf("swap is rejected by approver %s, swap ID %d, start tx hash %s: %s", approver, swap.ID, swap.StartTxHash, reason))
+			} else {
+				approvers, err := engine.getApprovers(tx, &swap)
+				if err != nil {
+					tx.Rollback()
+					return err
+				}
+				if len(approvers) >= engine.config.AdminConfig.RequiredApprovals {
+					swap.Status = SwapConfirmed
+					swap.Approved = true
+					swap.Log = fmt.Sprintf("approved by %s", strings.Join(approvers, ", "))
+					engine.updateSwap(tx, &swap)
+					alerts = append(alerts, fmt.Sprintf("swap is approved by %d approvers, swap ID %d, start tx hash %s", len(approvers), swap.ID, swap.StartTxHash))
+				}
+			}
+			decidedSwapList = append(decidedSwapList, swap.ID)
+		}
+		return tx.Commit().Error
+	}()
+	if writeDBErr == nil {
+		for _, alert := range alerts {
+			util.SendTelegramMessage(alert)
+		}
+	}
+	return decidedSwapList, refusedSwapList, writeDBErr
+}
@@ -0,0 +1,194 @@
+package swap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	ethcom "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"occ-swap-server/model"
+	"occ-swap-server/provider"
+	"occ-swap-server/util"
+)
+
+// getFillTxLineage returns the fill tx and all the txs it replaced, they share the same nonce and
+// at most one of them can be mined. The missing txs are part of it, they may still be mined
+func (engine *SwapEngine) getFillTxLineage(swapTx *model.SwapFillTx) ([]model.SwapFillTx, error) {
+	lineage := make([]model.SwapFillTx, 0)
+	err := engine.db.Where("start_swap_tx_hash = ? and chain = ? and nonce = ? and status in (?)",
+		swapTx.StartSwapTxHash, swapTx.Chain, swapTx.Nonce, []model.FillTxStatus{model.FillTxSent, model.FillTxReplaced, model.FillTxMissing}).
+		Order("id desc").Find(&lineage).Error
+	if err != nil {
+		return nil, err
+	}
+	return lineage, nil
+}
+
+// findMinedFillTx returns the fill tx of the lineage which is mined and its receipt, both are nil if none is mined
+func findMinedFillTx(chain *ChainIns, lineage []model.SwapFillTx) (*model.SwapFillTx, *types.Receipt, error) {
+	for idx := range lineage {
+		receipt, err := chain.Client.TransactionReceipt(context.Background(), ethcom.HexToHash(lineage[idx].FillSwapTxHash))
+		if errors.Is(err, ethereum.NotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		return &lineage[idx], receipt, nil
+	}
+	return nil, nil, nil
+}
+
+// bumpGasPrice raises the price to the minimum accepted by nodes to replace the pending fill tx, both the tip and
+// the fee cap must be higher than the ones of the pending tx by bumpPercent
+func bumpGasPrice(price *txGasPrice, swapTx *model.SwapFillTx, bumpPercent int64) *txGasPrice {
+	bump := func(amount string) *big.Int {
+		value := parseWei(amount)
+		if value == nil {
+			return big.NewInt(0)
+		}
+		value.Mul(value, big.NewInt(100+bumpPercent))
+		value.Div(value, big.NewInt(100))
+		return value
+	}
+	minFeeCap := bump(swapTx.GasPrice)
+	minTip := minFeeCap
+	if swapTx.GasTipCap != "" {
+		minTip = bump(swapTx.GasTipCap)
+	}
+
+	if !price.isDynamicFee() {
+		return &txGasPrice{GasPrice: bigMax(price.GasPrice, minFeeCap)}
+	}
+	bumped := &txGasPrice{
+		GasTipCap: bigMax(price.GasTipCap, minTip),
+		GasFeeCap: bigMax(price.GasFeeCap, minFeeCap),
+		BaseFee:   price.BaseFee,
+	}
+	bumped.GasFeeCap = bigMax(bumped.GasFeeCap, bumped.GasTipCap)
+	bumped.GasPrice = bumped.GasFeeCap
+	return bumped
+}
+
+// bumpSwapTx replaces the fill tx with a tx of the same nonce and a higher gas price if it is still pending
+// after bump_after_blocks blocks
+func (engine *SwapEngine) bumpSwapTx(chain *ChainIns, swapTx *model.SwapFillTx, height int64) {
+	gasCfg := chain.Config.GasConfig
+	if gasCfg.BumpAfterBlocks == 0 || height < swapTx.SentHeight+gasCfg.BumpAfterBlocks || swapTx.BumpCounter >= gasCfg.MaxBumps {
+		return
+	}
+
+	chain.mutex.Lock()
+	defer chain.mutex.Unlock()
+
+	// a tx of the lineage is mined if the nonce is taken, wait for its receipt
+	nonce, err := chain.Client.NonceAt(context.Background(), crypto.PubkeyToAddress(chain.PrivateKey.PublicKey), nil)
+	if err != nil {
+		util.Logger.Errorf("query nonce of %s error: %s", chain.Name, err.Error())
+		return
+	}
+	if nonce > swapTx.Nonce {
+		return
+	}
+
+	swap, err := engine.getSwapByStartTx(engine.db, swapTx.StartSwapTxHash, swapTx.StartLogIndex)
+	if err != nil {
+		util.Logger.Errorf("query swap error: %s, start hash %s", err.Error(), swapTx.StartSwapTxHash)
+		return
+	}
+	data, err := engine.encodeFillSwap(swap)
+	if err != nil {
+		util.Logger.Errorf("encode fill swap error: %s, start hash %s", err.Error(), swapTx.StartSwapTxHash)
+		return
+	}
+
+	price, err := suggestGasPrice(chain)
+	if err != nil {
+		util.Logger.Infof("skip bumping fill tx %s on %s: %s", swapTx.FillSwapTxHash, chain.Name, err.Error())
+		return
+	}
+	price = bumpGasPrice(price, swapTx, gasCfg.BumpPercent)
+	if ceiling := parseWei(gasCfg.GasPriceCeiling); ceiling != nil && price.expectedPrice().Cmp(ceiling) > 0 {
+		util.Logger.Infof("skip bumping fill tx %s on %s, bumped gas price %s is above the ceiling %s",
+			swapTx.FillSwapTxHash, chain.Name, price.expectedPrice().String(), ceiling.String())
+		return
+	}
+
+	signedTx, err := signTransaction(chain, price, chain.SwapAgent, big.NewInt(0), data, swapTx.Nonce)
+	if err != nil {
+		util.Logger.Errorf("build replacement of fill tx %s on %s error: %s", swapTx.FillSwapTxHash, chain.Name, err.Error())
+		return
+	}
+	replacement := &model.SwapFillTx{
+		Chain:           chain.Name,
+		Direction:       swapTx.Direction,
+		StartSwapTxHash: swapTx.StartSwapTxHash,
+		StartLogIndex:   swapTx.StartLogIndex,
+		SwapId:          getSwapId(swap.SwapId, swap.StartTxHash),
+		FillSwapTxHash:  signedTx.Hash().String(),
+		Nonce:           swapTx.Nonce,
+		GasPrice:        signedTx.GasPrice().String(),
+		GasTipCap:       gasTipCap(signedTx),
+		Status:          model.FillTxCreated,
+		SentHeight:      height,
+		ReplacedTxHash:  swapTx.FillSwapTxHash,
+		BumpCounter:     swapTx.BumpCounter + 1,
+	}
+	if err := engine.insertSwapTxToDB(replacement); err != nil {
+		util.Logger.Errorf("write db error: %s", err.Error())
+		return
+	}
+
+	sendErr := chain.Client.SendTransaction(context.Background(), signedTx)
+	if errors.Is(sendErr, provider.ErrSendUncertain) {
+		// the replacement may be relayed, it stays in the lineage so its receipt is looked up
+		util.Logger.Errorf("broadcast replacement of fill tx %s to %s is uncertain, track it as sent: %s", swapTx.FillSwapTxHash, chain.Name, sendErr.Error())
+		sendErr = nil
+	}
+	writeDBErr := func() error {
+		tx := engine.db.Begin()
+		if err := tx.Error; err != nil {
+			return err
+		}
+		if sendErr != nil {
+			// refused by the providers, the replacement can not be mined
+			tx.Model(model.SwapFillTx{}).Where("id = ?", replacement.ID).Updates(
+				map[string]interface{}{
+					"status":     model.FillTxFailed,
+					"updated_at": time.Now().Unix(),
+				})
+			return tx.Commit().Error
+		}
+
+		tx.Model(model.SwapFillTx{}).Where("id = ?", swapTx.ID).Updates(
+			map[string]interface{}{
+				"status":     model.FillTxReplaced,
+				"updated_at": time.Now().Unix(),
+			})
+		tx.Model(model.SwapFillTx{}).Where("id = ?", replacement.ID).Updates(
+			map[string]interface{}{
+				"status":     model.FillTxSent,
+				"updated_at": time.Now().Unix(),
+			})
+
+		swap.FillTxHash = replacement.FillSwapTxHash
+		engine.updateSwap(tx, swap)
+		return tx.Commit().Error
+	}()
+	if writeDBErr != nil {
+		util.Logger.Errorf("write db error: %s", writeDBErr.Error())
+		util.SendTelegramMessage(fmt.Sprintf("write db error: %s", writeDBErr.Error()))
+	}
+	if sendErr != nil {
+		util.Logger.Errorf("broadcast replacement of fill tx %s to %s error: %s", swapTx.FillSwapTxHash, chain.Name, sendErr.Error())
+		return
+	}
+	util.Logger.Infof("Replace fill tx %s on %s with %s/%s, gas price %s",
+		swapTx.FillSwapTxHash, chain.Name, chain.Config.ExplorerUrl, signedTx.Hash().String(), signedTx.GasPrice().String())
+}
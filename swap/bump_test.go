@@ -0,0 +1,177 @@
+package swap
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcom "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	sabi "occ-swap-server/abi"
+	"occ-swap-server/model"
+	"occ-swap-server/util"
+)
+
+func TestBumpGasPrice(t *testing.T) {
+	cases := []struct {
+		name string
+		// the price suggested by the gas strategy, the tip and fee cap are 0 for legacy prices
+		price *txGasPrice
+		// the gas price and tip of the pending tx, the tip is empty for legacy txs
+		pendingPrice, pendingTip string
+		wantPrice, wantTip, wantFeeCap string
+	}{
+		{
+			name:         "legacy tx bumped by the percent",
+			price:        &txGasPrice{GasPrice: big.NewInt(100)},
+			pendingPrice: "100",
+			wantPrice:    "110",
+		},
+		{
+			name:         "legacy price above the bump is kept",
+			price:        &txGasPrice{GasPrice: big.NewInt(200)},
+			pendingPrice: "100",
+			wantPrice:    "200",
+		},
+		{
+			name:         "dynamic fee tx bumps both the tip and the fee cap",
+			price:        &txGasPrice{GasTipCap: big.NewInt(2), GasFeeCap: big.NewInt(150), BaseFee: big.NewInt(70)},
+			pendingPrice: "200", pendingTip: "20",
+			wantPrice: "220", wantTip: "22", wantFeeCap: "220",
+		},
+		{
+			name:         "suggested dynamic fees above the bump are kept",
+			price:        &txGasPrice{GasTipCap: big.NewInt(30), GasFeeCap: big.NewInt(300), BaseFee: big.NewInt(130)},
+			pendingPrice: "200", pendingTip: "20",
+			wantPrice: "300", wantTip: "30", wantFeeCap: "300",
+		},
+		{
+			name:         "legacy tx replaced by a dynamic fee tx bumps the tip to the legacy price",
+			price:        &txGasPrice{GasTipCap: big.NewInt(2), GasFeeCap: big.NewInt(150), BaseFee: big.NewInt(70)},
+			pendingPrice: "100",
+			wantPrice:    "150", wantTip: "110", wantFeeCap: "150",
+		},
+		{
+			name:         "fee cap raised to the bumped tip",
+			price:        &txGasPrice{GasTipCap: big.NewInt(2), GasFeeCap: big.NewInt(10), BaseFee: big.NewInt(4)},
+			pendingPrice: "5", pendingTip: "50",
+			wantPrice: "55", wantTip: "55", wantFeeCap: "55",
+		},
+	}
+
+	for _, c := range cases {
+		swapTx := &model.SwapFillTx{GasPrice: c.pendingPrice, GasTipCap: c.pendingTip}
+		bumped := bumpGasPrice(c.price, swapTx, 10)
+		if got := bigString(bumped.GasPrice); got != c.wantPrice {
+			t.Fatalf("%s: gas price = %s, want %s", c.name, got, c.wantPrice)
+		}
+		if got := bigString(bumped.GasTipCap); got != c.wantTip {
+			t.Fatalf("%s: tip = %s, want %s", c.name, got, c.wantTip)
+		}
+		if got := bigString(bumped.GasFeeCap); got != c.wantFeeCap {
+			t.Fatalf("%s: fee cap = %s, want %s", c.name, got, c.wantFeeCap)
+		}
+	}
+}
+
+func TestBumpSwapTx(t *testing.T) {
+	cases := []struct {
+		name    string
+		sendErr error
+		// whether the replacement is in the lineage and tracked instead of the pending tx
+		wantReplaced bool
+	}{
+		{name: "accepted", wantReplaced: true},
+		{name: "known by the provider", sendErr: errors.New("already known"), wantReplaced: true},
+		{name: "provider unreachable", sendErr: errStubUnreachable, wantReplaced: true},
+		{name: "refused by the provider", sendErr: errors.New("replacement transaction underpriced"), wantReplaced: false},
+	}
+
+	agentABI, err := abi.JSON(strings.NewReader(sabi.SwapAgentABI))
+	if err != nil {
+		t.Fatalf("parse swap agent abi error: %s", err.Error())
+	}
+	for _, c := range cases {
+		sendErr := c.sendErr
+		client := newStubClient(t, map[string]rpcMethod{
+			// the pending tx is not mined
+			"eth_getTransactionCount": func([]json.RawMessage) (interface{}, error) { return hexutil.Uint64(3), nil },
+			"eth_gasPrice": func([]json.RawMessage) (interface{}, error) {
+				return (*hexutil.Big)(big.NewInt(100)), nil
+			},
+			"eth_estimateGas": func([]json.RawMessage) (interface{}, error) { return hexutil.Uint64(100000), nil },
+			"eth_sendRawTransaction": func([]json.RawMessage) (interface{}, error) {
+				if sendErr != nil {
+					return nil, sendErr
+				}
+				return ethcom.Hash{}, nil
+			},
+		})
+		privateKey, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatalf("generate key error: %s", err.Error())
+		}
+		chain := &ChainIns{
+			Name:       "stub",
+			ChainID:    big.NewInt(97),
+			Client:     client,
+			PrivateKey: privateKey,
+			SwapAgent:  ethcom.HexToAddress("0x02"),
+			Config: &util.ChainInfo{
+				Name:      "stub",
+				GasConfig: util.GasConfig{BumpAfterBlocks: 10, BumpPercent: 10, MaxBumps: 3},
+			},
+		}
+		engine := &SwapEngine{db: newTestDB(t), hmacCKey: "key", swapAgentABI: &agentABI}
+
+		swap := &model.Swap{StartTxHash: "0x01", Status: SwapSent, Sponsor: "0x03", Amount: "1000", ToChainId: "97", FillTxHash: "0x0a"}
+		if err := engine.insertSwap(engine.db, swap); err != nil {
+			t.Fatalf("%s: insert swap error: %s", c.name, err.Error())
+		}
+		pending := &model.SwapFillTx{Chain: "stub", StartSwapTxHash: "0x01", FillSwapTxHash: "0x0a", Nonce: 3,
+			GasPrice: "100", Status: model.FillTxSent, SentHeight: 100}
+		if err := engine.insertSwapTxToDB(pending); err != nil {
+			t.Fatalf("%s: insert fill tx error: %s", c.name, err.Error())
+		}
+
+		engine.bumpSwapTx(chain, pending, 110)
+
+		lineage, err := engine.getFillTxLineage(pending)
+		if err != nil {
+			t.Fatalf("%s: query lineage error: %s", c.name, err.Error())
+		}
+		tracked := make([]model.SwapFillTx, 0)
+		engine.db.Where("status = ?", model.FillTxSent).Find(&tracked)
+		if len(tracked) != 1 {
+			t.Fatalf("%s: %d fill txs are tracked, want 1", c.name, len(tracked))
+		}
+		swap, err = engine.getSwapByStartTx(engine.db, "0x01", 0)
+		if err != nil {
+			t.Fatalf("%s: query swap error: %s", c.name, err.Error())
+		}
+
+		if c.wantReplaced {
+			if len(lineage) != 2 {
+				t.Fatalf("%s: lineage has %d txs, want the pending tx and its replacement", c.name, len(lineage))
+			}
+			if tracked[0].ReplacedTxHash != pending.FillSwapTxHash || tracked[0].BumpCounter != 1 {
+				t.Fatalf("%s: tracked tx %s is not the replacement", c.name, tracked[0].FillSwapTxHash)
+			}
+			if swap.FillTxHash != tracked[0].FillSwapTxHash {
+				t.Fatalf("%s: fill tx hash of swap = %s, want the replacement %s", c.name, swap.FillTxHash, tracked[0].FillSwapTxHash)
+			}
+			continue
+		}
+		if len(lineage) != 1 || tracked[0].ID != pending.ID {
+			t.Fatalf("%s: refused replacement is in the lineage", c.name)
+		}
+		if swap.FillTxHash != pending.FillSwapTxHash {
+			t.Fatalf("%s: fill tx hash of swap = %s, want the pending tx %s", c.name, swap.FillTxHash, pending.FillSwapTxHash)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package swap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"occ-swap-server/common"
+	"occ-swap-server/util"
+)
+
+// NewSwapDirection returns the direction of swaps from fromChain to toChain, e.g. "bsc_eth"
+func NewSwapDirection(fromChain, toChain string) common.SwapDirection {
+	return common.SwapDirection(fmt.Sprintf("%s_%s", strings.ToLower(fromChain), strings.ToLower(toChain)))
+}
+
+// DirectionResolver resolves the direction of a swap from its source chain and destination chain id,
+// the directions are generated for every pair of configured chains
+type DirectionResolver struct {
+	// key is the chain id, value is the chain name
+	chainNames map[int64]string
+	// key is the source chain name, then the destination chain name
+	directions map[string]map[string]common.SwapDirection
+}
+
+func NewDirectionResolver(chains []util.ChainInfo) *DirectionResolver {
+	chainNames := make(map[int64]string)
+	directions := make(map[string]map[string]common.SwapDirection, len(chains))
+	for _, from := range chains {
+		for _, chainId := range from.ChainIds {
+			chainNames[chainId] = from.Name
+		}
+
+		directions[from.Name] = make(map[string]common.SwapDirection, len(chains))
+		for _, to := range chains {
+			if from.Name == to.Name {
+				continue
+			}
+			directions[from.Name][to.Name] = NewSwapDirection(from.Name, to.Name)
+		}
+	}
+
+	return &DirectionResolver{
+		chainNames: chainNames,
+		directions: directions,
+	}
+}
+
+// Has returns true if the direction is between two configured chains
+func (r *DirectionResolver) Has(direction common.SwapDirection) bool {
+	for _, toDirections := range r.directions {
+		for _, known := range toDirections {
+			if known == direction {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Resolve returns the direction of a swap started on fromChain to toChainId, unknown chains are rejected
+func (r *DirectionResolver) Resolve(fromChain, toChainId string) (common.SwapDirection, error) {
+	toDirections, ok := r.directions[fromChain]
+	if !ok {
+		return "", fmt.Errorf("unsupported source chain: %s", fromChain)
+	}
+
+	chainId, err := strconv.ParseInt(toChainId, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid destination chain id: %s", toChainId)
+	}
+	toChain, ok := r.chainNames[chainId]
+	if !ok {
+		return "", fmt.Errorf("unsupported destination chain id: %s", toChainId)
+	}
+	if toChain == fromChain {
+		return "", fmt.Errorf("destination chain id %s belongs to the source chain %s", toChainId, fromChain)
+	}
+
+	return toDirections[toChain], nil
+}
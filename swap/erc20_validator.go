@@ -0,0 +1,401 @@
+package swap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcom "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	sabi "occ-swap-server/abi"
+	"occ-swap-server/model"
+)
+
+const (
+	// the balance of the holder is compared across this many blocks to detect rebasing tokens
+	RebaseProbeBlocks = 1000
+	// the simulated transfer moves 1/TransferProbeDivisor of the balance of the holder
+	TransferProbeDivisor = 10
+)
+
+// errERC20ValidationFailed is wrapped when the token fails the ERC20 validation, the report is kept in the log of
+// the swap pair state machine
+var errERC20ValidationFailed = errors.New("erc20 validation failed")
+
+// transferProbeRecipient receives the simulated transfer, nobody holds its key
+var transferProbeRecipient = ethcom.BytesToAddress(crypto.Keccak256([]byte("erc20 transfer probe recipient")))
+
+var (
+	balanceOfSelector = crypto.Keccak256([]byte("balanceOf(address)"))[:4]
+	transferSelector  = crypto.Keccak256([]byte("transfer(address,uint256)"))[:4]
+)
+
+// ERC20ValidationReport is the result of the checks on a token to register, it is stored in the log of the swap pair
+// state machine whether the token passes or not
+type ERC20ValidationReport struct {
+	Token  string `json:"token"`
+	Holder string `json:"holder"`
+	Height int64  `json:"height"`
+
+	HasCode       bool   `json:"has_code"`
+	Name          string `json:"name"`
+	Symbol        string `json:"symbol"`
+	Decimals      uint8  `json:"decimals"`
+	TotalSupply   string `json:"total_supply"`
+	HolderBalance string `json:"holder_balance"`
+
+	TransferSimulated bool   `json:"transfer_simulated"`
+	TransferAmount    string `json:"transfer_amount,omitempty"`
+	SenderDelta       string `json:"sender_delta,omitempty"`
+	RecipientDelta    string `json:"recipient_delta,omitempty"`
+	FeeOnTransfer     bool   `json:"fee_on_transfer"`
+
+	RebaseChecked bool `json:"rebase_checked"`
+	Rebasing      bool `json:"rebasing"`
+
+	Failures []string `json:"failures,omitempty"`
+	Notes    []string `json:"notes,omitempty"`
+}
+
+func (report *ERC20ValidationReport) fail(format string, args ...interface{}) {
+	report.Failures = append(report.Failures, fmt.Sprintf(format, args...))
+}
+
+func (report *ERC20ValidationReport) note(format string, args ...interface{}) {
+	report.Notes = append(report.Notes, fmt.Sprintf(format, args...))
+}
+
+func (report *ERC20ValidationReport) Passed() bool {
+	return len(report.Failures) == 0
+}
+
+func (report *ERC20ValidationReport) String() string {
+	bz, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Sprintf("marshal erc20 validation report error: %s", err.Error())
+	}
+	return string(bz)
+}
+
+// Err returns nil if the token passes all checks
+func (report *ERC20ValidationReport) Err() error {
+	if report.Passed() {
+		return nil
+	}
+	return fmt.Errorf("%w: token %s, %v", errERC20ValidationFailed, report.Token, report.Failures)
+}
+
+// validateERC20Token checks the token has code, answers the ERC20 calls with metadata matching the register event,
+// and is neither a fee-on-transfer nor a rebasing token. The holder is the sponsor, its balance is used to simulate
+// a transfer with eth_call
+func validateERC20Token(chain *ChainIns, tokenAddr, holder ethcom.Address, height int64, sm *model.SwapPairStateMachine) *ERC20ValidationReport {
+	report := &ERC20ValidationReport{
+		Token:  tokenAddr.String(),
+		Holder: holder.String(),
+		Height: height,
+	}
+	block := big.NewInt(height)
+
+	code, err := chain.Client.CodeAt(context.Background(), tokenAddr, block)
+	if err != nil {
+		report.fail("query code error: %s", err.Error())
+		return report
+	}
+	if len(code) == 0 {
+		report.fail("token has no code")
+		return report
+	}
+	report.HasCode = true
+
+	token, err := sabi.NewERC20(tokenAddr, chain.Client)
+	if err != nil {
+		report.fail("bind token error: %s", err.Error())
+		return report
+	}
+	opts := &bind.CallOpts{BlockNumber: block, Context: context.Background()}
+	if report.Name, err = token.Name(opts); err != nil {
+		report.fail("query name error: %s", err.Error())
+	}
+	if report.Symbol, err = token.Symbol(opts); err != nil {
+		report.fail("query symbol error: %s", err.Error())
+	}
+	if report.Decimals, err = token.Decimals(opts); err != nil {
+		report.fail("query decimals error: %s", err.Error())
+	}
+	totalSupply, err := token.TotalSupply(opts)
+	if err != nil {
+		report.fail("query totalSupply error: %s", err.Error())
+	} else {
+		report.TotalSupply = totalSupply.String()
+	}
+	balance, err := token.BalanceOf(opts, holder)
+	if err != nil {
+		report.fail("query balanceOf error: %s", err.Error())
+	} else {
+		report.HolderBalance = balance.String()
+	}
+	if !report.Passed() {
+		return report
+	}
+	if report.Name != sm.Name || report.Symbol != sm.Symbol || int(report.Decimals) != sm.Decimals {
+		report.fail("token is %s(%s, %d decimals), the register event claims %s(%s, %d decimals)",
+			report.Name, report.Symbol, report.Decimals, sm.Name, sm.Symbol, sm.Decimals)
+	}
+	if balance.Cmp(totalSupply) > 0 {
+		report.fail("balance of the holder is more than the total supply")
+	}
+
+	simulateTransfer(chain, tokenAddr, holder, balance, block, report)
+	checkRebase(chain, token, tokenAddr, holder, balance, totalSupply, height, report)
+	return report
+}
+
+// simulateTransfer replaces the code of the holder with a probe which transfers a part of its balance and reads the
+// balances before and after, the token must move exactly the amount transferred
+func simulateTransfer(chain *ChainIns, tokenAddr, holder ethcom.Address, balance, block *big.Int, report *ERC20ValidationReport) {
+	amount := new(big.Int).Div(balance, big.NewInt(TransferProbeDivisor))
+	if amount.Sign() == 0 {
+		amount = new(big.Int).Set(balance)
+	}
+	if amount.Sign() == 0 {
+		report.note("holder has no balance, transfer is not simulated")
+		return
+	}
+	report.TransferAmount = amount.String()
+
+	rpcClient, err := rpc.DialContext(context.Background(), chain.Config.Provider)
+	if err != nil {
+		report.fail("dial provider error: %s", err.Error())
+		return
+	}
+	defer rpcClient.Close()
+
+	overrides := map[ethcom.Address]gethclient.OverrideAccount{
+		holder: {Code: transferProbeCode(tokenAddr, holder, transferProbeRecipient, amount)},
+	}
+	output, err := gethclient.New(rpcClient).CallContract(context.Background(), ethereum.CallMsg{To: &holder}, block, &overrides)
+	if err != nil {
+		report.fail("simulate transfer error: %s", err.Error())
+		return
+	}
+	if len(output) != transferProbeWords*32 {
+		report.fail("simulate transfer returns %d bytes", len(output))
+		return
+	}
+	word := func(slot int) *big.Int {
+		return new(big.Int).SetBytes(output[slot*32 : (slot+1)*32])
+	}
+	for _, slot := range []int{probeRecipientBeforeOk, probeSenderBeforeOk, probeRecipientAfterOk, probeSenderAfterOk} {
+		if word(slot).Sign() == 0 {
+			report.fail("balanceOf reverts in the simulated transfer")
+			return
+		}
+	}
+	if word(probeTransferOk).Sign() == 0 {
+		report.fail("simulated transfer reverts")
+		return
+	}
+	// tokens returning nothing from transfer are accepted
+	if word(probeTransferReturnSize).Sign() != 0 && word(probeTransferReturn).Sign() == 0 {
+		report.fail("simulated transfer returns false")
+		return
+	}
+	report.TransferSimulated = true
+
+	senderDelta := new(big.Int).Sub(word(probeSenderBefore), word(probeSenderAfter))
+	recipientDelta := new(big.Int).Sub(word(probeRecipientAfter), word(probeRecipientBefore))
+	report.SenderDelta = senderDelta.String()
+	report.RecipientDelta = recipientDelta.String()
+	if recipientDelta.Cmp(amount) < 0 {
+		report.FeeOnTransfer = true
+		report.fail("fee on transfer: recipient receives %s of %s", recipientDelta.String(), amount.String())
+	} else if recipientDelta.Cmp(amount) > 0 || senderDelta.Cmp(amount) != 0 {
+		report.fail("balances do not move by the amount transferred: sender %s, recipient %s, amount %s",
+			senderDelta.String(), recipientDelta.String(), amount.String())
+	}
+}
+
+// checkRebase compares the total supply and the balance of the holder with RebaseProbeBlocks blocks before, they
+// may only change with the Transfer events of mints, burns and the holder
+func checkRebase(chain *ChainIns, token *sabi.ERC20, tokenAddr, holder ethcom.Address, balance, totalSupply *big.Int,
+	height int64, report *ERC20ValidationReport) {
+	from := height - RebaseProbeBlocks
+	if from < 0 {
+		from = 0
+	}
+	code, err := chain.Client.CodeAt(context.Background(), tokenAddr, big.NewInt(from))
+	if err != nil || len(code) == 0 {
+		report.note("token is deployed within %d blocks, rebase is not checked", RebaseProbeBlocks)
+		return
+	}
+
+	opts := &bind.CallOpts{BlockNumber: big.NewInt(from), Context: context.Background()}
+	pastSupply, err := token.TotalSupply(opts)
+	if err != nil {
+		report.note("query past totalSupply error, rebase is not checked: %s", err.Error())
+		return
+	}
+	pastBalance, err := token.BalanceOf(opts, holder)
+	if err != nil {
+		report.note("query past balanceOf error, rebase is not checked: %s", err.Error())
+		return
+	}
+
+	end := uint64(height)
+	filterOpts := &bind.FilterOpts{Start: uint64(from) + 1, End: &end, Context: context.Background()}
+	zero := []ethcom.Address{{}}
+	supplyMoved := false
+	if pastSupply.Cmp(totalSupply) != 0 {
+		if supplyMoved, err = hasTransfer(token, filterOpts, zero, nil); err == nil && !supplyMoved {
+			supplyMoved, err = hasTransfer(token, filterOpts, nil, zero)
+		}
+		if err != nil {
+			report.note("filter mint and burn events error, rebase is not checked: %s", err.Error())
+			return
+		}
+	}
+	balanceMoved := false
+	if pastBalance.Cmp(balance) != 0 {
+		holders := []ethcom.Address{holder}
+		if balanceMoved, err = hasTransfer(token, filterOpts, holders, nil); err == nil && !balanceMoved {
+			balanceMoved, err = hasTransfer(token, filterOpts, nil, holders)
+		}
+		if err != nil {
+			report.note("filter holder transfer events error, rebase is not checked: %s", err.Error())
+			return
+		}
+	}
+	report.RebaseChecked = true
+
+	if pastSupply.Cmp(totalSupply) != 0 && !supplyMoved {
+		report.Rebasing = true
+		report.fail("total supply changes from %s to %s without mint or burn", pastSupply.String(), totalSupply.String())
+	}
+	if pastBalance.Cmp(balance) != 0 && !balanceMoved {
+		report.Rebasing = true
+		report.fail("balance of the holder changes from %s to %s without transfer", pastBalance.String(), balance.String())
+	}
+}
+
+func hasTransfer(token *sabi.ERC20, opts *bind.FilterOpts, from, to []ethcom.Address) (bool, error) {
+	iter, err := token.FilterTransfer(opts, from, to)
+	if err != nil {
+		return false, err
+	}
+	defer iter.Close()
+	found := iter.Next()
+	return found, iter.Error()
+}
+
+// the words returned by the transfer probe
+const (
+	probeRecipientBefore = iota
+	probeRecipientBeforeOk
+	probeSenderBefore
+	probeSenderBeforeOk
+	probeTransferReturn
+	probeTransferOk
+	probeTransferReturnSize
+	probeRecipientAfter
+	probeRecipientAfterOk
+	probeSenderAfter
+	probeSenderAfterOk
+
+	transferProbeWords
+)
+
+const (
+	opMstore         = 0x52
+	opGas            = 0x5a
+	opPush1          = 0x60
+	opCall           = 0xf1
+	opReturn         = 0xf3
+	opReturnDataSize = 0x3d
+
+	probeOutputOffset = 0x80
+)
+
+// evmAssembler emits straight-line EVM code, the probe has no branch so no jump table is needed
+type evmAssembler struct {
+	code []byte
+}
+
+func (asm *evmAssembler) push(value []byte) {
+	value = new(big.Int).SetBytes(value).Bytes()
+	if len(value) == 0 {
+		value = []byte{0}
+	}
+	asm.code = append(asm.code, byte(opPush1+len(value)-1))
+	asm.code = append(asm.code, value...)
+}
+
+func (asm *evmAssembler) pushUint(value uint64) {
+	asm.push(new(big.Int).SetUint64(value).Bytes())
+}
+
+func (asm *evmAssembler) op(op byte) {
+	asm.code = append(asm.code, op)
+}
+
+// mstore writes a 32 bytes word to memory
+func (asm *evmAssembler) mstore(offset uint64, word []byte) {
+	asm.push(word)
+	asm.pushUint(offset)
+	asm.op(opMstore)
+}
+
+// storeTop writes the top of the stack to the output word
+func (asm *evmAssembler) storeTop(slot int) {
+	asm.pushUint(probeOutputOffset + uint64(slot)*32)
+	asm.op(opMstore)
+}
+
+// call calls the contract with the calldata at the start of memory, the first word returned and the success flag
+// are written to the output words
+func (asm *evmAssembler) call(contract ethcom.Address, argsSize uint64, retSlot, okSlot int) {
+	asm.pushUint(32)
+	asm.pushUint(probeOutputOffset + uint64(retSlot)*32)
+	asm.pushUint(argsSize)
+	asm.pushUint(0)
+	asm.pushUint(0)
+	asm.push(contract.Bytes())
+	asm.op(opGas)
+	asm.op(opCall)
+	asm.storeTop(okSlot)
+}
+
+func (asm *evmAssembler) balanceOf(token, account ethcom.Address, retSlot, okSlot int) {
+	asm.mstore(0, ethcom.RightPadBytes(balanceOfSelector, 32))
+	asm.mstore(4, ethcom.LeftPadBytes(account.Bytes(), 32))
+	asm.call(token, 36, retSlot, okSlot)
+}
+
+// transferProbeCode returns the code which reads the balances of the recipient and the sender, transfers the amount
+// to the recipient and reads the balances again
+func transferProbeCode(token, sender, recipient ethcom.Address, amount *big.Int) []byte {
+	asm := &evmAssembler{}
+	asm.balanceOf(token, recipient, probeRecipientBefore, probeRecipientBeforeOk)
+	asm.balanceOf(token, sender, probeSenderBefore, probeSenderBeforeOk)
+
+	asm.mstore(0, ethcom.RightPadBytes(transferSelector, 32))
+	asm.mstore(4, ethcom.LeftPadBytes(recipient.Bytes(), 32))
+	asm.mstore(36, ethcom.LeftPadBytes(amount.Bytes(), 32))
+	asm.call(token, 68, probeTransferReturn, probeTransferOk)
+	asm.op(opReturnDataSize)
+	asm.storeTop(probeTransferReturnSize)
+
+	asm.balanceOf(token, recipient, probeRecipientAfter, probeRecipientAfterOk)
+	asm.balanceOf(token, sender, probeSenderAfter, probeSenderAfterOk)
+
+	asm.pushUint(transferProbeWords * 32)
+	asm.pushUint(probeOutputOffset)
+	asm.op(opReturn)
+	return asm.code
+}
@@ -0,0 +1,216 @@
+package swap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	ethcom "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"occ-swap-server/common"
+	"occ-swap-server/util"
+)
+
+// errGasPriceAboveCeiling is returned when the gas price of a chain is above its ceiling,
+// the swap is deferred instead of being marked as failed
+var errGasPriceAboveCeiling = errors.New("gas price is above the ceiling")
+
+// txGasPrice is the price a tx is sent with, GasTipCap and GasFeeCap are nil for legacy txs
+type txGasPrice struct {
+	GasPrice  *big.Int
+	GasTipCap *big.Int
+	GasFeeCap *big.Int
+	// BaseFee is the base fee of the next block when the price is decided
+	BaseFee *big.Int
+}
+
+func (p *txGasPrice) isDynamicFee() bool {
+	return p.GasFeeCap != nil
+}
+
+// expectedPrice is the price per gas the tx is expected to pay if it is mined in the next block
+func (p *txGasPrice) expectedPrice() *big.Int {
+	if !p.isDynamicFee() {
+		return p.GasPrice
+	}
+	return bigMin(big.NewInt(0).Add(p.BaseFee, p.GasTipCap), p.GasFeeCap)
+}
+
+func (p *txGasPrice) newTx(chainId *big.Int, nonce uint64, to ethcom.Address, value *big.Int, gasLimit uint64, data []byte) *types.Transaction {
+	if !p.isDynamicFee() {
+		return types.NewTransaction(nonce, to, value, gasLimit, p.GasPrice, data)
+	}
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainId,
+		Nonce:     nonce,
+		GasTipCap: p.GasTipCap,
+		GasFeeCap: p.GasFeeCap,
+		Gas:       gasLimit,
+		To:        &to,
+		Value:     value,
+		Data:      data,
+	})
+}
+
+// suggestGasPrice prices the next tx of the chain with its gas strategy, errGasPriceAboveCeiling is returned
+// if the expected price is above the configured ceiling
+func suggestGasPrice(chain *ChainIns) (*txGasPrice, error) {
+	gasCfg := chain.Config.GasConfig
+
+	var price *txGasPrice
+	var err error
+	switch gasCfg.Strategy {
+	case common.GasStrategyFixed:
+		price = &txGasPrice{GasPrice: parseWei(gasCfg.FixedGasPrice)}
+	case common.GasStrategyEIP1559:
+		price, err = suggestEIP1559GasPrice(chain)
+	case common.GasStrategyFeeHistory:
+		price, err = suggestFeeHistoryGasPrice(chain)
+	default:
+		var gasPrice *big.Int
+		gasPrice, err = chain.Client.SuggestGasPrice(context.Background())
+		price = &txGasPrice{GasPrice: gasPrice}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if price.isDynamicFee() {
+		if maxTip := parseWei(gasCfg.MaxPriorityFeePerGas); maxTip != nil {
+			price.GasTipCap = bigMin(price.GasTipCap, maxTip)
+		}
+		if maxFee := parseWei(gasCfg.MaxFeePerGas); maxFee != nil {
+			price.GasFeeCap = bigMin(price.GasFeeCap, maxFee)
+		}
+	}
+
+	if ceiling := parseWei(gasCfg.GasPriceCeiling); ceiling != nil {
+		if price.expectedPrice().Cmp(ceiling) > 0 {
+			return nil, fmt.Errorf("%w, chain %s, expected gas price %s, ceiling %s",
+				errGasPriceAboveCeiling, chain.Name, price.expectedPrice().String(), ceiling.String())
+		}
+		// never pay more than the ceiling even if the base fee rises before the tx is mined
+		if price.isDynamicFee() {
+			price.GasFeeCap = bigMin(price.GasFeeCap, ceiling)
+		}
+	}
+
+	if price.isDynamicFee() {
+		price.GasTipCap = bigMin(price.GasTipCap, price.GasFeeCap)
+		price.GasPrice = price.GasFeeCap
+	}
+	return price, nil
+}
+
+func suggestEIP1559GasPrice(chain *ChainIns) (*txGasPrice, error) {
+	header, err := chain.Client.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if header.BaseFee == nil {
+		return nil, fmt.Errorf("chain %s does not support eip1559", chain.Name)
+	}
+	tip, err := chain.Client.SuggestGasTipCap(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return newDynamicFeeGasPrice(header.BaseFee, tip), nil
+}
+
+func suggestFeeHistoryGasPrice(chain *ChainIns) (*txGasPrice, error) {
+	gasCfg := chain.Config.GasConfig
+	history, err := chain.Client.FeeHistory(context.Background(), gasCfg.FeeHistoryBlocks, nil, []float64{gasCfg.FeeHistoryPercentile})
+	if err != nil {
+		return nil, err
+	}
+	// the last base fee of the history is the base fee of the next block
+	if len(history.BaseFee) == 0 || history.BaseFee[len(history.BaseFee)-1] == nil {
+		return nil, fmt.Errorf("chain %s does not support eip1559", chain.Name)
+	}
+	baseFee := history.BaseFee[len(history.BaseFee)-1]
+
+	tip := big.NewInt(0)
+	blocks := int64(0)
+	for _, rewards := range history.Reward {
+		if len(rewards) == 0 || rewards[0] == nil {
+			continue
+		}
+		tip.Add(tip, rewards[0])
+		blocks++
+	}
+	if blocks == 0 {
+		return nil, fmt.Errorf("empty fee history of chain %s", chain.Name)
+	}
+	tip.Div(tip, big.NewInt(blocks))
+
+	return newDynamicFeeGasPrice(baseFee, tip), nil
+}
+
+// newDynamicFeeGasPrice leaves room for the base fee to double before the tx is mined
+func newDynamicFeeGasPrice(baseFee, tip *big.Int) *txGasPrice {
+	feeCap := big.NewInt(0).Mul(baseFee, big.NewInt(2))
+	feeCap.Add(feeCap, tip)
+	return &txGasPrice{
+		GasTipCap: tip,
+		GasFeeCap: feeCap,
+		BaseFee:   baseFee,
+	}
+}
+
+// effectiveGasPrice is the price per gas a mined tx paid, baseFee is the base fee of its block and nil before london
+func effectiveGasPrice(tx *types.Transaction, baseFee *big.Int) *big.Int {
+	if tx.Type() == types.LegacyTxType || baseFee == nil {
+		return tx.GasPrice()
+	}
+	return bigMin(big.NewInt(0).Add(baseFee, tx.GasTipCap()), tx.GasFeeCap())
+}
+
+// getFillTxFee returns the base fee of the block the receipt is in and the fee consumed by the tx
+func getFillTxFee(chain *ChainIns, receipt *types.Receipt) (*big.Int, *big.Int, error) {
+	tx, _, err := chain.Client.TransactionByHash(context.Background(), receipt.TxHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	header, err := chain.Client.HeaderByNumber(context.Background(), receipt.BlockNumber)
+	if err != nil {
+		return nil, nil, err
+	}
+	fee := big.NewInt(0).Mul(effectiveGasPrice(tx, header.BaseFee), big.NewInt(int64(receipt.GasUsed)))
+	return header.BaseFee, fee, nil
+}
+
+// gasTipCap returns the max priority fee per gas of dynamic fee txs, it is empty for legacy txs
+func gasTipCap(tx *types.Transaction) string {
+	if tx.Type() == types.LegacyTxType {
+		return ""
+	}
+	return tx.GasTipCap().String()
+}
+
+func parseWei(amount string) *big.Int {
+	if amount == "" {
+		return nil
+	}
+	value, ok := big.NewInt(0).SetString(amount, 10)
+	if !ok {
+		util.Logger.Errorf("invalid wei amount: %s", amount)
+		return nil
+	}
+	return value
+}
+
+func bigMin(x, y *big.Int) *big.Int {
+	if x.Cmp(y) > 0 {
+		return y
+	}
+	return x
+}
+
+func bigMax(x, y *big.Int) *big.Int {
+	if x.Cmp(y) < 0 {
+		return y
+	}
+	return x
+}
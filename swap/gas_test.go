@@ -0,0 +1,180 @@
+package swap
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	ethcom "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"occ-swap-server/common"
+	"occ-swap-server/util"
+)
+
+// newGasStubClient starts a local provider with the gas price, the base fee and tip of the next block and a fee
+// history of the base fees and rewards. The base fee is nil before london
+func newGasStubClient(t *testing.T, gasPrice, baseFee, tip int64, historyBaseFees []int64, historyRewards []int64) *ChainIns {
+	methods := map[string]rpcMethod{
+		"eth_gasPrice": func([]json.RawMessage) (interface{}, error) {
+			return (*hexutil.Big)(big.NewInt(gasPrice)), nil
+		},
+		"eth_maxPriorityFeePerGas": func([]json.RawMessage) (interface{}, error) {
+			return (*hexutil.Big)(big.NewInt(tip)), nil
+		},
+		"eth_getBlockByNumber": func([]json.RawMessage) (interface{}, error) {
+			header := &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(0)}
+			if baseFee > 0 {
+				header.BaseFee = big.NewInt(baseFee)
+			}
+			return header, nil
+		},
+		"eth_feeHistory": func([]json.RawMessage) (interface{}, error) {
+			history := map[string]interface{}{"oldestBlock": (*hexutil.Big)(big.NewInt(1))}
+			baseFees := make([]*hexutil.Big, 0, len(historyBaseFees))
+			for _, fee := range historyBaseFees {
+				baseFees = append(baseFees, (*hexutil.Big)(big.NewInt(fee)))
+			}
+			rewards := make([][]*hexutil.Big, 0, len(historyRewards))
+			ratios := make([]float64, 0, len(historyRewards))
+			for _, reward := range historyRewards {
+				rewards = append(rewards, []*hexutil.Big{(*hexutil.Big)(big.NewInt(reward))})
+				ratios = append(ratios, 0.5)
+			}
+			history["baseFeePerGas"] = baseFees
+			history["reward"] = rewards
+			history["gasUsedRatio"] = ratios
+			return history, nil
+		},
+	}
+	return &ChainIns{Name: "stub", Client: newStubClient(t, methods)}
+}
+
+func bigString(value *big.Int) string {
+	if value == nil {
+		return ""
+	}
+	return value.String()
+}
+
+func TestSuggestGasPrice(t *testing.T) {
+	cases := []struct {
+		name    string
+		gasCfg  util.GasConfig
+		baseFee int64
+		// the expected price, the tip and fee cap are empty for legacy txs
+		wantPrice, wantTip, wantFeeCap string
+		wantCeilingErr                 bool
+	}{
+		{
+			name:      "legacy",
+			gasCfg:    util.GasConfig{},
+			wantPrice: "50",
+		},
+		{
+			name:           "legacy above the ceiling",
+			gasCfg:         util.GasConfig{Strategy: common.GasStrategyLegacy, GasPriceCeiling: "49"},
+			wantCeilingErr: true,
+		},
+		{
+			name:      "fixed",
+			gasCfg:    util.GasConfig{Strategy: common.GasStrategyFixed, FixedGasPrice: "7"},
+			wantPrice: "7",
+		},
+		{
+			name:      "eip1559 leaves room for the base fee to double",
+			gasCfg:    util.GasConfig{Strategy: common.GasStrategyEIP1559},
+			baseFee:   100,
+			wantPrice: "202", wantTip: "2", wantFeeCap: "202",
+		},
+		{
+			name:      "eip1559 capped by the max fees",
+			gasCfg:    util.GasConfig{Strategy: common.GasStrategyEIP1559, MaxFeePerGas: "150", MaxPriorityFeePerGas: "1"},
+			baseFee:   100,
+			wantPrice: "150", wantTip: "1", wantFeeCap: "150",
+		},
+		{
+			name:      "eip1559 fee cap clipped to the ceiling",
+			gasCfg:    util.GasConfig{Strategy: common.GasStrategyEIP1559, GasPriceCeiling: "120"},
+			baseFee:   100,
+			wantPrice: "120", wantTip: "2", wantFeeCap: "120",
+		},
+		{
+			name:           "eip1559 expected price above the ceiling",
+			gasCfg:         util.GasConfig{Strategy: common.GasStrategyEIP1559, GasPriceCeiling: "101"},
+			baseFee:        100,
+			wantCeilingErr: true,
+		},
+		{
+			name:      "eip1559 tip never above the fee cap",
+			gasCfg:    util.GasConfig{Strategy: common.GasStrategyEIP1559, MaxFeePerGas: "1"},
+			baseFee:   100,
+			wantPrice: "1", wantTip: "1", wantFeeCap: "1",
+		},
+		{
+			name:      "fee history averages the rewards over the last base fee",
+			gasCfg:    util.GasConfig{Strategy: common.GasStrategyFeeHistory, FeeHistoryBlocks: 2, FeeHistoryPercentile: 50},
+			wantPrice: "204", wantTip: "4", wantFeeCap: "204",
+		},
+	}
+
+	for _, c := range cases {
+		chain := newGasStubClient(t, 50, c.baseFee, 2, []int64{90, 100}, []int64{3, 5})
+		chain.Config = &util.ChainInfo{Name: "stub", GasConfig: c.gasCfg}
+
+		price, err := suggestGasPrice(chain)
+		if c.wantCeilingErr {
+			if !errors.Is(err, errGasPriceAboveCeiling) {
+				t.Fatalf("%s: error = %v, want %v", c.name, err, errGasPriceAboveCeiling)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: suggest gas price error: %s", c.name, err.Error())
+		}
+		if got := bigString(price.GasPrice); got != c.wantPrice {
+			t.Fatalf("%s: gas price = %s, want %s", c.name, got, c.wantPrice)
+		}
+		if got := bigString(price.GasTipCap); got != c.wantTip {
+			t.Fatalf("%s: tip = %s, want %s", c.name, got, c.wantTip)
+		}
+		if got := bigString(price.GasFeeCap); got != c.wantFeeCap {
+			t.Fatalf("%s: fee cap = %s, want %s", c.name, got, c.wantFeeCap)
+		}
+	}
+}
+
+func TestSuggestGasPriceWithoutBaseFee(t *testing.T) {
+	for _, strategy := range []string{common.GasStrategyEIP1559, common.GasStrategyFeeHistory} {
+		chain := newGasStubClient(t, 50, 0, 2, []int64{}, []int64{})
+		chain.Config = &util.ChainInfo{Name: "stub", GasConfig: util.GasConfig{Strategy: strategy, FeeHistoryBlocks: 2, FeeHistoryPercentile: 50}}
+		if _, err := suggestGasPrice(chain); err == nil {
+			t.Fatalf("%s: chain without base fee is priced", strategy)
+		}
+	}
+}
+
+func TestEffectiveGasPrice(t *testing.T) {
+	to := ethcom.HexToAddress("0x01")
+	legacyTx := types.NewTransaction(0, to, big.NewInt(0), 21000, big.NewInt(30), nil)
+	dynamicTx := types.NewTx(&types.DynamicFeeTx{GasTipCap: big.NewInt(2), GasFeeCap: big.NewInt(120), Gas: 21000, To: &to})
+
+	cases := []struct {
+		name    string
+		tx      *types.Transaction
+		baseFee *big.Int
+		want    int64
+	}{
+		{name: "legacy tx pays its gas price", tx: legacyTx, baseFee: big.NewInt(10), want: 30},
+		{name: "dynamic fee tx pays the base fee and tip", tx: dynamicTx, baseFee: big.NewInt(100), want: 102},
+		{name: "dynamic fee tx pays at most its fee cap", tx: dynamicTx, baseFee: big.NewInt(119), want: 120},
+		{name: "block before london", tx: dynamicTx, baseFee: nil, want: 120},
+	}
+	for _, c := range cases {
+		if got := effectiveGasPrice(c.tx, c.baseFee); got.Cmp(big.NewInt(c.want)) != 0 {
+			t.Fatalf("%s: effective gas price = %s, want %d", c.name, got.String(), c.want)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package swap
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	ethcom "github.com/ethereum/go-ethereum/common"
+	"github.com/jinzhu/gorm"
+
+	"occ-swap-server/model"
+	"occ-swap-server/provider"
+	"occ-swap-server/util"
+)
+
+// NonceManager hands out the nonces of one signer on one chain. Nonces are reserved locally so
+// concurrent senders never race on PendingNonceAt, and the next nonce is persisted in db.
+type NonceManager struct {
+	mutex sync.Mutex
+
+	db     *gorm.DB
+	chain  string
+	signer ethcom.Address
+	client *provider.Pool
+
+	nextNonce uint64
+	// nonces handed out by Reserve which are neither committed nor released yet
+	reserved map[uint64]bool
+	// nonces released below nextNonce, they are reused first to fill the gaps
+	released []uint64
+	// one slot per reserved nonce, Reserve waits while all of them are taken
+	slots chan struct{}
+}
+
+// NewNonceManager returns the nonce manager of the signer, it is reconciled against the chain before being returned.
+// At most maxReserved nonces are reserved at once
+func NewNonceManager(db *gorm.DB, chain string, signer ethcom.Address, client *provider.Pool, maxReserved int) (*NonceManager, error) {
+	manager := &NonceManager{
+		db:       db,
+		chain:    chain,
+		signer:   signer,
+		client:   client,
+		reserved: make(map[uint64]bool),
+		released: make([]uint64, 0),
+		slots:    make(chan struct{}, maxReserved),
+	}
+
+	signerNonce := model.SignerNonce{}
+	err := db.Where("chain = ? and signer = ?", chain, signer.String()).First(&signerNonce).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+	manager.nextNonce = signerNonce.Nonce
+
+	if err := manager.Reconcile(); err != nil {
+		return nil, err
+	}
+	return manager, nil
+}
+
+// Reserve returns the nonce to be used by the next transaction, the caller must either Commit or Release it.
+// It waits while maxReserved nonces are reserved
+func (m *NonceManager) Reserve() (uint64, error) {
+	m.slots <- struct{}{}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if len(m.released) > 0 {
+		nonce := m.released[0]
+		m.released = m.released[1:]
+		m.reserved[nonce] = true
+		return nonce, nil
+	}
+
+	nonce := m.nextNonce
+	if err := m.saveNonce(nonce + 1); err != nil {
+		<-m.slots
+		return 0, err
+	}
+	m.nextNonce = nonce + 1
+	m.reserved[nonce] = true
+	return nonce, nil
+}
+
+// Commit marks the nonce as used by a broadcast transaction
+func (m *NonceManager) Commit(nonce uint64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if !m.reserved[nonce] {
+		return
+	}
+	delete(m.reserved, nonce)
+	<-m.slots
+}
+
+// Release gives back a nonce whose transaction was never broadcast
+func (m *NonceManager) Release(nonce uint64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if !m.reserved[nonce] {
+		return
+	}
+	delete(m.reserved, nonce)
+	<-m.slots
+
+	m.released = append(m.released, nonce)
+	sort.Slice(m.released, func(i, j int) bool { return m.released[i] < m.released[j] })
+
+	// roll back the next nonce while the highest handed out nonces are released
+	nextNonce := m.nextNonce
+	released := m.released
+	for len(released) > 0 && released[len(released)-1]+1 == nextNonce {
+		nextNonce--
+		released = released[:len(released)-1]
+	}
+	if nextNonce != m.nextNonce {
+		if err := m.saveNonce(nextNonce); err != nil {
+			util.Logger.Errorf("save nonce error, chain %s, signer %s, err=%s", m.chain, m.signer.String(), err.Error())
+		} else {
+			m.nextNonce = nextNonce
+			m.released = released
+		}
+	}
+
+	// a later nonce is already handed out, the released ones must be reused or the later txs will be stuck
+	if len(m.released) > 0 {
+		util.Logger.Infof("nonce gap on %s, signer %s, released nonces %v will be reused, next nonce is %d",
+			m.chain, m.signer.String(), m.released, m.nextNonce)
+	}
+}
+
+// Reconcile aligns the local nonce with the pending nonce of the chain, it should be called after broadcast errors
+func (m *NonceManager) Reconcile() error {
+	pendingNonce, err := m.client.PendingNonceAt(context.Background(), m.signer)
+	if err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// released nonces already taken on chain can not be reused
+	released := make([]uint64, 0, len(m.released))
+	for _, nonce := range m.released {
+		if nonce >= pendingNonce {
+			released = append(released, nonce)
+		}
+	}
+	m.released = released
+
+	switch {
+	case pendingNonce > m.nextNonce:
+		util.Logger.Infof("nonce of %s signer %s is used outside the swap engine, local nonce %d, pending nonce %d",
+			m.chain, m.signer.String(), m.nextNonce, pendingNonce)
+		if err := m.saveNonce(pendingNonce); err != nil {
+			return err
+		}
+		m.nextNonce = pendingNonce
+		m.released = m.released[:0]
+	case pendingNonce < m.nextNonce && len(m.reserved) == 0:
+		// nothing is in flight, so the nonces in [pendingNonce, nextNonce) never reached the chain
+		msg := fmt.Sprintf("nonce gap detected on %s, signer %s, local nonce %d, pending nonce %d",
+			m.chain, m.signer.String(), m.nextNonce, pendingNonce)
+		util.Logger.Errorf(msg)
+		util.SendTelegramMessage(msg)
+		if err := m.saveNonce(pendingNonce); err != nil {
+			return err
+		}
+		m.nextNonce = pendingNonce
+		m.released = m.released[:0]
+	}
+	return nil
+}
+
+func (m *NonceManager) saveNonce(nonce uint64) error {
+	signerNonce := model.SignerNonce{}
+	err := m.db.Where("chain = ? and signer = ?", m.chain, m.signer.String()).First(&signerNonce).Error
+	if err == gorm.ErrRecordNotFound {
+		return m.db.Create(&model.SignerNonce{
+			Chain:  m.chain,
+			Signer: m.signer.String(),
+			Nonce:  nonce,
+		}).Error
+	}
+	if err != nil {
+		return err
+	}
+	return m.db.Model(model.SignerNonce{}).Where("id = ?", signerNonce.Id).Updates(
+		map[string]interface{}{
+			"nonce":       nonce,
+			"update_time": time.Now().Unix(),
+		}).Error
+}
@@ -0,0 +1,282 @@
+package swap
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	ethcom "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+
+	"occ-swap-server/model"
+	"occ-swap-server/provider"
+	"occ-swap-server/util"
+)
+
+// rpcMethod answers a json-rpc call of the stub provider
+type rpcMethod func(params []json.RawMessage) (interface{}, error)
+
+// errStubUnreachable makes the stub provider fail the call like an unreachable provider
+var errStubUnreachable = errors.New("provider is unreachable")
+
+// newStubClient starts a local provider answering the methods, eth_blockNumber is answered for the health check
+// unless it is given
+func newStubClient(t *testing.T, methods map[string]rpcMethod) *provider.Pool {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		res := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		method, ok := methods[req.Method]
+		if !ok && req.Method == "eth_blockNumber" {
+			method = func([]json.RawMessage) (interface{}, error) { return hexutil.Uint64(1), nil }
+		}
+		if method == nil {
+			res["error"] = map[string]interface{}{"code": -32601, "message": "method not found: " + req.Method}
+		} else if result, err := method(req.Params); err == errStubUnreachable {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		} else if err != nil {
+			res["error"] = map[string]interface{}{"code": -32000, "message": err.Error()}
+		} else {
+			res["result"] = result
+		}
+		json.NewEncoder(w).Encode(res)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := provider.Dial(&util.ChainInfo{Name: "stub", Provider: server.URL})
+	if err != nil {
+		t.Fatalf("dial stub provider error: %s", err.Error())
+	}
+	return client
+}
+
+func newTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("open db error: %s", err.Error())
+	}
+	t.Cleanup(func() { db.Close() })
+	model.InitTables(db)
+	return db
+}
+
+const (
+	opReserve   = "reserve"
+	opCommit    = "commit"
+	opRelease   = "release"
+	opReconcile = "reconcile"
+)
+
+// nonceStep is an operation on the nonce manager, nonce is the nonce to commit or release and the nonce expected
+// from reserve, pending is the pending nonce of the chain from the step on
+type nonceStep struct {
+	op      string
+	nonce   uint64
+	pending uint64
+}
+
+func TestNonceManager(t *testing.T) {
+	cases := []struct {
+		name     string
+		pending  uint64
+		steps    []nonceStep
+		wantNext uint64
+	}{
+		{
+			name:    "reserve from the pending nonce",
+			pending: 5,
+			steps: []nonceStep{
+				{op: opReserve, nonce: 5},
+				{op: opReserve, nonce: 6},
+				{op: opCommit, nonce: 5},
+				{op: opCommit, nonce: 6},
+				{op: opReserve, nonce: 7},
+			},
+			wantNext: 8,
+		},
+		{
+			name:    "release the highest nonce rolls the next nonce back",
+			pending: 5,
+			steps: []nonceStep{
+				{op: opReserve, nonce: 5},
+				{op: opReserve, nonce: 6},
+				{op: opRelease, nonce: 6},
+				{op: opReserve, nonce: 6},
+			},
+			wantNext: 7,
+		},
+		{
+			name:    "released gap is reused first",
+			pending: 5,
+			steps: []nonceStep{
+				{op: opReserve, nonce: 5},
+				{op: opReserve, nonce: 6},
+				{op: opReserve, nonce: 7},
+				{op: opRelease, nonce: 6},
+				{op: opCommit, nonce: 7},
+				{op: opReserve, nonce: 6},
+				{op: opReserve, nonce: 8},
+			},
+			wantNext: 9,
+		},
+		{
+			name:    "committed nonce is never reused",
+			pending: 0,
+			steps: []nonceStep{
+				{op: opReserve, nonce: 0},
+				{op: opCommit, nonce: 0},
+				{op: opRelease, nonce: 0},
+				{op: opReserve, nonce: 1},
+			},
+			wantNext: 2,
+		},
+		{
+			name:    "reconcile follows a nonce used outside",
+			pending: 5,
+			steps: []nonceStep{
+				{op: opReserve, nonce: 5},
+				{op: opCommit, nonce: 5},
+				{op: opReconcile, pending: 9},
+				{op: opReserve, nonce: 9},
+			},
+			wantNext: 10,
+		},
+		{
+			name:    "reconcile closes a gap when nothing is in flight",
+			pending: 5,
+			steps: []nonceStep{
+				{op: opReserve, nonce: 5},
+				{op: opReserve, nonce: 6},
+				{op: opCommit, nonce: 5},
+				{op: opCommit, nonce: 6},
+				{op: opReconcile, pending: 6},
+				{op: opReserve, nonce: 6},
+			},
+			wantNext: 7,
+		},
+		{
+			name:    "reconcile keeps the nonces in flight",
+			pending: 5,
+			steps: []nonceStep{
+				{op: opReserve, nonce: 5},
+				{op: opReserve, nonce: 6},
+				{op: opCommit, nonce: 5},
+				{op: opReconcile, pending: 5},
+				{op: opReserve, nonce: 7},
+			},
+			wantNext: 8,
+		},
+		{
+			name:    "reconcile drops released nonces taken on chain",
+			pending: 5,
+			steps: []nonceStep{
+				{op: opReserve, nonce: 5},
+				{op: opReserve, nonce: 6},
+				{op: opRelease, nonce: 5},
+				{op: opReconcile, pending: 6},
+				{op: opCommit, nonce: 6},
+				{op: opReserve, nonce: 7},
+			},
+			wantNext: 8,
+		},
+	}
+
+	signer := ethcom.HexToAddress("0x01")
+	for _, c := range cases {
+		pending := c.pending
+		client := newStubClient(t, map[string]rpcMethod{
+			"eth_getTransactionCount": func([]json.RawMessage) (interface{}, error) {
+				return hexutil.Uint64(atomic.LoadUint64(&pending)), nil
+			},
+		})
+		db := newTestDB(t)
+		manager, err := NewNonceManager(db, "stub", signer, client, 10)
+		if err != nil {
+			t.Fatalf("%s: new nonce manager error: %s", c.name, err.Error())
+		}
+
+		for idx, step := range c.steps {
+			switch step.op {
+			case opReserve:
+				nonce, err := manager.Reserve()
+				if err != nil {
+					t.Fatalf("%s: step %d: reserve error: %s", c.name, idx, err.Error())
+				}
+				if nonce != step.nonce {
+					t.Fatalf("%s: step %d: reserve = %d, want %d", c.name, idx, nonce, step.nonce)
+				}
+			case opCommit:
+				manager.Commit(step.nonce)
+			case opRelease:
+				manager.Release(step.nonce)
+			case opReconcile:
+				atomic.StoreUint64(&pending, step.pending)
+				if err := manager.Reconcile(); err != nil {
+					t.Fatalf("%s: step %d: reconcile error: %s", c.name, idx, err.Error())
+				}
+			}
+		}
+
+		if manager.nextNonce != c.wantNext {
+			t.Fatalf("%s: next nonce = %d, want %d", c.name, manager.nextNonce, c.wantNext)
+		}
+		// the next nonce survives restarts
+		signerNonce := model.SignerNonce{}
+		if err := db.Where("chain = ? and signer = ?", "stub", signer.String()).First(&signerNonce).Error; err != nil {
+			t.Fatalf("%s: query saved nonce error: %s", c.name, err.Error())
+		}
+		if signerNonce.Nonce != c.wantNext {
+			t.Fatalf("%s: saved nonce = %d, want %d", c.name, signerNonce.Nonce, c.wantNext)
+		}
+	}
+}
+
+func TestNonceManagerWaitsForReservedSlots(t *testing.T) {
+	client := newStubClient(t, map[string]rpcMethod{
+		"eth_getTransactionCount": func([]json.RawMessage) (interface{}, error) { return hexutil.Uint64(0), nil },
+	})
+	manager, err := NewNonceManager(newTestDB(t), "stub", ethcom.HexToAddress("0x01"), client, 2)
+	if err != nil {
+		t.Fatalf("new nonce manager error: %s", err.Error())
+	}
+	for want := uint64(0); want < 2; want++ {
+		if nonce, err := manager.Reserve(); err != nil || nonce != want {
+			t.Fatalf("reserve = %d, %v, want %d", nonce, err, want)
+		}
+	}
+
+	reserved := make(chan uint64)
+	go func() {
+		nonce, _ := manager.Reserve()
+		reserved <- nonce
+	}()
+	select {
+	case nonce := <-reserved:
+		t.Fatalf("nonce %d is reserved while all slots are taken", nonce)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	manager.Commit(0)
+	select {
+	case nonce := <-reserved:
+		if nonce != 2 {
+			t.Fatalf("reserve = %d, want 2", nonce)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("reserve still waits after a slot is freed")
+	}
+}
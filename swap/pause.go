@@ -0,0 +1,191 @@
+package swap
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"occ-swap-server/common"
+	"occ-swap-server/model"
+	"occ-swap-server/util"
+)
+
+// pauseSet is the pauses in force
+type pauseSet []model.Pause
+
+// get returns the pause stopping the txs to the chain in the direction, it is nil if none applies. The direction is
+// empty for the txs which are not fills, e.g. refunds
+func (pauses pauseSet) get(chainName string, direction common.SwapDirection) *model.Pause {
+	for idx := range pauses {
+		pause := &pauses[idx]
+		switch pause.Scope {
+		case common.PauseScopeGlobal:
+			return pause
+		case common.PauseScopeChain:
+			if strings.EqualFold(pause.Target, chainName) {
+				return pause
+			}
+		case common.PauseScopeDirection:
+			if direction != "" && pause.Target == string(direction) {
+				return pause
+			}
+		}
+	}
+	return nil
+}
+
+// directions returns the paused directions
+func (pauses pauseSet) directions() []string {
+	directions := make([]string, 0)
+	for _, pause := range pauses {
+		if pause.Scope == common.PauseScopeDirection {
+			directions = append(directions, pause.Target)
+		}
+	}
+	return directions
+}
+
+// GetPauses returns the pauses in force
+func (engine *SwapEngine) GetPauses() ([]model.Pause, error) {
+	pauses := make([]model.Pause, 0)
+	err := engine.db.Order("id asc").Find(&pauses).Error
+	return pauses, err
+}
+
+// checkPauseTarget returns the target of the scope in its configured form, the chain of a chain pause must be
+// configured and the direction of a direction pause must be between configured chains
+func (engine *SwapEngine) checkPauseTarget(scope common.PauseScope, target string) (string, error) {
+	switch scope {
+	case common.PauseScopeGlobal:
+		if target != "" {
+			return "", fmt.Errorf("target of the global pause should be empty")
+		}
+		return target, nil
+	case common.PauseScopeChain:
+		chainCfg := engine.config.ChainConfig.GetChain(target)
+		if chainCfg == nil {
+			return "", fmt.Errorf("unknown chain: %s", target)
+		}
+		return chainCfg.Name, nil
+	case common.PauseScopeDirection:
+		if !engine.directionResolver.Has(common.SwapDirection(target)) {
+			return "", fmt.Errorf("unknown direction: %s", target)
+		}
+		return target, nil
+	default:
+		return "", fmt.Errorf("unknown pause scope: %s", scope)
+	}
+}
+
+// Pause stops sending the fills of the scope until it is resumed, target is the chain name of a chain pause and the
+// direction of a direction pause
+func (engine *SwapEngine) Pause(scope common.PauseScope, target, reason string) error {
+	target, err := engine.checkPauseTarget(scope, target)
+	if err != nil {
+		return err
+	}
+	inserted, err := engine.insertPause(scope, target, reason, common.PausedByAdmin)
+	if err != nil {
+		return err
+	}
+	if !inserted {
+		return fmt.Errorf("fills of %s %s are paused already", scope, target)
+	}
+	return nil
+}
+
+// Resume lifts the pauses of the scope, it returns the number of the lifted pauses
+func (engine *SwapEngine) Resume(scope common.PauseScope, target string) (int64, error) {
+	target, err := engine.checkPauseTarget(scope, target)
+	if err != nil {
+		return 0, err
+	}
+	lifted, err := model.LiftPauses(engine.db, scope, target)
+	if err != nil {
+		return 0, err
+	}
+	if lifted > 0 {
+		// the failures before the pause must not trip it again
+		for _, chain := range engine.chains {
+			if scope == common.PauseScopeGlobal || chain.Name == target {
+				atomic.StoreInt64(&chain.fillFailures, 0)
+			}
+		}
+		msg := fmt.Sprintf("fills of %s %s are resumed", scope, target)
+		util.Logger.Infof("%s", msg)
+		util.SendTelegramMessage(msg)
+	}
+	return lifted, nil
+}
+
+// tripPause pauses the scope for an anomaly, nothing is done if the scope is paused already
+func (engine *SwapEngine) tripPause(scope common.PauseScope, target, reason, pausedBy string) {
+	if _, err := engine.insertPause(scope, target, reason, pausedBy); err != nil {
+		util.Logger.Errorf("pause fills of %s %s error: %s", scope, target, err.Error())
+		util.SendTelegramMessage(fmt.Sprintf("Urgent alert: pause fills of %s %s error: %s", scope, target, err.Error()))
+	}
+}
+
+// insertPause pauses the scope unless it is paused already, it returns whether a pause is inserted
+func (engine *SwapEngine) insertPause(scope common.PauseScope, target, reason, pausedBy string) (bool, error) {
+	inserted, err := model.InsertPause(engine.db, scope, target, reason, pausedBy)
+	if err != nil || !inserted {
+		return inserted, err
+	}
+	msg := fmt.Sprintf("Urgent alert: fills of %s %s are paused by %s: %s", scope, target, pausedBy, reason)
+	util.Logger.Errorf("%s", msg)
+	util.SendTelegramMessage(msg)
+	return true, nil
+}
+
+// recordFillResult counts the fills to the chain failing in a row, the fills to the chain are paused once there are
+// too many of them. It writes the pause with its own connection, so it is called once the fill result is committed
+func (engine *SwapEngine) recordFillResult(chain *ChainIns, fillErr error) {
+	if fillErr == nil {
+		atomic.StoreInt64(&chain.fillFailures, 0)
+		return
+	}
+	failures := atomic.AddInt64(&chain.fillFailures, 1)
+	if failures >= chain.Config.GetMaxFillFailures() {
+		engine.tripPause(common.PauseScopeChain, chain.Name,
+			fmt.Sprintf("%d fills failed in a row, the last one: %s", failures, fillErr.Error()), common.PausedByFillFailures)
+	}
+}
+
+// balanceMonitorDaemon checks the native balances of the signers, it alerts while a balance is below the alert
+// threshold of its chain and pauses the fills to the chain once it is below the pause threshold
+func (engine *SwapEngine) balanceMonitorDaemon() {
+	for {
+		for _, chain := range engine.chains {
+			engine.checkBalance(chain)
+		}
+		time.Sleep(time.Duration(engine.config.ChainConfig.GetBalanceMonitorInterval()) * time.Second)
+	}
+}
+
+func (engine *SwapEngine) checkBalance(chain *ChainIns) {
+	if chain.Config.AlertThreshold == "" && chain.Config.PauseThreshold == "" {
+		return
+	}
+	signer := crypto.PubkeyToAddress(chain.PrivateKey.PublicKey)
+	balance, err := chain.Client.BalanceAt(context.Background(), signer, nil)
+	if err != nil {
+		util.Logger.Errorf("query balance of %s on %s error: %s", signer.String(), chain.Name, err.Error())
+		return
+	}
+
+	if threshold, ok := big.NewInt(0).SetString(chain.Config.AlertThreshold, 10); ok && balance.Cmp(threshold) < 0 {
+		msg := fmt.Sprintf("balance %s of signer %s on %s is below the alert threshold %s", balance.String(), signer.String(), chain.Name, threshold.String())
+		util.Logger.Errorf("%s", msg)
+		util.SendTelegramMessage(msg)
+	}
+	if threshold, ok := big.NewInt(0).SetString(chain.Config.PauseThreshold, 10); ok && balance.Cmp(threshold) < 0 {
+		engine.tripPause(common.PauseScopeChain, chain.Name,
+			fmt.Sprintf("balance %s of signer %s is below the pause threshold %s", balance.String(), signer.String(), threshold.String()), common.PausedByBalance)
+	}
+}
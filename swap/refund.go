@@ -0,0 +1,589 @@
+package swap
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	ethcom "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/jinzhu/gorm"
+
+	"occ-swap-server/model"
+	"occ-swap-server/provider"
+	"occ-swap-server/util"
+)
+
+// errSwapQuoteRejected is wrapped by the rejections of the swap pair quote, these swaps can be refunded
+var errSwapQuoteRejected = errors.New("swap quote rejected")
+
+// errSwapRefunded is returned when the swap is being refunded or is refunded, it must not be paid out
+var errSwapRefunded = errors.New("swap is refunded")
+
+// getRefundId returns the id passed to fillSwap to refund the swap, it differs from the swap id so the refund
+// is never taken as the payout of the swap
+func getRefundId(refund *model.SwapRefund) string {
+	swapId := getSwapId(refund.SwapId, refund.StartTxHash)
+	return crypto.Keccak256Hash([]byte("refund"), ethcom.HexToHash(swapId).Bytes()).Hex()
+}
+
+func (engine *SwapEngine) getSwapRefundHMAC(refund *model.SwapRefund) string {
+	material := fmt.Sprintf("%s#%s#%s#%s#%s#%s#%s",
+		refund.Chain, refund.StartTxHash, refund.Sponsor, refund.TokenAddr, refund.Amount, refund.Status, refund.RefundTxHash)
+	// appended only when set, so the hashes of refunds created before swap ids are recorded stay valid
+	if refund.SwapId != "" {
+		material = fmt.Sprintf("%s#%d#%s", material, refund.StartLogIndex, refund.SwapId)
+	}
+	mac := hmac.New(sha256.New, []byte(engine.hmacCKey))
+	mac.Write([]byte(material))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (engine *SwapEngine) verifySwapRefund(refund *model.SwapRefund) bool {
+	return refund.RecordHash == engine.getSwapRefundHMAC(refund)
+}
+
+func (engine *SwapEngine) updateSwapRefund(tx *gorm.DB, refund *model.SwapRefund) {
+	refund.RecordHash = engine.getSwapRefundHMAC(refund)
+	tx.Save(refund)
+}
+
+// getActiveSwapRefund returns the refund of the swap which is not failed, it is nil if there is none. A missing refund
+// is active, its refund tx may still land
+func (engine *SwapEngine) getActiveSwapRefund(tx *gorm.DB, startTxHash string, startLogIndex uint) *model.SwapRefund {
+	refund := model.SwapRefund{}
+	err := tx.Where("start_tx_hash = ? and start_log_index = ? and status != ?", startTxHash, startLogIndex, RefundFailed).First(&refund).Error
+	if err != nil {
+		return nil
+	}
+	return &refund
+}
+
+// getConfirmedRefundFill returns the confirmed SwapFilled event of a refund of the swap, it is nil if there is none.
+// It finds the refund txs which landed after their refunds were given up
+func (engine *SwapEngine) getConfirmedRefundFill(startTxHash string, startLogIndex uint, swapId string) (*model.SwapFilledTxLog, error) {
+	refundId := getRefundId(&model.SwapRefund{StartTxHash: startTxHash, StartLogIndex: startLogIndex, SwapId: swapId})
+	fillLog := model.SwapFilledTxLog{}
+	err := engine.db.Where("swap_id = ? and status = ?", refundId, model.TxStatusConfirmed).First(&fillLog).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &fillLog, nil
+}
+
+// checkSwapNotRefunded returns errSwapRefunded if the swap has a refund which is not failed or a refund of the swap
+// is confirmed on chain
+func (engine *SwapEngine) checkSwapNotRefunded(startTxHash string, startLogIndex uint, swapId string) error {
+	if refund := engine.getActiveSwapRefund(engine.db, startTxHash, startLogIndex); refund != nil {
+		return fmt.Errorf("%w, chain %s, status %s", errSwapRefunded, refund.Chain, refund.Status)
+	}
+	fillLog, err := engine.getConfirmedRefundFill(startTxHash, startLogIndex, swapId)
+	if err != nil {
+		return err
+	}
+	if fillLog != nil {
+		return fmt.Errorf("%w, chain %s, refund tx hash %s", errSwapRefunded, fillLog.Chain, fillLog.TxHash)
+	}
+	return nil
+}
+
+// unsettledFillTxStatuses are the statuses of the fill txs which may still be mined
+var unsettledFillTxStatuses = []model.FillTxStatus{model.FillTxCreated, model.FillTxSent, model.FillTxReplaced, model.FillTxMissing}
+
+// checkSwapFillsSettled returns an error unless every fill tx of the swap is provably failed or dropped and no
+// SwapFilled event of the swap is confirmed, a fill tx which may still be mined must not be paid out again
+func (engine *SwapEngine) checkSwapFillsSettled(tx *gorm.DB, swap *model.Swap) error {
+	var unsettled int64
+	err := tx.Model(model.SwapFillTx{}).Where("start_swap_tx_hash = ? and start_log_index = ? and status in (?)",
+		swap.StartTxHash, swap.StartLogIndex, unsettledFillTxStatuses).Count(&unsettled).Error
+	if err != nil {
+		return err
+	}
+	if unsettled > 0 {
+		return fmt.Errorf("%d fill txs of swap %s may still be mined", unsettled, swap.StartTxHash)
+	}
+	fillLog, err := engine.getConfirmedSwapFill(swap.StartTxHash, swap.StartLogIndex, swap.SwapId)
+	if err != nil {
+		return err
+	}
+	if fillLog != nil {
+		return fmt.Errorf("%w, chain %s, fill tx hash %s", errSwapAlreadyFilled, fillLog.Chain, fillLog.TxHash)
+	}
+	return nil
+}
+
+// insertSwapRefund records a pending refund of the deposit of the swap, a failed refund of the same deposit is sent again
+func (engine *SwapEngine) insertSwapRefund(tx *gorm.DB, txEventLog *model.SwapStartTxLog, swap *model.Swap) error {
+	chainCfg := engine.config.ChainConfig.GetChain(txEventLog.Chain)
+	if chainCfg == nil {
+		return fmt.Errorf("unknown chain: %s", txEventLog.Chain)
+	}
+	reason := swap.Log
+	util.Logger.Infof("refund swap, chain %s, start tx hash %s, reason: %s", txEventLog.Chain, txEventLog.TxHash, reason)
+
+	refund := model.SwapRefund{}
+	err := tx.Where("start_tx_hash = ? and start_log_index = ?", txEventLog.TxHash, txEventLog.LogIndex).First(&refund).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return err
+	}
+	if err == nil && refund.Status != RefundFailed {
+		return fmt.Errorf("swap %s is already refunded", txEventLog.TxHash)
+	}
+	refund.Chain = chainCfg.Name
+	refund.StartTxHash = txEventLog.TxHash
+	refund.StartLogIndex = txEventLog.LogIndex
+	refund.SwapId = swap.SwapId
+	refund.Sponsor = txEventLog.FromAddress
+	refund.TokenAddr = txEventLog.TokenAddr
+	refund.Amount = txEventLog.Amount
+	refund.Reason = reason
+	refund.Status = RefundPending
+	refund.RefundTxHash = ""
+	refund.ErrorMsg = ""
+	refund.TrackRetryCounter = 0
+	refund.RecordHash = engine.getSwapRefundHMAC(&refund)
+	return tx.Save(&refund).Error
+}
+
+// refundRejectedSwap refunds the swap rejected for its quote if the source chain refunds rejected swaps
+func (engine *SwapEngine) refundRejectedSwap(tx *gorm.DB, txEventLog *model.SwapStartTxLog, swap *model.Swap) error {
+	chainCfg := engine.config.ChainConfig.GetChain(txEventLog.Chain)
+	if chainCfg == nil || !chainCfg.RefundRejectedSwaps {
+		return nil
+	}
+	return engine.insertSwapRefund(tx, txEventLog, swap)
+}
+
+// refundFailedSwaps refunds the failed swaps from the chains which refund failed swaps, swaps being retried and swaps
+// whose fill txs may still be mined are skipped
+func (engine *SwapEngine) refundFailedSwaps() {
+	chains := make([]string, 0)
+	for _, chainCfg := range engine.config.ChainConfig.Chains {
+		if chainCfg.RefundFailedSwaps {
+			chains = append(chains, chainCfg.Name)
+		}
+	}
+	if len(chains) == 0 {
+		return
+	}
+
+	swaps := make([]model.Swap, 0)
+	engine.db.Where("status = ? and start_tx_hash in (?)", SwapSendFailed,
+		engine.db.Model(model.SwapStartTxLog{}).Select("tx_hash").Where("chain in (?)", chains).QueryExpr()).
+		Where("not exists (?)", engine.db.Model(model.SwapRefund{}).Select("id").
+			Where("swap_refunds.start_tx_hash = swaps.start_tx_hash and swap_refunds.start_log_index = swaps.start_log_index").QueryExpr()).
+		Where("not exists (?)", engine.db.Model(model.RetrySwap{}).Select("id").
+			Where("retry_swaps.start_tx_hash = swaps.start_tx_hash and retry_swaps.start_log_index = swaps.start_log_index").QueryExpr()).
+		Where("not exists (?)", engine.db.Model(model.SwapFillTx{}).Select("id").
+			Where("swap_fill_txs.start_swap_tx_hash = swaps.start_tx_hash and swap_fill_txs.start_log_index = swaps.start_log_index and swap_fill_txs.status in (?)",
+				unsettledFillTxStatuses).QueryExpr()).
+		Order("id asc").Limit(BatchSize).Find(&swaps)
+
+	for _, swap := range swaps {
+		if _, _, err := engine.InsertRefundSwaps([]uint{swap.ID}); err != nil {
+			util.Logger.Errorf("refund failed swap error: %s, start hash %s", err.Error(), swap.StartTxHash)
+		}
+	}
+}
+
+// InsertRefundSwaps refunds the rejected and failed swaps of the list, it returns the ids of the refunded swaps
+// and the ids of the swaps which can not be refunded
+func (engine *SwapEngine) InsertRefundSwaps(swapIDList []uint) ([]uint, []uint, error) {
+	swaps := make([]model.Swap, 0)
+	engine.db.Where("id in (?)", swapIDList).Find(&swaps)
+
+	if len(swaps) == 0 {
+		return nil, nil, fmt.Errorf("no matched swap")
+	}
+
+	refundSwapList := make([]uint, 0, len(swapIDList))
+	rejectedRefundSwapList := make([]uint, 0, len(swapIDList))
+	writeDBErr := func() error {
+		tx := engine.db.Begin()
+		if err := tx.Error; err != nil {
+			return err
+		}
+		for _, swap := range swaps {
+			if !engine.verifySwap(&swap) {
+				rejectedRefundSwapList = append(rejectedRefundSwapList, swap.ID)
+				continue
+			}
+			if swap.Status != SwapQuoteRejected && swap.Status != SwapSendFailed {
+				rejectedRefundSwapList = append(rejectedRefundSwapList, swap.ID)
+				continue
+			}
+			// a retry swap may still pay out the swap
+			var retrying int64
+			tx.Model(model.RetrySwap{}).Where("start_tx_hash = ? and start_log_index = ? and status != ?", swap.StartTxHash, swap.StartLogIndex, RetrySwapSendFailed).
+				Count(&retrying)
+			if retrying > 0 || engine.getActiveSwapRefund(tx, swap.StartTxHash, swap.StartLogIndex) != nil {
+				rejectedRefundSwapList = append(rejectedRefundSwapList, swap.ID)
+				continue
+			}
+			if err := engine.checkSwapFillsSettled(tx, &swap); err != nil {
+				util.Logger.Infof("refuse to refund swap, start hash %s: %s", swap.StartTxHash, err.Error())
+				rejectedRefundSwapList = append(rejectedRefundSwapList, swap.ID)
+				continue
+			}
+			var txEventLog model.SwapStartTxLog
+			if err := tx.Where("tx_hash = ? and log_index = ?", swap.StartTxHash, swap.StartLogIndex).First(&txEventLog).Error; err != nil {
+				rejectedRefundSwapList = append(rejectedRefundSwapList, swap.ID)
+				continue
+			}
+			if err := engine.insertSwapRefund(tx, &txEventLog, &swap); err != nil {
+				tx.Rollback()
+				return err
+			}
+			refundSwapList = append(refundSwapList, swap.ID)
+		}
+		return tx.Commit().Error
+	}()
+	return refundSwapList, rejectedRefundSwapList, writeDBErr
+}
+
+// refundDaemon sends the pending refunds once their deposits are confirmed
+func (engine *SwapEngine) refundDaemon() {
+	for {
+		engine.refundFailedSwaps()
+
+		refunds := make([]model.SwapRefund, 0)
+		engine.db.Where("status = ? and start_tx_hash in (?)", RefundPending,
+			engine.db.Model(model.SwapStartTxLog{}).Select("tx_hash").Where("status = ?", model.TxStatusConfirmed).QueryExpr()).
+			Order("id asc").Limit(BatchSize).Find(&refunds)
+
+		pauses, err := engine.GetPauses()
+		if err != nil {
+			util.Logger.Errorf("query pauses error: %s", err.Error())
+			time.Sleep(SleepTime * time.Second)
+			continue
+		}
+		paused := 0
+		for _, refund := range refunds {
+			if pauseSet(pauses).get(refund.Chain, "") != nil {
+				paused++
+				continue
+			}
+			if !engine.verifySwapRefund(&refund) {
+				engine.blockTamperedRefund(&refund)
+				continue
+			}
+			var refundErr error
+			if refund.RefundTxHash != "" {
+				util.Logger.Infof("refund tx is built successfully, but the refund tx status is uncertain, just mark the refund as sent, start hash %s", refund.StartTxHash)
+			} else {
+				refundErr = engine.doRefund(&refund)
+			}
+
+			writeDBErr := func() error {
+				tx := engine.db.Begin()
+				if err := tx.Error; err != nil {
+					return err
+				}
+				if refundErr != nil {
+					util.Logger.Errorf("refund swap failed: %s, start hash %s", refundErr.Error(), refund.StartTxHash)
+					util.SendTelegramMessage(fmt.Sprintf("refund swap failed: %s, start hash %s", refundErr.Error(), refund.StartTxHash))
+					refund.Status = RefundFailed
+					refund.ErrorMsg = refundErr.Error()
+				} else {
+					refund.Status = RefundSent
+				}
+				engine.updateSwapRefund(tx, &refund)
+				return tx.Commit().Error
+			}()
+			if writeDBErr != nil {
+				util.Logger.Errorf("write db error: %s", writeDBErr.Error())
+				util.SendTelegramMessage(fmt.Sprintf("write db error: %s", writeDBErr.Error()))
+			}
+		}
+		if paused == len(refunds) {
+			time.Sleep(SleepTime * time.Second)
+		}
+	}
+}
+
+// blockTamperedRefund stops the refund failing the hmac verification. It is marked missing, so the swap is neither
+// refunded again nor paid out, and only its status and error are updated so the row still fails the verification
+// and the tampering stays visible
+func (engine *SwapEngine) blockTamperedRefund(refund *model.SwapRefund) {
+	util.Logger.Errorf("verify hmac of swap refund failed: %s", refund.StartTxHash)
+	util.SendTelegramMessage(fmt.Sprintf("Urgent alert: verify hmac of swap refund failed: %s", refund.StartTxHash))
+	err := engine.db.Model(model.SwapRefund{}).Where("id = ?", refund.ID).UpdateColumns(
+		map[string]interface{}{
+			"status":    RefundMissing,
+			"error_msg": fmt.Sprintf("verify hmac of swap refund failed: %s", refund.StartTxHash),
+		}).Error
+	if err != nil {
+		util.Logger.Errorf("write db error: %s", err.Error())
+		util.SendTelegramMessage(fmt.Sprintf("write db error: %s", err.Error()))
+	}
+}
+
+// doRefund calls fillSwap on the source chain, the swap agent pays the deposited token back to the sponsor.
+// The refund tx is recorded before it is broadcast so it is tracked even if the process stops in between
+func (engine *SwapEngine) doRefund(refund *model.SwapRefund) error {
+	fillLog, err := engine.getConfirmedSwapFill(refund.StartTxHash, refund.StartLogIndex, refund.SwapId)
+	if err != nil {
+		return err
+	}
+	if fillLog != nil {
+		return fmt.Errorf("%w, chain %s, fill tx hash %s", errSwapAlreadyFilled, fillLog.Chain, fillLog.TxHash)
+	}
+	// a refund given up before may have landed
+	refundLog, err := engine.getConfirmedRefundFill(refund.StartTxHash, refund.StartLogIndex, refund.SwapId)
+	if err != nil {
+		return err
+	}
+	if refundLog != nil {
+		return fmt.Errorf("%w, chain %s, refund tx hash %s", errSwapRefunded, refundLog.Chain, refundLog.TxHash)
+	}
+
+	chain, err := engine.getChainByName(refund.Chain)
+	if err != nil {
+		return err
+	}
+	if err := engine.checkDestinationToken(chain, chain.ChainID.String(), refund.TokenAddr); err != nil {
+		return err
+	}
+	amount := big.NewInt(0)
+	if _, ok := amount.SetString(refund.Amount, 10); !ok {
+		return fmt.Errorf("invalid refund amount: %s", refund.Amount)
+	}
+	data, err := abiEncodeFillSwap(getRefundId(refund), chain.ChainID, ethcom.HexToAddress(refund.Sponsor), amount, engine.swapAgentABI)
+	if err != nil {
+		return err
+	}
+
+	chain.mutex.Lock()
+	defer chain.mutex.Unlock()
+	nonce, err := chain.nonceManager.Reserve()
+	if err != nil {
+		return err
+	}
+	signedTx, err := buildSignedTransaction(chain, chain.SwapAgent, data, nonce)
+	if err != nil {
+		chain.nonceManager.Release(nonce)
+		return err
+	}
+	refund.RefundTxHash = signedTx.Hash().String()
+	refund.Nonce = nonce
+	refund.GasPrice = signedTx.GasPrice().String()
+	engine.updateSwapRefund(engine.db, refund)
+
+	err = chain.Client.SendTransaction(context.Background(), signedTx)
+	if errors.Is(err, provider.ErrSendUncertain) {
+		// the tx may be relayed, it is tracked like a sent tx and settled by the chain
+		util.Logger.Errorf("broadcast refund tx to %s is uncertain, track it as sent: %s", chain.Name, err.Error())
+		err = nil
+	}
+	if err != nil {
+		util.Logger.Errorf("broadcast tx to %s error: %s", chain.Name, err.Error())
+		chain.nonceManager.Release(nonce)
+		if reconcileErr := chain.nonceManager.Reconcile(); reconcileErr != nil {
+			util.Logger.Errorf("reconcile nonce of %s error: %s", chain.Name, reconcileErr.Error())
+		}
+		return err
+	}
+	chain.nonceManager.Commit(nonce)
+	util.Logger.Infof("Send refund transaction to %s, %s/%s", chain.Name, chain.Config.ExplorerUrl, signedTx.Hash().String())
+	return nil
+}
+
+// trackRefundTxDaemon tracks the sent refund txs like the fill txs, the swap is marked as refunded once
+// its refund tx is confirmed
+func (engine *SwapEngine) trackRefundTxDaemon() {
+	for {
+		time.Sleep(SleepTime * time.Second)
+
+		for _, chain := range engine.chains {
+			refunds := make([]model.SwapRefund, 0)
+			engine.db.Where("status = ? and chain = ?", RefundSent, chain.Name).
+				Order("id asc").Limit(TrackSentTxBatchSize).Find(&refunds)
+
+			if len(refunds) > 0 {
+				util.Logger.Debugf("Track %d non-finalized refund txs on %s", len(refunds), chain.Name)
+			}
+
+			for _, refund := range refunds {
+				if !engine.verifySwapRefund(&refund) {
+					util.Logger.Errorf("verify hmac of swap refund failed: %s", refund.StartTxHash)
+					util.SendTelegramMessage(fmt.Sprintf("Urgent alert: verify hmac of swap refund failed: %s", refund.StartTxHash))
+					continue
+				}
+
+				var txRecipient *types.Receipt
+				var baseFee, txFee *big.Int
+				queryTxStatusErr := func() error {
+					block, err := chain.Client.BlockByNumber(context.Background(), nil)
+					if err != nil {
+						util.Logger.Debugf("%s, query block failed: %s", chain.Name, err.Error())
+						return err
+					}
+					txRecipient, err = chain.Client.TransactionReceipt(context.Background(), ethcom.HexToHash(refund.RefundTxHash))
+					if err != nil {
+						util.Logger.Debugf("%s, query tx failed: %s", chain.Name, err.Error())
+						return err
+					}
+					if block.Number().Int64() < txRecipient.BlockNumber.Int64()+chain.Config.ConfirmNum {
+						return fmt.Errorf("%s, refund tx is still not finalized", chain.Name)
+					}
+					baseFee, txFee, err = getFillTxFee(chain, txRecipient)
+					if err != nil {
+						util.Logger.Debugf("%s, query tx fee failed: %s", chain.Name, err.Error())
+						return err
+					}
+					return nil
+				}()
+
+				writeDBErr := func() error {
+					tx := engine.db.Begin()
+					if err := tx.Error; err != nil {
+						return err
+					}
+					if queryTxStatusErr != nil {
+						refund.TrackRetryCounter++
+						// the refund tx may still land, so the refund is not failed and the swap can not be paid out again
+						if refund.TrackRetryCounter >= chain.Config.MaxTrackRetry {
+							util.Logger.Errorf("The refund tx is sent, however, after %d seconds its status is still uncertain. Mark refund as missing, chain %s, refund hash %s", SleepTime*chain.Config.MaxTrackRetry, chain.Name, refund.RefundTxHash)
+							util.SendTelegramMessage(fmt.Sprintf("Urgent alert: The refund tx is sent, however, after %d seconds its status is still uncertain. Mark refund as missing, it must be resolved by hand, chain %s, refund hash %s", SleepTime*chain.Config.MaxTrackRetry, chain.Name, refund.RefundTxHash))
+							refund.Status = RefundMissing
+							refund.ErrorMsg = fmt.Sprintf("track refund tx for more than %d times, the refund tx status is still uncertain", chain.Config.MaxTrackRetry)
+						}
+						engine.updateSwapRefund(tx, &refund)
+						return tx.Commit().Error
+					}
+					if err := engine.settleRefund(tx, chain, &refund, txRecipient, baseFee, txFee); err != nil {
+						tx.Rollback()
+						return err
+					}
+					return tx.Commit().Error
+				}()
+				if writeDBErr != nil {
+					util.Logger.Errorf("write db error: %s", writeDBErr.Error())
+					util.SendTelegramMessage(fmt.Sprintf("write db error: %s", writeDBErr.Error()))
+				}
+			}
+		}
+	}
+}
+
+// settleRefund records the result of the finalized refund tx, the swap is marked as refunded if the refund succeeded
+func (engine *SwapEngine) settleRefund(tx *gorm.DB, chain *ChainIns, refund *model.SwapRefund, txRecipient *types.Receipt, baseFee, txFee *big.Int) error {
+	refund.Height = txRecipient.BlockNumber.Int64()
+	if baseFee != nil {
+		refund.BaseFee = baseFee.String()
+	}
+	refund.ConsumedFeeAmount = txFee.String()
+	if txRecipient.Status == TxFailedStatus || !engine.verifySwapFilled(chain, txRecipient, getRefundId(refund)) {
+		util.Logger.Errorf("refund tx is failed, chain %s, txHash: %s", chain.Name, txRecipient.TxHash.String())
+		util.SendTelegramMessage(fmt.Sprintf("refund tx is failed, chain %s, txHash: %s", chain.Name, txRecipient.TxHash.String()))
+		refund.Status = RefundFailed
+		refund.ErrorMsg = "refund tx is failed"
+		engine.updateSwapRefund(tx, refund)
+		return nil
+	}
+
+	util.Logger.Infof("refund tx is success, chain %s, txHash: %s", chain.Name, txRecipient.TxHash.String())
+	refund.Status = RefundSuccess
+	engine.updateSwapRefund(tx, refund)
+
+	swap, err := engine.getSwapByStartTx(tx, refund.StartTxHash, refund.StartLogIndex)
+	if err != nil {
+		return err
+	}
+	swap.Status = SwapRefunded
+	swap.Log = fmt.Sprintf("refunded, refund txHash %s", refund.RefundTxHash)
+	engine.updateSwap(tx, swap)
+	return nil
+}
+
+// resolveMissingRefund settles the missing refund by the chain. A finalized refund tx is settled like a tracked one. A
+// refund tx which is not mined while a finalized tx of the signer took its nonce can never land, the refund is failed
+// and the swap can be refunded again. Otherwise the refund stays missing
+func (engine *SwapEngine) resolveMissingRefund(refund *model.SwapRefund) error {
+	chain, err := engine.getChainByName(refund.Chain)
+	if err != nil {
+		return err
+	}
+	head, err := chain.Client.BlockNumber(context.Background())
+	if err != nil {
+		return err
+	}
+	finalized := int64(head) - chain.Config.ConfirmNum
+	if finalized < 0 {
+		return fmt.Errorf("%s has no finalized block", chain.Name)
+	}
+
+	txRecipient, err := chain.Client.TransactionReceipt(context.Background(), ethcom.HexToHash(refund.RefundTxHash))
+	if err == nil {
+		if txRecipient.BlockNumber.Int64() > finalized {
+			return fmt.Errorf("refund tx %s is still not finalized", refund.RefundTxHash)
+		}
+		baseFee, txFee, err := getFillTxFee(chain, txRecipient)
+		if err != nil {
+			return err
+		}
+		tx := engine.db.Begin()
+		if err := tx.Error; err != nil {
+			return err
+		}
+		if err := engine.settleRefund(tx, chain, refund, txRecipient, baseFee, txFee); err != nil {
+			tx.Rollback()
+			return err
+		}
+		return tx.Commit().Error
+	}
+
+	signer := crypto.PubkeyToAddress(chain.PrivateKey.PublicKey)
+	nonce, err := chain.Client.NonceAt(context.Background(), signer, big.NewInt(finalized))
+	if err != nil {
+		return err
+	}
+	if nonce <= refund.Nonce {
+		return fmt.Errorf("refund tx %s is not mined and its nonce %d is not taken yet", refund.RefundTxHash, refund.Nonce)
+	}
+	util.Logger.Infof("refund tx is dropped, chain %s, txHash: %s", chain.Name, refund.RefundTxHash)
+	refund.Status = RefundFailed
+	refund.ErrorMsg = fmt.Sprintf("refund tx is dropped, its nonce %d is taken by another tx", refund.Nonce)
+	engine.updateSwapRefund(engine.db, refund)
+	return nil
+}
+
+// ResolveMissingRefunds settles the missing refunds of the swaps of the list by the chain, it returns the ids of the
+// swaps whose refunds are resolved and the ids of the swaps whose refunds can not be resolved yet
+func (engine *SwapEngine) ResolveMissingRefunds(swapIDList []uint) ([]uint, []uint, error) {
+	swaps := make([]model.Swap, 0)
+	engine.db.Where("id in (?)", swapIDList).Find(&swaps)
+
+	if len(swaps) == 0 {
+		return nil, nil, fmt.Errorf("no matched swap")
+	}
+
+	resolvedSwapList := make([]uint, 0, len(swapIDList))
+	rejectedResolveSwapList := make([]uint, 0, len(swapIDList))
+	for _, swap := range swaps {
+		refund := model.SwapRefund{}
+		err := engine.db.Where("start_tx_hash = ? and start_log_index = ? and status = ?", swap.StartTxHash, swap.StartLogIndex, RefundMissing).
+			First(&refund).Error
+		if err != nil || !engine.verifySwapRefund(&refund) {
+			rejectedResolveSwapList = append(rejectedResolveSwapList, swap.ID)
+			continue
+		}
+		if err := engine.resolveMissingRefund(&refund); err != nil {
+			util.Logger.Errorf("resolve missing refund error: %s, start hash %s", err.Error(), swap.StartTxHash)
+			rejectedResolveSwapList = append(rejectedResolveSwapList, swap.ID)
+			continue
+		}
+		resolvedSwapList = append(resolvedSwapList, swap.ID)
+	}
+	return resolvedSwapList, rejectedResolveSwapList, nil
+}
@@ -0,0 +1,28 @@
+package swap
+
+import (
+	"fmt"
+
+	"occ-swap-server/model"
+	"occ-swap-server/util"
+)
+
+// screenSwap screens the sponsor of the swap started by the event log, who is paid out by the fill as well. It
+// returns the reason the sponsor is flagged for, which is empty if the sponsor is clear or the swap is not waiting
+// to be confirmed
+func (engine *SwapEngine) screenSwap(txEventLog *model.SwapStartTxLog) (string, error) {
+	swap, err := engine.getSwapByStartTx(engine.db, txEventLog.TxHash, txEventLog.LogIndex)
+	// the swap is checked again when it is confirmed
+	if err != nil || swap.Status != SwapTokenReceived {
+		return "", nil
+	}
+	return engine.screener.Screen(swap.Sponsor)
+}
+
+// alertBlockedSwap reports the swap blocked by the screening
+func (engine *SwapEngine) alertBlockedSwap(swap *model.Swap) {
+	msg := fmt.Sprintf("Urgent alert: swap is blocked, swap ID %d, start tx hash %s, sponsor %s, amount %s: %s",
+		swap.ID, swap.StartTxHash, swap.Sponsor, swap.Amount, swap.Log)
+	util.Logger.Errorf("%s", msg)
+	util.SendTelegramMessage(msg)
+}
@@ -5,26 +5,31 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"math/big"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	ethcom "github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/jinzhu/gorm"
 
 	sabi "occ-swap-server/abi"
 	"occ-swap-server/common"
+	"occ-swap-server/executor"
 	"occ-swap-server/model"
+	"occ-swap-server/provider"
+	"occ-swap-server/screening"
 	"occ-swap-server/util"
 )
 
-// NewSwapEngine returns the swapEngine instance
-func NewSwapEngine(db *gorm.DB, cfg *util.Config, bscClient, ethClient, maticClient *ethclient.Client) (*SwapEngine, error) {
+// NewSwapEngine returns the swapEngine instance, clients are keyed by the chain name
+func NewSwapEngine(db *gorm.DB, cfg *util.Config, clients map[string]*provider.Pool) (*SwapEngine, error) {
 	pairs := make([]model.SwapPair, 0)
 	db.Find(&pairs)
 
@@ -44,36 +49,60 @@ func NewSwapEngine(db *gorm.DB, cfg *util.Config, bscClient, ethClient, maticCli
 		return nil, err
 	}
 
-	bscPrivateKey, _, err := BuildKeys(keyConfig.BSCPrivateKey)
-	if err != nil {
-		return nil, err
-	}
+	chains := make(map[string]*ChainIns, len(cfg.ChainConfig.Chains))
+	for idx := range cfg.ChainConfig.Chains {
+		chainCfg := &cfg.ChainConfig.Chains[idx]
 
-	ethPrivateKey, _, err := BuildKeys(keyConfig.ETHPrivateKey)
-	if err != nil {
-		return nil, err
-	}
+		client, ok := clients[chainCfg.Name]
+		if !ok {
+			return nil, fmt.Errorf("missing client of chain %s", chainCfg.Name)
+		}
 
-	maticPrivateKey, _, err := BuildKeys(keyConfig.MATICPrivateKey)
-	if err != nil {
-		return nil, err
-	}
+		privateKeyStr, ok := keyConfig.GetPrivateKey(chainCfg.Name)
+		if !ok {
+			return nil, fmt.Errorf("missing private key of chain %s", chainCfg.Name)
+		}
+		privateKey, _, err := BuildKeys(privateKeyStr)
+		if err != nil {
+			return nil, err
+		}
 
-	bscChainID, err := bscClient.ChainID(context.Background())
-	if err != nil {
-		return nil, err
+		chainID, err := client.ChainID(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		if !chainCfg.HasChainId(chainID.Int64()) {
+			return nil, fmt.Errorf("chain id %s reported by the provider of %s is not in chain_ids", chainID.String(), chainCfg.Name)
+		}
 
+		agents, err := executor.LoadAgents(chainCfg)
+		if err != nil {
+			return nil, err
+		}
+
+		nonceManager, err := NewNonceManager(db, chainCfg.Name, crypto.PubkeyToAddress(privateKey.PublicKey), client, chainCfg.GetSwapWorkers())
+		if err != nil {
+			return nil, err
+		}
+
+		chains[chainCfg.Name] = &ChainIns{
+			Name:         chainCfg.Name,
+			ChainID:      chainID,
+			Client:       client,
+			PrivateKey:   privateKey,
+			SwapAgent:    ethcom.HexToAddress(chainCfg.SwapAgentAddr),
+			Agent:        agents[len(agents)-1],
+			Config:       chainCfg,
+			nonceManager: nonceManager,
+		}
 	}
-	ethChainID, err := ethClient.ChainID(context.Background())
-	if err != nil {
-		return nil, err
-	}
-	maticChainID, err := maticClient.ChainID(context.Background())
+
+	SwapAgentAbi, err := abi.JSON(strings.NewReader(sabi.SwapAgentABI))
 	if err != nil {
 		return nil, err
 	}
 
-	SwapAgentAbi, err := abi.JSON(strings.NewReader(sabi.SwapAgentABI))
+	screener, err := screening.NewScreener(&cfg.ScreeningConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -82,22 +111,13 @@ func NewSwapEngine(db *gorm.DB, cfg *util.Config, bscClient, ethClient, maticCli
 		db:                     db,
 		config:                 cfg,
 		hmacCKey:               keyConfig.HMACKey,
-		ethPrivateKey:          ethPrivateKey,
-		bscPrivateKey:          bscPrivateKey,
-		maticPrivateKey:        maticPrivateKey,
-		bscClient:              bscClient,
-		ethClient:              ethClient,
-		maticClient:            maticClient,
-		bscChainID:             bscChainID.Int64(),
-		ethChainID:             ethChainID.Int64(),
-		maticChainID:           maticChainID.Int64(),
+		chains:                 chains,
 		swapPairsFromERC20Addr: swapPairInstances,
 		bep20ToERC20:           bscContractAddrToEthContractAddr,
 		erc20ToBEP20:           ethContractAddrToBscContractAddr,
+		directionResolver:      NewDirectionResolver(cfg.ChainConfig.Chains),
+		screener:               screener,
 		swapAgentABI:           &SwapAgentAbi,
-		ethSwapAgent:           ethcom.HexToAddress(cfg.ChainConfig.ETHSwapAgentAddr),
-		bscSwapAgent:           ethcom.HexToAddress(cfg.ChainConfig.BSCSwapAgentAddr),
-		maticSwapAgent:         ethcom.HexToAddress(cfg.ChainConfig.MATICSwapAgentAddr),
 	}
 
 	return swapEngine, nil
@@ -106,19 +126,45 @@ func NewSwapEngine(db *gorm.DB, cfg *util.Config, bscClient, ethClient, maticCli
 func (engine *SwapEngine) Start() {
 	go engine.monitorSwapRequestDaemon()
 	go engine.confirmSwapRequestDaemon()
-	go engine.swapInstanceDaemon(SwapEth2BSC, SwapMATIC2BSC)
-	go engine.swapInstanceDaemon(SwapBSC2Eth, SwapMATIC2Eth)
-	go engine.swapInstanceDaemon(SwapBSC2MATIC, SwapEth2MATIC)
+	for _, chain := range engine.chains {
+		engine.startSwapWorkers(chain)
+	}
 	go engine.trackSwapTxDaemon()
+	go engine.confirmSwapFilledDaemon()
 	go engine.retryFailedSwapsDaemon()
 	go engine.trackRetrySwapTxDaemon()
+	go engine.refundDaemon()
+	go engine.trackRefundTxDaemon()
+	go engine.balanceMonitorDaemon()
+}
+
+// getChainByName returns the chain instance with the given name, the name is case insensitive
+func (engine *SwapEngine) getChainByName(name string) (*ChainIns, error) {
+	chainCfg := engine.config.ChainConfig.GetChain(name)
+	if chainCfg == nil {
+		return nil, fmt.Errorf("unknown chain: %s", name)
+	}
+	return engine.chains[chainCfg.Name], nil
+}
+
+// getChainByChainId returns the chain instance which declares the given chain id
+func (engine *SwapEngine) getChainByChainId(chainId string) (*ChainIns, error) {
+	id, err := strconv.ParseInt(chainId, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid chainId: %s", chainId)
+	}
+	chainCfg := engine.config.ChainConfig.GetChainByChainId(id)
+	if chainCfg == nil {
+		return nil, fmt.Errorf("unknown chainId: %s", chainId)
+	}
+	return engine.chains[chainCfg.Name], nil
 }
 
 func (engine *SwapEngine) monitorSwapRequestDaemon() {
 	for {
 		// fmt.Printf("monitorSwapRequestDaemon start 0\n")
 		swapStartTxLogs := make([]model.SwapStartTxLog, 0)
-		engine.db.Where("phase = ?", model.SeenRequest).Order("height asc").Limit(BatchSize).Find(&swapStartTxLogs)
+		engine.db.Where("phase = ?", model.SeenRequest).Order("height asc, log_index asc").Limit(BatchSize).Find(&swapStartTxLogs)
 
 		if len(swapStartTxLogs) == 0 {
 			time.Sleep(SleepTime * time.Second)
@@ -126,17 +172,24 @@ func (engine *SwapEngine) monitorSwapRequestDaemon() {
 		}
 		fmt.Printf("monitorSwapRequestDaemon start 1\n")
 		for _, swapEventLog := range swapStartTxLogs {
-			swap := engine.createSwap(&swapEventLog)
+			swap, refundable := engine.createSwap(&swapEventLog)
 			writeDBErr := func() error {
 				tx := engine.db.Begin()
 				if err := tx.Error; err != nil {
 					return err
 				}
+				swap.SwapId = newSwapId(&swapEventLog)
 				if err := engine.insertSwap(tx, swap); err != nil {
 					tx.Rollback()
 					return err
 				}
-				tx.Model(model.SwapStartTxLog{}).Where("tx_hash = ?", swap.StartTxHash).Updates(
+				if refundable {
+					if err := engine.refundRejectedSwap(tx, &swapEventLog, swap); err != nil {
+						tx.Rollback()
+						return err
+					}
+				}
+				tx.Model(model.SwapStartTxLog{}).Where("id = ?", swapEventLog.Id).Updates(
 					map[string]interface{}{
 						"phase":       model.ConfirmRequest,
 						"update_time": time.Now().Unix(),
@@ -156,14 +209,34 @@ func (engine *SwapEngine) monitorSwapRequestDaemon() {
 func (engine *SwapEngine) getSwapHMAC(swap *model.Swap) string {
 	material := fmt.Sprintf("%s#%s#%s#%s#%s#%s#%d#%s#%s#%s",
 		swap.Status, swap.Sponsor, swap.BEP20Addr, swap.ERC20Addr, swap.Symbol, swap.Amount, swap.Decimals, swap.Direction, swap.StartTxHash, swap.FillTxHash)
+	// appended only when set, so the hashes of swaps created before swaps carry tokens stay valid
+	if swap.ToTokenAddr != "" {
+		material = fmt.Sprintf("%s#%s", material, swap.ToTokenAddr)
+	}
+	if swap.SwapId != "" {
+		material = fmt.Sprintf("%s#%d#%s", material, swap.StartLogIndex, swap.SwapId)
+	}
+	if swap.VelocityReleased {
+		material = fmt.Sprintf("%s#velocity_released", material)
+	}
+	if swap.Approved {
+		material = fmt.Sprintf("%s#approved", material)
+	}
 	mac := hmac.New(sha256.New, []byte(engine.hmacCKey))
 	mac.Write([]byte(material))
 
 	return hex.EncodeToString(mac.Sum(nil))
 }
 
+// verifySwap checks the record hash of the swap, the swaps are only written by the engine so a mismatch pauses all
+// fills until the admin resumes them. The pause is written in the background as the caller may hold a db transaction
 func (engine *SwapEngine) verifySwap(swap *model.Swap) bool {
-	return swap.RecordHash == engine.getSwapHMAC(swap)
+	if swap.RecordHash == engine.getSwapHMAC(swap) {
+		return true
+	}
+	go engine.tripPause(common.PauseScopeGlobal, "", fmt.Sprintf("verify hmac of swap failed, swap ID %d, start tx hash %s", swap.ID, swap.StartTxHash),
+		common.PausedByHMAC)
+	return false
 }
 
 func (engine *SwapEngine) insertSwap(tx *gorm.DB, swap *model.Swap) error {
@@ -176,47 +249,48 @@ func (engine *SwapEngine) updateSwap(tx *gorm.DB, swap *model.Swap) {
 	tx.Save(swap)
 }
 
-func (engine *SwapEngine) createSwap(txEventLog *model.SwapStartTxLog) *model.Swap {
+// createSwap builds the swap of the start event, refundable is true if the swap is rejected for its swap pair quote
+func (engine *SwapEngine) createSwap(txEventLog *model.SwapStartTxLog) (swap *model.Swap, refundable bool) {
 	sponsor := txEventLog.FromAddress
 	amount := txEventLog.Amount
 	toChainId := txEventLog.ToChainId
 	swapStartTxHash := txEventLog.TxHash
-	swapDirection := SwapBSC2Eth
-
-	if txEventLog.Chain == common.ChainBSC {
-		if toChainId == "1" || toChainId == "4" {
-			swapDirection = SwapBSC2Eth
-		} else {
-			swapDirection = SwapBSC2MATIC
-		}
-	} else if txEventLog.Chain == common.ChainETH {
-		if toChainId == "56" || toChainId == "97" {
-			swapDirection = SwapEth2BSC
-		} else {
-			swapDirection = SwapEth2MATIC
-		}
-	} else if txEventLog.Chain == common.ChainMATIC {
-		if toChainId == "56" || toChainId == "97" {
-			swapDirection = SwapMATIC2BSC
-		} else {
-			swapDirection = SwapMATIC2Eth
-		}
-	}
+	var swapDirection common.SwapDirection
 
 	fmt.Printf("createSwap(1): %s\n", sponsor)
 
 	var bep20Addr ethcom.Address
 	var erc20Addr ethcom.Address
+	var toTokenAddr ethcom.Address
 	var ok bool
 	decimals := 0
 	var symbol string
 	swapStatus := SwapQuoteRejected
 	err := func() error {
+		direction, err := engine.directionResolver.Resolve(txEventLog.Chain, toChainId)
+		if err != nil {
+			return err
+		}
+		swapDirection = direction
+
+		swapPairInstance, destTokenAddr, err := engine.resolveSwapToken(ethcom.HexToAddress(txEventLog.TokenAddr))
+		if err != nil {
+			return err
+		}
+		bep20Addr = swapPairInstance.BEP20Addr
+		erc20Addr = swapPairInstance.ERC20Addr
+		toTokenAddr = destTokenAddr
+		symbol = swapPairInstance.Symbol
+		decimals = swapPairInstance.Decimals
+
 		swapAmount := big.NewInt(0)
 		_, ok = swapAmount.SetString(txEventLog.Amount, 10)
 		if !ok {
 			return fmt.Errorf("unrecongnized swap amount: %s", txEventLog.Amount)
 		}
+		if err := checkSwapQuote(swapPairInstance, swapAmount); err != nil {
+			return err
+		}
 
 		swapStatus = SwapTokenReceived
 		return nil
@@ -225,26 +299,29 @@ func (engine *SwapEngine) createSwap(txEventLog *model.SwapStartTxLog) *model.Sw
 	log := ""
 	if err != nil {
 		log = err.Error()
+		util.Logger.Errorf("reject swap, chain %s, start tx hash %s, reason: %s", txEventLog.Chain, swapStartTxHash, log)
 	}
 
 	fmt.Printf("createSwap(2): %s, %s, %s, %s, %s\n", sponsor, swapDirection, amount, toChainId, swapStatus)
 
-	swap := &model.Swap{
-		Status:      swapStatus,
-		Sponsor:     sponsor,
-		ToChainId:   toChainId,
-		BEP20Addr:   bep20Addr.String(),
-		ERC20Addr:   erc20Addr.String(),
-		Symbol:      symbol,
-		Amount:      amount,
-		Decimals:    decimals,
-		Direction:   swapDirection,
-		StartTxHash: swapStartTxHash,
-		FillTxHash:  "",
-		Log:         log,
-	}
-
-	return swap
+	swap = &model.Swap{
+		Status:        swapStatus,
+		Sponsor:       sponsor,
+		ToChainId:     toChainId,
+		BEP20Addr:     bep20Addr.String(),
+		ERC20Addr:     erc20Addr.String(),
+		ToTokenAddr:   toTokenAddr.String(),
+		Symbol:        symbol,
+		Amount:        amount,
+		Decimals:      decimals,
+		Direction:     swapDirection,
+		StartTxHash:   swapStartTxHash,
+		StartLogIndex: txEventLog.LogIndex,
+		FillTxHash:    "",
+		Log:           log,
+	}
+
+	return swap, errors.Is(err, errSwapQuoteRejected)
 }
 
 func (engine *SwapEngine) confirmSwapRequestDaemon() {
@@ -260,21 +337,37 @@ func (engine *SwapEngine) confirmSwapRequestDaemon() {
 
 		util.Logger.Debugf("found %d confirmed event logs", len(txEventLogs))
 
+		screenFailed := false
 		for _, txEventLog := range txEventLogs {
+			// a swap which can not be screened is not confirmed, it is screened again in the next round
+			blockReason, err := engine.screenSwap(&txEventLog)
+			if err != nil {
+				screenFailed = true
+				util.Logger.Errorf("screen swap error, start tx hash %s: %s", txEventLog.TxHash, err.Error())
+				util.SendTelegramMessage(fmt.Sprintf("Urgent alert: screen swap error, start tx hash %s: %s", txEventLog.TxHash, err.Error()))
+				continue
+			}
+
+			var blockedSwap *model.Swap
 			writeDBErr := func() error {
 				tx := engine.db.Begin()
 				if err := tx.Error; err != nil {
 					return err
 				}
 				fmt.Printf("confirmSwapRequestDaemon start 0\n")
-				swap, err := engine.getSwapByStartTxHash(tx, txEventLog.TxHash)
+				swap, err := engine.getSwapByStartTx(tx, txEventLog.TxHash, txEventLog.LogIndex)
 				if err != nil {
 					util.Logger.Errorf("verify hmac of swap failed: %s", txEventLog.TxHash)
 					util.SendTelegramMessage(fmt.Sprintf("Urgent alert: verify hmac of swap failed: %s", txEventLog.TxHash))
 					return err
 				}
 				fmt.Printf("confirmSwapRequestDaemon start 1\n")
-				if swap.Status == SwapTokenReceived {
+				if swap.Status == SwapTokenReceived && blockReason != "" {
+					swap.Status = SwapBlocked
+					swap.Log = fmt.Sprintf("blocked by screening: %s", blockReason)
+					engine.updateSwap(tx, swap)
+					blockedSwap = swap
+				} else if swap.Status == SwapTokenReceived {
 					swap.Status = SwapConfirmed
 					engine.updateSwap(tx, swap)
 					fmt.Printf("confirmSwapRequestDaemon start 11\n")
@@ -291,166 +384,20 @@ func (engine *SwapEngine) confirmSwapRequestDaemon() {
 			if writeDBErr != nil {
 				util.Logger.Errorf("write db error: %s", writeDBErr.Error())
 				util.SendTelegramMessage(fmt.Sprintf("write db error: %s", writeDBErr.Error()))
+			} else if blockedSwap != nil {
+				engine.alertBlockedSwap(blockedSwap)
 			}
 			fmt.Printf("confirmSwapRequestDaemon start final\n")
 		}
-	}
-}
-
-func (engine *SwapEngine) swapInstanceDaemon(direction1, direction2 common.SwapDirection) {
-	util.Logger.Infof("start swap daemon, direction %s", direction1, direction2)
-	for {
-
-		swaps := make([]model.Swap, 0)
-		engine.db.Where("status in (?) and (direction = ? or direction = ?)", []common.SwapStatus{SwapConfirmed, SwapSending}, direction1, direction2).Order("id asc").Limit(BatchSize).Find(&swaps)
-		if len(swaps) == 0 {
-			time.Sleep(SwapSleepSecond * time.Second)
-			continue
-		}
-
-		util.Logger.Debugf("found %d confirmed swap requests", len(swaps))
-
-		for _, swap := range swaps {
-			var swapPairInstance *SwapPairIns
-			// var err error
-			retryCheckErr := func() error {
-				if !engine.verifySwap(&swap) {
-					return fmt.Errorf("verify hmac of swap failed: %s", swap.StartTxHash)
-				}
-				fmt.Printf("swapInstanceDaemon start 1\n")
-				return nil
-			}()
-			if retryCheckErr != nil {
-				writeDBErr := func() error {
-					tx := engine.db.Begin()
-					if err := tx.Error; err != nil {
-						return err
-					}
-					swap.Status = SwapQuoteRejected
-					swap.Log = retryCheckErr.Error()
-					engine.updateSwap(tx, &swap)
-					return tx.Commit().Error
-				}()
-				if writeDBErr != nil {
-					util.Logger.Errorf("write db error: %s", writeDBErr.Error())
-					util.SendTelegramMessage(fmt.Sprintf("write db error: %s", writeDBErr.Error()))
-				}
-				continue
-			}
-			fmt.Printf("swapInstanceDaemon start 2\n")
-			skip, writeDBErr := func() (bool, error) {
-				isSkip := false
-				tx := engine.db.Begin()
-				if err := tx.Error; err != nil {
-					return false, err
-				}
-				if swap.Status == SwapSending {
-					var swapTx model.SwapFillTx
-					engine.db.Where("start_swap_tx_hash = ?", swap.StartTxHash).First(&swapTx)
-					fmt.Printf("swapInstanceDaemon start 3\n")
-					if swapTx.FillSwapTxHash == "" {
-						util.Logger.Infof("retry swap, start tx hash %s, symbol %s, amount %s, direction %s",
-							swap.StartTxHash, swap.Symbol, swap.Amount, swap.Direction)
-						swap.Status = SwapConfirmed
-						engine.updateSwap(tx, &swap)
-					} else {
-						util.Logger.Infof("swap tx is built successfully, but the swap tx status is uncertain, just mark the swap and swap tx status as sent, swap ID %d", swap.ID)
-						tx.Model(model.SwapFillTx{}).Where("fill_swap_tx_hash = ?", swapTx.FillSwapTxHash).Updates(
-							map[string]interface{}{
-								"status":     model.FillTxSent,
-								"updated_at": time.Now().Unix(),
-							})
-						fmt.Printf("swapInstanceDaemon start 4\n")
-						swap.Status = SwapSent
-						swap.FillTxHash = swapTx.FillSwapTxHash
-						engine.updateSwap(tx, &swap)
-
-						isSkip = true
-					}
-				} else {
-					fmt.Printf("swapInstanceDaemon start 5\n")
-					swap.Status = SwapSending
-					engine.updateSwap(tx, &swap)
-				}
-				return isSkip, tx.Commit().Error
-			}()
-			fmt.Printf("swapInstanceDaemon start 6\n")
-			if writeDBErr != nil {
-				util.Logger.Errorf("write db error: %s", writeDBErr.Error())
-				util.SendTelegramMessage(fmt.Sprintf("write db error: %s", writeDBErr.Error()))
-				continue
-			}
-			if skip {
-				util.Logger.Debugf("skip this swap, start tx hash %s", swap.StartTxHash)
-				continue
-			}
-			fmt.Printf("swapInstanceDaemon start 7\n")
-			util.Logger.Infof("Swap token %s, direction %s, sponsor: %s, amount %s, decimals %d", swap.BEP20Addr, swap.Direction, swap.Sponsor, swap.Amount, swap.Decimals)
-			swapTx, swapErr := engine.doSwap(&swap, swapPairInstance)
-
-			writeDBErr = func() error {
-				tx := engine.db.Begin()
-				if err := tx.Error; err != nil {
-					return err
-				}
-				if swapErr != nil {
-					util.Logger.Errorf("do swap failed: %s, start hash %s", swapErr.Error(), swap.StartTxHash)
-					util.SendTelegramMessage(fmt.Sprintf("do swap failed: %s, start hash %s", swapErr.Error(), swap.StartTxHash))
-					if swapErr.Error() == core.ErrReplaceUnderpriced.Error() {
-						//delete the fill swap tx
-						tx.Where("fill_swap_tx_hash = ?", swapTx.FillSwapTxHash).Delete(model.SwapFillTx{})
-						// retry this swap
-						swap.Status = SwapConfirmed
-						swap.Log = fmt.Sprintf("do swap failure: %s", swapErr.Error())
-
-						engine.updateSwap(tx, &swap)
-					} else {
-						fillTxHash := ""
-						if swapTx != nil {
-							tx.Model(model.SwapFillTx{}).Where("fill_swap_tx_hash = ?", swapTx.FillSwapTxHash).Updates(
-								map[string]interface{}{
-									"status":     model.FillTxFailed,
-									"updated_at": time.Now().Unix(),
-								})
-							fillTxHash = swapTx.FillSwapTxHash
-						}
-
-						swap.Status = SwapSendFailed
-						swap.FillTxHash = fillTxHash
-						swap.Log = fmt.Sprintf("do swap failure: %s", swapErr.Error())
-						engine.updateSwap(tx, &swap)
-					}
-				} else {
-					tx.Model(model.SwapFillTx{}).Where("fill_swap_tx_hash = ?", swapTx.FillSwapTxHash).Updates(
-						map[string]interface{}{
-							"status":     model.FillTxSent,
-							"updated_at": time.Now().Unix(),
-						})
-
-					swap.Status = SwapSent
-					swap.FillTxHash = swapTx.FillSwapTxHash
-					engine.updateSwap(tx, &swap)
-				}
-
-				return tx.Commit().Error
-			}()
-			fmt.Printf("swapInstanceDaemon start doSwap\n")
-			if writeDBErr != nil {
-				util.Logger.Errorf("write db error: %s", writeDBErr.Error())
-				util.SendTelegramMessage(fmt.Sprintf("write db error: %s", writeDBErr.Error()))
-			}
-
-			if swap.Direction == SwapEth2BSC {
-				time.Sleep(time.Duration(engine.config.ChainConfig.BSCWaitMilliSecBetweenSwaps) * time.Millisecond)
-			} else {
-				time.Sleep(time.Duration(engine.config.ChainConfig.ETHWaitMilliSecBetweenSwaps) * time.Millisecond)
-			}
+		// do not query the screeners again right away while they are failing
+		if screenFailed {
+			time.Sleep(SleepTime * time.Second)
 		}
-		fmt.Printf("swapInstanceDaemon start final\n")
 	}
 }
 
-func (engine *SwapEngine) doSwap(swap *model.Swap, swapPairInstance *SwapPairIns) (*model.SwapFillTx, error) {
+// encodeFillSwap returns the input of the fillSwap call which pays out the swap
+func (engine *SwapEngine) encodeFillSwap(swap *model.Swap) ([]byte, error) {
 	amount := big.NewInt(0)
 	_, ok := amount.SetString(swap.Amount, 10)
 	toChainId := big.NewInt(0)
@@ -461,96 +408,114 @@ func (engine *SwapEngine) doSwap(swap *model.Swap, swapPairInstance *SwapPairIns
 	if !okk {
 		return nil, fmt.Errorf("invalid chainId: %s", swap.ToChainId)
 	}
+	return abiEncodeFillSwap(getSwapId(swap.SwapId, swap.StartTxHash), toChainId, ethcom.HexToAddress(swap.Sponsor), amount, engine.swapAgentABI)
+}
 
-	if swap.Direction == SwapEth2BSC || swap.Direction == SwapMATIC2BSC {
-		bscClientMutex.Lock()
-		defer bscClientMutex.Unlock()
-		data, err := abiEncodeFillSwap(toChainId, ethcom.HexToAddress(swap.Sponsor), amount, engine.swapAgentABI)
-		if err != nil {
-			return nil, err
-		}
-		signedTx, err := buildSignedTransaction(engine.bscSwapAgent, engine.bscClient, data, engine.bscPrivateKey, toChainId)
-		if err != nil {
-			return nil, err
-		}
-		swapTx := &model.SwapFillTx{
-			Direction:       swap.Direction,
-			StartSwapTxHash: swap.StartTxHash,
-			FillSwapTxHash:  signedTx.Hash().String(),
-			GasPrice:        signedTx.GasPrice().String(),
-			Status:          model.FillTxCreated,
-		}
-		err = engine.insertSwapTxToDB(swapTx)
-		if err != nil {
-			return nil, err
-		}
-		err = engine.bscClient.SendTransaction(context.Background(), signedTx)
-		if err != nil {
-			util.Logger.Errorf("broadcast tx to BSC error: %s", err.Error())
-			return nil, err
-		}
-		util.Logger.Infof("Send transaction to BSC, %s/%s", engine.config.ChainConfig.BSCExplorerUrl, signedTx.Hash().String())
-		return swapTx, nil
-	} else if swap.Direction == SwapBSC2Eth || swap.Direction == SwapMATIC2Eth {
-		ethClientMutex.Lock()
-		defer ethClientMutex.Unlock()
-		data, err := abiEncodeFillSwap(toChainId, ethcom.HexToAddress(swap.Sponsor), amount, engine.swapAgentABI)
-		if err != nil {
-			return nil, err
-		}
-		signedTx, err := buildSignedTransaction(engine.ethSwapAgent, engine.ethClient, data, engine.ethPrivateKey, toChainId)
-		if err != nil {
-			return nil, err
-		}
-		swapTx := &model.SwapFillTx{
-			Direction:       swap.Direction,
-			StartSwapTxHash: swap.StartTxHash,
-			GasPrice:        signedTx.GasPrice().String(),
-			FillSwapTxHash:  signedTx.Hash().String(),
-			Status:          model.FillTxCreated,
-		}
-		err = engine.insertSwapTxToDB(swapTx)
-		if err != nil {
-			return nil, err
-		}
-		err = engine.ethClient.SendTransaction(context.Background(), signedTx)
-		if err != nil {
-			util.Logger.Errorf("broadcast tx to ETH error: %s", err.Error())
-			return nil, err
-		} else {
-			util.Logger.Infof("Send transaction to ETH, %s/%s", engine.config.ChainConfig.ETHExplorerUrl, signedTx.Hash().String())
-		}
-		return swapTx, nil
-	} else {
-		maticClientMutex.Lock()
-		defer maticClientMutex.Unlock()
-		data, err := abiEncodeFillSwap(toChainId, ethcom.HexToAddress(swap.Sponsor), amount, engine.swapAgentABI)
-		if err != nil {
-			return nil, err
-		}
-		signedTx, err := buildSignedTransaction(engine.maticSwapAgent, engine.maticClient, data, engine.maticPrivateKey, toChainId)
-		if err != nil {
-			return nil, err
-		}
-		swapTx := &model.SwapFillTx{
-			Direction:       swap.Direction,
-			StartSwapTxHash: swap.StartTxHash,
-			FillSwapTxHash:  signedTx.Hash().String(),
-			GasPrice:        signedTx.GasPrice().String(),
-			Status:          model.FillTxCreated,
-		}
-		err = engine.insertSwapTxToDB(swapTx)
-		if err != nil {
-			return nil, err
-		}
-		err = engine.maticClient.SendTransaction(context.Background(), signedTx)
-		if err != nil {
-			util.Logger.Errorf("broadcast tx to MATIC error: %s", err.Error())
-			return nil, err
+// checkDestinationToken makes sure the swap agent of the destination chain pays out the token resolved for the swap,
+// fillSwap takes no token argument and the agent pays out the token it configures for the chain id
+func (engine *SwapEngine) checkDestinationToken(chain *ChainIns, toChainId string, toTokenAddr string) error {
+	// swaps created before swaps carry tokens
+	if toTokenAddr == "" {
+		return nil
+	}
+	chainId := big.NewInt(0)
+	if _, ok := chainId.SetString(toChainId, 10); !ok {
+		return fmt.Errorf("invalid chainId: %s", toChainId)
+	}
+	data, err := engine.swapAgentABI.Pack("tokenAddresses", chainId)
+	if err != nil {
+		return err
+	}
+	result, err := chain.Client.CallContract(context.Background(), ethereum.CallMsg{To: &chain.SwapAgent, Data: data}, nil)
+	if err != nil {
+		return err
+	}
+	values, err := engine.swapAgentABI.Unpack("tokenAddresses", result)
+	if err != nil {
+		return err
+	}
+	agentToken, ok := values[0].(ethcom.Address)
+	if !ok || agentToken != ethcom.HexToAddress(toTokenAddr) {
+		return fmt.Errorf("swap agent of %s pays out token %s instead of %s", chain.Name, agentToken.String(), toTokenAddr)
+	}
+	return nil
+}
+
+func (engine *SwapEngine) doSwap(swap *model.Swap, swapPairInstance *SwapPairIns) (*model.SwapFillTx, error) {
+	fillLog, err := engine.getConfirmedSwapFill(swap.StartTxHash, swap.StartLogIndex, swap.SwapId)
+	if err != nil {
+		return nil, err
+	}
+	if fillLog != nil {
+		return nil, fmt.Errorf("%w, chain %s, fill tx hash %s", errSwapAlreadyFilled, fillLog.Chain, fillLog.TxHash)
+	}
+	if err := engine.checkSwapNotRefunded(swap.StartTxHash, swap.StartLogIndex, swap.SwapId); err != nil {
+		return nil, err
+	}
+
+	data, err := engine.encodeFillSwap(swap)
+	if err != nil {
+		return nil, err
+	}
+
+	chain, err := engine.getChainByChainId(swap.ToChainId)
+	if err != nil {
+		return nil, err
+	}
+	if err := engine.checkDestinationToken(chain, swap.ToChainId, swap.ToTokenAddr); err != nil {
+		return nil, err
+	}
+
+	// the fills are sent by several workers at once, the nonce manager keeps their nonces apart
+	height, err := chain.Client.BlockNumber(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := chain.nonceManager.Reserve()
+	if err != nil {
+		return nil, err
+	}
+	signedTx, err := buildSignedTransaction(chain, chain.SwapAgent, data, nonce)
+	if err != nil {
+		chain.nonceManager.Release(nonce)
+		return nil, err
+	}
+	swapTx := &model.SwapFillTx{
+		Chain:           chain.Name,
+		Direction:       swap.Direction,
+		StartSwapTxHash: swap.StartTxHash,
+		StartLogIndex:   swap.StartLogIndex,
+		SwapId:          getSwapId(swap.SwapId, swap.StartTxHash),
+		FillSwapTxHash:  signedTx.Hash().String(),
+		Nonce:           nonce,
+		GasPrice:        signedTx.GasPrice().String(),
+		GasTipCap:       gasTipCap(signedTx),
+		Status:          model.FillTxCreated,
+		SentHeight:      int64(height),
+	}
+	err = engine.insertSwapTxToDB(swapTx)
+	if err != nil {
+		chain.nonceManager.Release(nonce)
+		return nil, err
+	}
+	err = chain.Client.SendTransaction(context.Background(), signedTx)
+	if errors.Is(err, provider.ErrSendUncertain) {
+		// the tx may be relayed, its nonce must not be handed out again until the chain tells otherwise
+		util.Logger.Errorf("broadcast tx to %s is uncertain: %s", chain.Name, err.Error())
+		chain.nonceManager.Commit(nonce)
+		return swapTx, err
+	}
+	if err != nil {
+		util.Logger.Errorf("broadcast tx to %s error: %s", chain.Name, err.Error())
+		chain.nonceManager.Release(nonce)
+		if reconcileErr := chain.nonceManager.Reconcile(); reconcileErr != nil {
+			util.Logger.Errorf("reconcile nonce of %s error: %s", chain.Name, reconcileErr.Error())
 		}
-		util.Logger.Infof("Send transaction to MATIC, %s/%s", engine.config.ChainConfig.MATICExplorerUrl, signedTx.Hash().String())
-		return swapTx, nil
+		return swapTx, err
 	}
+	chain.nonceManager.Commit(nonce)
+	util.Logger.Infof("Send transaction to %s, %s/%s", chain.Name, chain.Config.ExplorerUrl, signedTx.Hash().String())
+	return swapTx, nil
 }
 
 func (engine *SwapEngine) trackSwapTxDaemon() {
@@ -558,54 +523,50 @@ func (engine *SwapEngine) trackSwapTxDaemon() {
 		for {
 			time.Sleep(SleepTime * time.Second)
 
-			swapTxs := make([]model.SwapFillTx, 0)
-			engine.db.Where("status = ? and track_retry_counter >= ?", model.FillTxSent, engine.config.ChainConfig.ETHMaxTrackRetry).
-				Order("id asc").Limit(TrackSentTxBatchSize).Find(&swapTxs)
+			for _, chain := range engine.chains {
+				swapTxs := make([]model.SwapFillTx, 0)
+				engine.db.Where("status = ? and chain = ? and track_retry_counter >= ?", model.FillTxSent, chain.Name, chain.Config.MaxTrackRetry).
+					Order("id asc").Limit(TrackSentTxBatchSize).Find(&swapTxs)
 
-			if len(swapTxs) > 0 {
-				util.Logger.Infof("%d fill tx are missing, mark these swaps as failed", len(swapTxs))
-			}
-
-			for _, swapTx := range swapTxs {
-				chainName := "ETH"
-				maxRetry := engine.config.ChainConfig.ETHMaxTrackRetry
-				if swapTx.Direction == SwapEth2BSC || swapTx.Direction == SwapMATIC2BSC {
-					chainName = "BSC"
-					maxRetry = engine.config.ChainConfig.BSCMaxTrackRetry
-				}
-				if swapTx.Direction == SwapBSC2MATIC || swapTx.Direction == SwapEth2MATIC {
-					chainName = "MATIC"
-					maxRetry = engine.config.ChainConfig.MATICMaxTrackRetry
+				if len(swapTxs) > 0 {
+					util.Logger.Infof("%d fill tx are missing on %s, mark these swaps as fill missing", len(swapTxs), chain.Name)
 				}
-				util.Logger.Errorf("The fill tx is sent, however, after %d seconds its status is still uncertain. Mark tx as missing and mark swap as failed, chain %s, fill hash %s", SleepTime*maxRetry, chainName, swapTx.StartSwapTxHash)
-				util.SendTelegramMessage(fmt.Sprintf("The fill tx is sent, however, after %d seconds its status is still uncertain. Mark tx as missing and mark swap as failed, chain %s, start hash %s", SleepTime*maxRetry, chainName, swapTx.StartSwapTxHash))
 
-				writeDBErr := func() error {
-					tx := engine.db.Begin()
-					if err := tx.Error; err != nil {
-						return err
-					}
-					tx.Model(model.SwapFillTx{}).Where("id = ?", swapTx.ID).Updates(
-						map[string]interface{}{
-							"status":     model.FillTxMissing,
-							"updated_at": time.Now().Unix(),
-						})
-
-					swap, err := engine.getSwapByStartTxHash(tx, swapTx.StartSwapTxHash)
-					if err != nil {
-						tx.Rollback()
-						return err
-					}
-					swap.Status = SwapSendFailed
-					swap.Log = fmt.Sprintf("track fill tx for more than %d times, the fill tx status is still uncertain", maxRetry)
-					engine.updateSwap(tx, swap)
+				for _, swapTx := range swapTxs {
+					maxRetry := chain.Config.MaxTrackRetry
+					util.Logger.Errorf("The fill tx is sent, however, after %d seconds its status is still uncertain. Mark tx as missing and wait for the chain to settle the swap, chain %s, fill hash %s", SleepTime*maxRetry, chain.Name, swapTx.FillSwapTxHash)
+					util.SendTelegramMessage(fmt.Sprintf("The fill tx is sent, however, after %d seconds its status is still uncertain. Mark tx as missing and wait for the chain to settle the swap, chain %s, start hash %s", SleepTime*maxRetry, chain.Name, swapTx.StartSwapTxHash))
+
+					writeDBErr := func() error {
+						tx := engine.db.Begin()
+						if err := tx.Error; err != nil {
+							return err
+						}
+						tx.Model(model.SwapFillTx{}).Where("id = ?", swapTx.ID).Updates(
+							map[string]interface{}{
+								"status":     model.FillTxMissing,
+								"updated_at": time.Now().Unix(),
+							})
 
-					return tx.Commit().Error
-				}()
-				if writeDBErr != nil {
-					util.Logger.Errorf("write db error: %s", writeDBErr.Error())
-					util.SendTelegramMessage(fmt.Sprintf("write db error: %s", writeDBErr.Error()))
+						swap, err := engine.getSwapByStartTx(tx, swapTx.StartSwapTxHash, swapTx.StartLogIndex)
+						if err != nil {
+							tx.Rollback()
+							return err
+						}
+						// the fill tx may still be mined, the swap is settled once the chain tells
+						swap.Status = SwapFillMissing
+						swap.Log = fmt.Sprintf("track fill tx for more than %d times, the fill tx status is still uncertain", maxRetry)
+						engine.updateSwap(tx, swap)
+
+						return tx.Commit().Error
+					}()
+					if writeDBErr != nil {
+						util.Logger.Errorf("write db error: %s", writeDBErr.Error())
+						util.SendTelegramMessage(fmt.Sprintf("write db error: %s", writeDBErr.Error()))
+					}
 				}
+
+				engine.resolveMissingFillTxs(chain)
 			}
 		}
 	}()
@@ -614,124 +575,257 @@ func (engine *SwapEngine) trackSwapTxDaemon() {
 		for {
 			time.Sleep(SleepTime * time.Second)
 
-			ethSwapTxs := make([]model.SwapFillTx, 0)
-			engine.db.Where("status = ? and direction = ? and track_retry_counter < ?", model.FillTxSent, SwapBSC2Eth, SwapMATIC2Eth, engine.config.ChainConfig.ETHMaxTrackRetry).
-				Order("id asc").Limit(TrackSentTxBatchSize).Find(&ethSwapTxs)
+			for _, chain := range engine.chains {
+				swapTxs := make([]model.SwapFillTx, 0)
+				engine.db.Where("status = ? and chain = ? and track_retry_counter < ?", model.FillTxSent, chain.Name, chain.Config.MaxTrackRetry).
+					Order("id asc").Limit(TrackSentTxBatchSize).Find(&swapTxs)
 
-			bscSwapTxs := make([]model.SwapFillTx, 0)
-			engine.db.Where("status = ? and direction = ? and track_retry_counter < ?", model.FillTxSent, SwapEth2BSC, SwapMATIC2BSC, engine.config.ChainConfig.BSCMaxTrackRetry).
-				Order("id asc").Limit(TrackSentTxBatchSize).Find(&bscSwapTxs)
-
-			maticSwapTxs := make([]model.SwapFillTx, 0)
-			engine.db.Where("status = ? and direction = ? and track_retry_counter < ?", model.FillTxSent, SwapEth2MATIC, SwapBSC2MATIC, engine.config.ChainConfig.MATICMaxTrackRetry).
-				Order("id asc").Limit(TrackSentTxBatchSize).Find(&maticSwapTxs)
-
-			swapTxs := append(ethSwapTxs, bscSwapTxs...)
-			swapTxs = append(swapTxs, maticSwapTxs...)
-
-			if len(swapTxs) > 0 {
-				util.Logger.Debugf("Track %d non-finalized swap txs", len(swapTxs))
-			}
-
-			for _, swapTx := range swapTxs {
-				gasPrice := big.NewInt(0)
-				gasPrice.SetString(swapTx.GasPrice, 10)
-
-				var client *ethclient.Client
-				chainName := "ETH"
-				client = engine.ethClient
-				if swapTx.Direction == SwapEth2BSC || swapTx.Direction == SwapMATIC2BSC {
-					chainName = "BSC"
-					client = engine.bscClient
+				if len(swapTxs) > 0 {
+					util.Logger.Debugf("Track %d non-finalized swap txs on %s", len(swapTxs), chain.Name)
 				}
-				if swapTx.Direction == SwapBSC2MATIC || swapTx.Direction == SwapEth2MATIC {
-					chainName = "MATIC"
-					client = engine.maticClient
-				}
-				var txRecipient *types.Receipt
-				queryTxStatusErr := func() error {
-					block, err := client.BlockByNumber(context.Background(), nil)
-					if err != nil {
-						util.Logger.Debugf("%s, query block failed: %s", chainName, err.Error())
-						return err
-					}
-					txRecipient, err = client.TransactionReceipt(context.Background(), ethcom.HexToHash(swapTx.FillSwapTxHash))
-					if err != nil {
-						util.Logger.Debugf("%s, query tx failed: %s", chainName, err.Error())
-						return err
-					}
-					if block.Number().Int64() < txRecipient.BlockNumber.Int64()+engine.config.ChainConfig.ETHConfirmNum {
-						return fmt.Errorf("%s, swap tx is still not finalized", chainName)
-					}
-					return nil
-				}()
 
-				writeDBErr := func() error {
-					tx := engine.db.Begin()
-					if err := tx.Error; err != nil {
-						return err
-					}
-					if queryTxStatusErr != nil {
-						tx.Model(model.SwapFillTx{}).Where("id = ?", swapTx.ID).Updates(
-							map[string]interface{}{
-								"track_retry_counter": gorm.Expr("track_retry_counter + 1"),
-								"updated_at":          time.Now().Unix(),
-							})
-					} else {
-						txFee := big.NewInt(1).Mul(gasPrice, big.NewInt(int64(txRecipient.GasUsed))).String()
-						if txRecipient.Status == TxFailedStatus {
-							util.Logger.Infof(fmt.Sprintf("fill swap tx is failed, chain %s, txHash: %s", chainName, txRecipient.TxHash.String()))
-							util.SendTelegramMessage(fmt.Sprintf("fill swap tx is failed, chain %s, txHash: %s", chainName, txRecipient.TxHash.String()))
+				for _, swapTx := range swapTxs {
+					var minedTx *model.SwapFillTx
+					var txRecipient *types.Receipt
+					var baseFee, txFee *big.Int
+					height := int64(0)
+					queryTxStatusErr := func() error {
+						block, err := chain.Client.BlockByNumber(context.Background(), nil)
+						if err != nil {
+							util.Logger.Debugf("%s, query block failed: %s", chain.Name, err.Error())
+							return err
+						}
+						height = block.Number().Int64()
+						// the fill tx may be replaced, any tx of its lineage can be the mined one
+						lineage, err := engine.getFillTxLineage(&swapTx)
+						if err != nil {
+							return err
+						}
+						minedTx, txRecipient, err = findMinedFillTx(chain, lineage)
+						if err != nil {
+							util.Logger.Debugf("%s, query tx failed: %s", chain.Name, err.Error())
+							return err
+						}
+						if txRecipient == nil {
+							return fmt.Errorf("%s, swap tx is not mined yet", chain.Name)
+						}
+						if block.Number().Int64() < txRecipient.BlockNumber.Int64()+chain.Config.ConfirmNum {
+							return fmt.Errorf("%s, swap tx is still not finalized", chain.Name)
+						}
+						baseFee, txFee, err = getFillTxFee(chain, txRecipient)
+						if err != nil {
+							util.Logger.Debugf("%s, query tx fee failed: %s", chain.Name, err.Error())
+							return err
+						}
+						return nil
+					}()
+
+					// the fill result is recorded once it is committed
+					fillTracked := false
+					var fillErr error
+					writeDBErr := func() error {
+						tx := engine.db.Begin()
+						if err := tx.Error; err != nil {
+							return err
+						}
+						if queryTxStatusErr != nil {
 							tx.Model(model.SwapFillTx{}).Where("id = ?", swapTx.ID).Updates(
 								map[string]interface{}{
-									"status":              model.FillTxFailed,
-									"height":              txRecipient.BlockNumber.Int64(),
-									"consumed_fee_amount": txFee,
+									"track_retry_counter": gorm.Expr("track_retry_counter + 1"),
 									"updated_at":          time.Now().Unix(),
 								})
-
-							swap, err := engine.getSwapByStartTxHash(tx, swapTx.StartSwapTxHash)
-							if err != nil {
-								tx.Rollback()
-								return err
-							}
-							swap.Status = SwapSendFailed
-							swap.Log = "fill tx is failed"
-							engine.updateSwap(tx, swap)
 						} else {
-							util.Logger.Infof(fmt.Sprintf("fill swap tx is success, chain %s, txHash: %s", chainName, txRecipient.TxHash.String()))
-							tx.Model(model.SwapFillTx{}).Where("id = ?", swapTx.ID).Updates(
-								map[string]interface{}{
-									"status":              model.FillTxSuccess,
-									"height":              txRecipient.BlockNumber.Int64(),
-									"consumed_fee_amount": txFee,
-									"updated_at":          time.Now().Unix(),
-								})
-
-							swap, err := engine.getSwapByStartTxHash(tx, swapTx.StartSwapTxHash)
+							succeeded, err := engine.settleFillTx(tx, chain, &swapTx, minedTx, txRecipient, baseFee, txFee)
 							if err != nil {
 								tx.Rollback()
 								return err
 							}
-							swap.Status = SwapSuccess
-							engine.updateSwap(tx, swap)
+							fillTracked = true
+							if !succeeded {
+								fillErr = fmt.Errorf("fill tx %s is failed", txRecipient.TxHash.String())
+							}
 						}
+						return tx.Commit().Error
+					}()
+					if writeDBErr != nil {
+						util.Logger.Errorf("update db failure3: %s", writeDBErr.Error())
+						util.SendTelegramMessage(fmt.Sprintf("Upgent alert: update db failure3: %s", writeDBErr.Error()))
+						continue
+					}
+					if fillTracked {
+						engine.recordFillResult(chain, fillErr)
 					}
-					return tx.Commit().Error
-				}()
-				if writeDBErr != nil {
-					util.Logger.Errorf("update db failure3: %s", writeDBErr.Error())
-					util.SendTelegramMessage(fmt.Sprintf("Upgent alert: update db failure3: %s", writeDBErr.Error()))
-				}
 
+					if height > 0 && txRecipient == nil {
+						engine.bumpSwapTx(chain, &swapTx, height)
+					}
+				}
 			}
 		}
 	}()
 }
 
-func (engine *SwapEngine) getSwapByStartTxHash(tx *gorm.DB, txHash string) (*model.Swap, error) {
+// settleFillTx records the result of the finalized fill tx of the swap, swapTx is the tracked tx of the lineage and
+// minedTx the one which is mined. It returns whether the swap is filled
+func (engine *SwapEngine) settleFillTx(tx *gorm.DB, chain *ChainIns, swapTx, minedTx *model.SwapFillTx, txRecipient *types.Receipt, baseFee, txFee *big.Int) (bool, error) {
+	baseFeeStr := ""
+	if baseFee != nil {
+		baseFeeStr = baseFee.String()
+	}
+	if minedTx.ID != swapTx.ID {
+		util.Logger.Infof("fill tx %s on %s is replaced by the mined tx %s", swapTx.FillSwapTxHash, chain.Name, minedTx.FillSwapTxHash)
+		tx.Model(model.SwapFillTx{}).Where("id = ?", swapTx.ID).Updates(
+			map[string]interface{}{
+				"status":     model.FillTxReplaced,
+				"updated_at": time.Now().Unix(),
+			})
+	}
+	swapIdVerified := minedTx.SwapId == "" || engine.verifySwapFilled(chain, txRecipient, minedTx.SwapId)
+	if !swapIdVerified {
+		util.Logger.Errorf("fill swap tx does not emit SwapFilled of swap id %s, chain %s, txHash: %s", minedTx.SwapId, chain.Name, txRecipient.TxHash.String())
+		util.SendTelegramMessage(fmt.Sprintf("Upgent alert: fill swap tx does not emit SwapFilled of swap id %s, chain %s, txHash: %s", minedTx.SwapId, chain.Name, txRecipient.TxHash.String()))
+	}
+
+	swap, err := engine.getSwapByStartTx(tx, swapTx.StartSwapTxHash, swapTx.StartLogIndex)
+	if err != nil {
+		return false, err
+	}
+	swap.FillTxHash = minedTx.FillSwapTxHash
+	if txRecipient.Status == TxFailedStatus || !swapIdVerified {
+		util.Logger.Infof(fmt.Sprintf("fill swap tx is failed, chain %s, txHash: %s", chain.Name, txRecipient.TxHash.String()))
+		util.SendTelegramMessage(fmt.Sprintf("fill swap tx is failed, chain %s, txHash: %s", chain.Name, txRecipient.TxHash.String()))
+		// the nonce is taken by the failed tx, no other tx of the lineage can be mined
+		tx.Model(model.SwapFillTx{}).Where("start_swap_tx_hash = ? and chain = ? and nonce = ? and status in (?)",
+			minedTx.StartSwapTxHash, minedTx.Chain, minedTx.Nonce, []model.FillTxStatus{model.FillTxSent, model.FillTxReplaced, model.FillTxMissing}).
+			Updates(
+				map[string]interface{}{
+					"status":     model.FillTxFailed,
+					"updated_at": time.Now().Unix(),
+				})
+		tx.Model(model.SwapFillTx{}).Where("id = ?", minedTx.ID).Updates(
+			map[string]interface{}{
+				"status":              model.FillTxFailed,
+				"height":              txRecipient.BlockNumber.Int64(),
+				"base_fee":            baseFeeStr,
+				"consumed_fee_amount": txFee.String(),
+				"updated_at":          time.Now().Unix(),
+			})
+
+		swap.Status = SwapSendFailed
+		swap.Log = "fill tx is failed"
+		if !swapIdVerified {
+			swap.Log = fmt.Sprintf("fill tx does not emit SwapFilled of swap id %s", minedTx.SwapId)
+		}
+		engine.updateSwap(tx, swap)
+		return false, nil
+	}
+
+	util.Logger.Infof(fmt.Sprintf("fill swap tx is success, chain %s, txHash: %s", chain.Name, txRecipient.TxHash.String()))
+	tx.Model(model.SwapFillTx{}).Where("id = ?", minedTx.ID).Updates(
+		map[string]interface{}{
+			"status":              model.FillTxSuccess,
+			"height":              txRecipient.BlockNumber.Int64(),
+			"base_fee":            baseFeeStr,
+			"consumed_fee_amount": txFee.String(),
+			"updated_at":          time.Now().Unix(),
+		})
+	swap.Status = SwapSuccess
+	engine.updateSwap(tx, swap)
+	return true, nil
+}
+
+// resolveMissingFillTx settles the missing fill tx by the chain. The swap is settled by the receipt once a tx of the
+// lineage is mined and finalized, and it is failed once the nonce is taken by a finalized tx outside the lineage.
+// Otherwise the fill tx may still be mined and it stays missing
+func (engine *SwapEngine) resolveMissingFillTx(chain *ChainIns, swapTx *model.SwapFillTx) error {
+	head, err := chain.Client.BlockNumber(context.Background())
+	if err != nil {
+		return err
+	}
+	finalized := int64(head) - chain.Config.ConfirmNum
+	if finalized < 0 {
+		return fmt.Errorf("%s has no finalized block", chain.Name)
+	}
+
+	lineage, err := engine.getFillTxLineage(swapTx)
+	if err != nil {
+		return err
+	}
+	minedTx, txRecipient, err := findMinedFillTx(chain, lineage)
+	if err != nil {
+		return err
+	}
+	if txRecipient != nil {
+		if txRecipient.BlockNumber.Int64() > finalized {
+			return fmt.Errorf("fill tx %s is still not finalized", minedTx.FillSwapTxHash)
+		}
+		baseFee, txFee, err := getFillTxFee(chain, txRecipient)
+		if err != nil {
+			return err
+		}
+		tx := engine.db.Begin()
+		if err := tx.Error; err != nil {
+			return err
+		}
+		if _, err := engine.settleFillTx(tx, chain, swapTx, minedTx, txRecipient, baseFee, txFee); err != nil {
+			tx.Rollback()
+			return err
+		}
+		return tx.Commit().Error
+	}
+
+	signer := crypto.PubkeyToAddress(chain.PrivateKey.PublicKey)
+	nonce, err := chain.Client.NonceAt(context.Background(), signer, big.NewInt(finalized))
+	if err != nil {
+		return err
+	}
+	if nonce <= swapTx.Nonce {
+		return fmt.Errorf("fill tx %s is not mined and its nonce %d is not taken yet", swapTx.FillSwapTxHash, swapTx.Nonce)
+	}
+	util.Logger.Infof("fill tx is dropped, chain %s, txHash: %s", chain.Name, swapTx.FillSwapTxHash)
+	util.SendTelegramMessage(fmt.Sprintf("fill tx is dropped, its nonce %d is taken by another tx, chain %s, txHash: %s", swapTx.Nonce, chain.Name, swapTx.FillSwapTxHash))
+
+	tx := engine.db.Begin()
+	if err := tx.Error; err != nil {
+		return err
+	}
+	tx.Model(model.SwapFillTx{}).Where("start_swap_tx_hash = ? and chain = ? and nonce = ? and status in (?)",
+		swapTx.StartSwapTxHash, swapTx.Chain, swapTx.Nonce, []model.FillTxStatus{model.FillTxSent, model.FillTxReplaced, model.FillTxMissing}).
+		Updates(
+			map[string]interface{}{
+				"status":     model.FillTxFailed,
+				"updated_at": time.Now().Unix(),
+			})
+	swap, err := engine.getSwapByStartTx(tx, swapTx.StartSwapTxHash, swapTx.StartLogIndex)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	swap.Status = SwapSendFailed
+	swap.Log = fmt.Sprintf("fill tx is dropped, its nonce %d is taken by another tx", swapTx.Nonce)
+	engine.updateSwap(tx, swap)
+	return tx.Commit().Error
+}
+
+// resolveMissingFillTxs settles the missing fill txs of the swaps waiting for them
+func (engine *SwapEngine) resolveMissingFillTxs(chain *ChainIns) {
+	swapTxs := make([]model.SwapFillTx, 0)
+	engine.db.Where("status = ? and chain = ? and exists (?)", model.FillTxMissing, chain.Name,
+		engine.db.Model(model.Swap{}).Select("id").
+			Where("swaps.start_tx_hash = swap_fill_txs.start_swap_tx_hash and swaps.start_log_index = swap_fill_txs.start_log_index and swaps.status = ?", SwapFillMissing).QueryExpr()).
+		Order("id asc").Limit(TrackSentTxBatchSize).Find(&swapTxs)
+
+	for idx := range swapTxs {
+		if err := engine.resolveMissingFillTx(chain, &swapTxs[idx]); err != nil {
+			util.Logger.Debugf("resolve missing fill tx %s on %s: %s", swapTxs[idx].FillSwapTxHash, chain.Name, err.Error())
+		}
+	}
+}
+
+// getSwapByStartTx returns the swap started by the event of the tx at the log index
+func (engine *SwapEngine) getSwapByStartTx(tx *gorm.DB, txHash string, logIndex uint) (*model.Swap, error) {
 	swap := model.Swap{}
-	err := tx.Where("start_tx_hash = ?", txHash).First(&swap).Error
+	err := tx.Where("start_tx_hash = ? and start_log_index = ?", txHash, logIndex).First(&swap).Error
 	if err != nil {
 		return nil, err
 	}
@@ -775,6 +869,7 @@ func (engine *SwapEngine) AddSwapPairInstance(swapPair *model.SwapPair) error {
 		Decimals:   swapPair.Decimals,
 		LowBound:   lowBound,
 		UpperBound: upperBound,
+		Available:  swapPair.Available,
 		BEP20Addr:  ethcom.HexToAddress(swapPair.BEP20Addr),
 		ERC20Addr:  ethcom.HexToAddress(swapPair.ERC20Addr),
 	}
@@ -786,6 +881,36 @@ func (engine *SwapEngine) AddSwapPairInstance(swapPair *model.SwapPair) error {
 	return nil
 }
 
+// resolveSwapToken returns the swap pair of the deposited token and the token paid out on the destination chain
+func (engine *SwapEngine) resolveSwapToken(tokenAddr ethcom.Address) (*SwapPairIns, ethcom.Address, error) {
+	engine.mutex.RLock()
+	defer engine.mutex.RUnlock()
+
+	if bep20Addr, ok := engine.erc20ToBEP20[tokenAddr]; ok {
+		if swapPairInstance, ok := engine.swapPairsFromERC20Addr[tokenAddr]; ok {
+			return swapPairInstance, bep20Addr, nil
+		}
+	}
+	if erc20Addr, ok := engine.bep20ToERC20[tokenAddr]; ok {
+		if swapPairInstance, ok := engine.swapPairsFromERC20Addr[erc20Addr]; ok {
+			return swapPairInstance, erc20Addr, nil
+		}
+	}
+	return nil, ethcom.Address{}, fmt.Errorf("unsupported token: %s", tokenAddr.String())
+}
+
+// checkSwapQuote returns an error wrapping errSwapQuoteRejected if the swap pair does not accept the amount
+func checkSwapQuote(swapPairInstance *SwapPairIns, amount *big.Int) error {
+	if !swapPairInstance.Available {
+		return fmt.Errorf("%w: swap pair %s is not available", errSwapQuoteRejected, swapPairInstance.Symbol)
+	}
+	if amount.Cmp(swapPairInstance.LowBound) < 0 || amount.Cmp(swapPairInstance.UpperBound) > 0 {
+		return fmt.Errorf("%w: amount %s is out of the bounds [%s, %s] of %s", errSwapQuoteRejected,
+			amount.String(), swapPairInstance.LowBound.String(), swapPairInstance.UpperBound.String(), swapPairInstance.Symbol)
+	}
+	return nil
+}
+
 func (engine *SwapEngine) GetSwapPairInstance(erc20Addr ethcom.Address) (*SwapPairIns, error) {
 	engine.mutex.RLock()
 	defer engine.mutex.RUnlock()
@@ -801,24 +926,22 @@ func (engine *SwapEngine) UpdateSwapInstance(swapPair *model.SwapPair) {
 	engine.mutex.Lock()
 	defer engine.mutex.Unlock()
 
-	bscTokenAddr := ethcom.HexToAddress(swapPair.BEP20Addr)
-	tokenInstance, ok := engine.swapPairsFromERC20Addr[bscTokenAddr]
+	erc20Addr := ethcom.HexToAddress(swapPair.ERC20Addr)
+	tokenInstance, ok := engine.swapPairsFromERC20Addr[erc20Addr]
 	if !ok {
 		return
 	}
 
-	if !swapPair.Available {
-		delete(engine.swapPairsFromERC20Addr, bscTokenAddr)
-		return
-	}
+	// unavailable pairs are kept so swaps of their tokens are rejected with a reason
+	tokenInstance.Available = swapPair.Available
 
 	upperBound := big.NewInt(0)
 	_, ok = upperBound.SetString(swapPair.UpperBound, 10)
 	tokenInstance.UpperBound = upperBound
 
 	lowBound := big.NewInt(0)
-	_, ok = upperBound.SetString(swapPair.LowBound, 10)
+	_, ok = lowBound.SetString(swapPair.LowBound, 10)
 	tokenInstance.LowBound = lowBound
 
-	engine.swapPairsFromERC20Addr[bscTokenAddr] = tokenInstance
+	engine.swapPairsFromERC20Addr[erc20Addr] = tokenInstance
 }
@@ -0,0 +1,214 @@
+package swap
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	ethcom "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/jinzhu/gorm"
+
+	"occ-swap-server/executor"
+	"occ-swap-server/model"
+	"occ-swap-server/util"
+)
+
+// errSwapAlreadyFilled is returned when a confirmed SwapFilled event already pays out the swap
+var errSwapAlreadyFilled = errors.New("swap is already filled on chain")
+
+// getSwapId returns the id passed to fillSwap to pay out the swap, the contract and the SwapFilled event
+// identify the swap by it. The swaps created before swap ids are recorded are identified by their start tx hash
+func getSwapId(swapId, startTxHash string) string {
+	if swapId != "" {
+		return swapId
+	}
+	return ethcom.HexToHash(startTxHash).Hex()
+}
+
+// newSwapId returns the swap id of the swap started by the event, the hash of the tx hash and the log index of the
+// event. It only depends on the event, so the swap gets the same id however often and in whatever order the events
+// are processed, e.g. when they are replayed after a reorg or rescanned
+func newSwapId(txEventLog *model.SwapStartTxLog) string {
+	txHash := ethcom.HexToHash(txEventLog.TxHash)
+	logIndex := ethcom.BigToHash(new(big.Int).SetUint64(uint64(txEventLog.LogIndex)))
+	return crypto.Keccak256Hash(txHash.Bytes(), logIndex.Bytes()).Hex()
+}
+
+// verifySwapFilled checks the receipt of a fill tx emits the SwapFilled event of the swap id, the event is decoded
+// by the latest agent version of the chain
+func (engine *SwapEngine) verifySwapFilled(chain *ChainIns, receipt *types.Receipt, swapId string) bool {
+	for _, log := range receipt.Logs {
+		if log.Address != chain.SwapAgent || len(log.Topics) == 0 || log.Topics[0] != chain.Agent.SwapFilled.ID {
+			continue
+		}
+		event := executor.SwapFilledEvent{}
+		if err := executor.DecodeEvent(chain.Agent.SwapFilled, log, &event); err != nil {
+			continue
+		}
+		if ethcom.BigToHash(event.FromChainId).Hex() == swapId {
+			return true
+		}
+	}
+	return false
+}
+
+// getFillTxHashes returns the hashes of all the fill txs and retry fill txs sent for the swap
+func (engine *SwapEngine) getFillTxHashes(startTxHash string, startLogIndex uint) []string {
+	swapTxs := make([]model.SwapFillTx, 0)
+	engine.db.Where("start_swap_tx_hash = ? and start_log_index = ?", startTxHash, startLogIndex).Find(&swapTxs)
+	retrySwapTxs := make([]model.RetrySwapTx, 0)
+	engine.db.Where("start_tx_hash = ? and start_log_index = ?", startTxHash, startLogIndex).Find(&retrySwapTxs)
+
+	hashes := make([]string, 0, len(swapTxs)+len(retrySwapTxs))
+	for _, swapTx := range swapTxs {
+		hashes = append(hashes, swapTx.FillSwapTxHash)
+	}
+	for _, retrySwapTx := range retrySwapTxs {
+		hashes = append(hashes, retrySwapTx.RetryFillSwapTxHash)
+	}
+	return hashes
+}
+
+// getConfirmedSwapFill returns the confirmed SwapFilled event paying out the swap, it is nil if there is none
+func (engine *SwapEngine) getConfirmedSwapFill(startTxHash string, startLogIndex uint, swapId string) (*model.SwapFilledTxLog, error) {
+	fillLog := model.SwapFilledTxLog{}
+	err := engine.db.Where("((start_tx_hash = ? and start_log_index = ?) or swap_id = ?) and status = ?",
+		startTxHash, startLogIndex, getSwapId(swapId, startTxHash), model.TxStatusConfirmed).
+		First(&fillLog).Error
+	if err == nil {
+		return &fillLog, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	// the event may not be correlated yet, look it up by the fill txs of the swap
+	hashes := engine.getFillTxHashes(startTxHash, startLogIndex)
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+	err = engine.db.Where("tx_hash in (?) and status = ?", hashes, model.TxStatusConfirmed).First(&fillLog).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &fillLog, nil
+}
+
+// correlateSwapFill returns the start tx hash and log index of the swap paid out by the event, the hash is empty if
+// no swap matches
+func (engine *SwapEngine) correlateSwapFill(fillLog *model.SwapFilledTxLog) (string, uint) {
+	swap := model.Swap{}
+	if err := engine.db.Where("swap_id = ? or (swap_id = '' and start_tx_hash = ?)", fillLog.SwapId, fillLog.SwapId).First(&swap).Error; err == nil {
+		return swap.StartTxHash, swap.StartLogIndex
+	}
+
+	// fills sent before swap ids are passed to fillSwap carry no id
+	swapTx := model.SwapFillTx{}
+	if err := engine.db.Where("fill_swap_tx_hash = ? and chain = ?", fillLog.TxHash, fillLog.Chain).First(&swapTx).Error; err == nil {
+		return swapTx.StartSwapTxHash, swapTx.StartLogIndex
+	}
+	retrySwapTx := model.RetrySwapTx{}
+	if err := engine.db.Where("retry_fill_swap_tx_hash = ? and chain = ?", fillLog.TxHash, fillLog.Chain).First(&retrySwapTx).Error; err == nil {
+		return retrySwapTx.StartTxHash, retrySwapTx.StartLogIndex
+	}
+
+	// our own tracking lost the fill tx, match the unfinished swaps paying the same amount to the same address
+	swaps := make([]model.Swap, 0)
+	engine.db.Where("sponsor = ? and amount = ? and to_chain_id = ? and status in (?)",
+		fillLog.ToAddress, fillLog.Amount, fillLog.ToChainId, []string{string(SwapSending), string(SwapSent), string(SwapFillMissing), string(SwapSendFailed)}).
+		Find(&swaps)
+	if len(swaps) != 1 {
+		return "", 0
+	}
+	return swaps[0].StartTxHash, swaps[0].StartLogIndex
+}
+
+// getSwapRefundByFill returns the refund paid by the SwapFilled event, it is nil if the event is not a refund
+func (engine *SwapEngine) getSwapRefundByFill(fillLog *model.SwapFilledTxLog) *model.SwapRefund {
+	refund := model.SwapRefund{}
+	if err := engine.db.Where("refund_tx_hash = ? and chain = ?", fillLog.TxHash, fillLog.Chain).First(&refund).Error; err != nil {
+		return nil
+	}
+	return &refund
+}
+
+// confirmSwapFilledDaemon correlates the confirmed SwapFilled events to swaps and marks the swaps as successful
+func (engine *SwapEngine) confirmSwapFilledDaemon() {
+	for {
+		fillLogs := make([]model.SwapFilledTxLog, 0)
+		engine.db.Where("status = ? and phase = ?", model.TxStatusConfirmed, model.SeenRequest).
+			Order("height asc").Limit(BatchSize).Find(&fillLogs)
+
+		if len(fillLogs) == 0 {
+			time.Sleep(SleepTime * time.Second)
+			continue
+		}
+
+		for _, fillLog := range fillLogs {
+			if refund := engine.getSwapRefundByFill(&fillLog); refund != nil {
+				// refunds pay the sponsor back on the source chain, they are not payouts of the swap
+				engine.db.Model(model.SwapFilledTxLog{}).Where("id = ?", fillLog.Id).Updates(
+					map[string]interface{}{
+						"start_tx_hash":   refund.StartTxHash,
+						"start_log_index": refund.StartLogIndex,
+						"phase":           model.ConfirmRequest,
+						"update_time":     time.Now().Unix(),
+					})
+				continue
+			}
+			startTxHash, startLogIndex := engine.correlateSwapFill(&fillLog)
+			if startTxHash == "" {
+				util.Logger.Errorf("SwapFilled event can not be correlated to any swap, chain %s, tx hash %s", fillLog.Chain, fillLog.TxHash)
+				util.SendTelegramMessage(fmt.Sprintf("SwapFilled event can not be correlated to any swap, chain %s, tx hash %s", fillLog.Chain, fillLog.TxHash))
+			}
+
+			writeDBErr := func() error {
+				tx := engine.db.Begin()
+				if err := tx.Error; err != nil {
+					return err
+				}
+				tx.Model(model.SwapFilledTxLog{}).Where("id = ?", fillLog.Id).Updates(
+					map[string]interface{}{
+						"start_tx_hash":   startTxHash,
+						"start_log_index": startLogIndex,
+						"phase":           model.ConfirmRequest,
+						"update_time":     time.Now().Unix(),
+					})
+				if startTxHash == "" {
+					return tx.Commit().Error
+				}
+
+				var otherFills int64
+				tx.Model(model.SwapFilledTxLog{}).Where("start_tx_hash = ? and start_log_index = ? and id != ?", startTxHash, startLogIndex, fillLog.Id).Count(&otherFills)
+				if otherFills > 0 {
+					util.Logger.Errorf("double payout detected, start tx hash %s, fill tx hash %s", startTxHash, fillLog.TxHash)
+					util.SendTelegramMessage(fmt.Sprintf("Upgent alert: double payout detected, start tx hash %s, fill tx hash %s", startTxHash, fillLog.TxHash))
+				}
+
+				swap, err := engine.getSwapByStartTx(tx, startTxHash, startLogIndex)
+				if err != nil {
+					tx.Rollback()
+					return err
+				}
+				if swap.Status != SwapSuccess {
+					util.Logger.Infof("swap is filled on chain, start tx hash %s, fill tx hash %s", startTxHash, fillLog.TxHash)
+					swap.Status = SwapSuccess
+					swap.FillTxHash = fillLog.TxHash
+					swap.Log = fmt.Sprintf("filled on chain, fill txHash %s", fillLog.TxHash)
+					engine.updateSwap(tx, swap)
+				}
+				return tx.Commit().Error
+			}()
+			if writeDBErr != nil {
+				util.Logger.Errorf("write db error: %s", writeDBErr.Error())
+				util.SendTelegramMessage(fmt.Sprintf("write db error: %s", writeDBErr.Error()))
+			}
+		}
+	}
+}
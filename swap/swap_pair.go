@@ -0,0 +1,640 @@
+package swap
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcom "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/jinzhu/gorm"
+
+	sabi "occ-swap-server/abi"
+	"occ-swap-server/common"
+	"occ-swap-server/model"
+	"occ-swap-server/provider"
+	"occ-swap-server/util"
+)
+
+// NewSwapPairEngine returns the swapPairEngine instance, it sends txs with the chains of the swap engine
+func NewSwapPairEngine(db *gorm.DB, cfg *util.Config, swapEngine *SwapEngine) (*SwapPairEngine, error) {
+	swapPairAgentABI, err := abi.JSON(strings.NewReader(sabi.SwapPairAgentABI))
+	if err != nil {
+		return nil, err
+	}
+
+	return &SwapPairEngine{
+		db:               db,
+		hmacKey:          swapEngine.hmacCKey,
+		config:           cfg,
+		swapEngine:       swapEngine,
+		swapPairAgentABI: &swapPairAgentABI,
+	}, nil
+}
+
+func (engine *SwapPairEngine) Start() {
+	go engine.monitorSwapPairRegisterDaemon()
+	go engine.confirmSwapPairRegisterDaemon()
+	go engine.createSwapPairDaemon()
+	go engine.trackSwapPairTxDaemon()
+	go engine.finalizeSwapPairDaemon()
+}
+
+func (engine *SwapPairEngine) getSwapPairSMHMAC(sm *model.SwapPairStateMachine) string {
+	material := fmt.Sprintf("%s#%s#%s#%s#%s#%s#%s#%s#%d#%s#%s",
+		sm.Status, sm.Chain, sm.ToChain, sm.ERC20Addr, sm.BEP20Addr, sm.Sponsor, sm.Symbol, sm.Name, sm.Decimals,
+		sm.PairRegisterTxHash, sm.PairCreatTxHash)
+	mac := hmac.New(sha256.New, []byte(engine.hmacKey))
+	mac.Write([]byte(material))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (engine *SwapPairEngine) verifySwapPairSM(sm *model.SwapPairStateMachine) bool {
+	return sm.RecordHash == engine.getSwapPairSMHMAC(sm)
+}
+
+func (engine *SwapPairEngine) insertSwapPairSM(tx *gorm.DB, sm *model.SwapPairStateMachine) error {
+	sm.RecordHash = engine.getSwapPairSMHMAC(sm)
+	return tx.Create(sm).Error
+}
+
+func (engine *SwapPairEngine) updateSwapPairSM(tx *gorm.DB, sm *model.SwapPairStateMachine) {
+	sm.RecordHash = engine.getSwapPairSMHMAC(sm)
+	tx.Save(sm)
+}
+
+// getSwapPairHMAC covers the fields of the swap pair which never change, the admin server updates the others
+func (engine *SwapPairEngine) getSwapPairHMAC(swapPair *model.SwapPair) string {
+	material := fmt.Sprintf("%s#%s#%s#%d#%s#%s",
+		swapPair.Sponsor, swapPair.Symbol, swapPair.Name, swapPair.Decimals, swapPair.BEP20Addr, swapPair.ERC20Addr)
+	mac := hmac.New(sha256.New, []byte(engine.hmacKey))
+	mac.Write([]byte(material))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (engine *SwapPairEngine) getSwapPairSMByRegisterTxHash(tx *gorm.DB, txHash string) (*model.SwapPairStateMachine, error) {
+	sm := model.SwapPairStateMachine{}
+	err := tx.Where("pair_register_tx_hash = ?", txHash).First(&sm).Error
+	if err != nil {
+		return nil, err
+	}
+	if !engine.verifySwapPairSM(&sm) {
+		return nil, fmt.Errorf("hmac verification failure")
+	}
+	return &sm, nil
+}
+
+func (engine *SwapPairEngine) createSwapPairSM(registerLog *model.SwapPairRegisterTxLog) *model.SwapPairStateMachine {
+	sm := &model.SwapPairStateMachine{
+		Status:             SwapPairReceived,
+		Chain:              registerLog.Chain,
+		ERC20Addr:          ethcom.HexToAddress(registerLog.ERC20Addr).String(),
+		BEP20Addr:          ethcom.HexToAddress(registerLog.BEP20Addr).String(),
+		Sponsor:            ethcom.HexToAddress(registerLog.Sponsor).String(),
+		Symbol:             registerLog.Symbol,
+		Name:               registerLog.Name,
+		Decimals:           registerLog.Decimals,
+		PairRegisterTxHash: registerLog.TxHash,
+	}
+
+	chainCfg := engine.config.ChainConfig.GetChain(registerLog.Chain)
+	if chainCfg == nil || chainCfg.SwapPairChain == "" {
+		sm.Status = SwapPairRejected
+		sm.Log = fmt.Sprintf("swap pairs registered on %s are not supported", registerLog.Chain)
+		return sm
+	}
+	sm.ToChain = engine.config.ChainConfig.GetChain(chainCfg.SwapPairChain).Name
+	return sm
+}
+
+func (engine *SwapPairEngine) monitorSwapPairRegisterDaemon() {
+	for {
+		registerLogs := make([]model.SwapPairRegisterTxLog, 0)
+		engine.db.Where("phase = ?", model.SeenRequest).Order("height asc").Limit(BatchSize).Find(&registerLogs)
+
+		if len(registerLogs) == 0 {
+			time.Sleep(SleepTime * time.Second)
+			continue
+		}
+
+		for _, registerLog := range registerLogs {
+			sm := engine.createSwapPairSM(&registerLog)
+			writeDBErr := func() error {
+				tx := engine.db.Begin()
+				if err := tx.Error; err != nil {
+					return err
+				}
+				if err := engine.insertSwapPairSM(tx, sm); err != nil {
+					tx.Rollback()
+					return err
+				}
+				tx.Model(model.SwapPairRegisterTxLog{}).Where("id = ?", registerLog.Id).Updates(
+					map[string]interface{}{
+						"phase":       model.ConfirmRequest,
+						"update_time": time.Now().Unix(),
+					})
+				return tx.Commit().Error
+			}()
+
+			if writeDBErr != nil {
+				util.Logger.Errorf("write db error: %s", writeDBErr.Error())
+				util.SendTelegramMessage(fmt.Sprintf("write db error: %s", writeDBErr.Error()))
+			}
+		}
+	}
+}
+
+// confirmSwapPairRegisterDaemon validates the confirmed register events, the swap pair is created only if all
+// the checks pass
+func (engine *SwapPairEngine) confirmSwapPairRegisterDaemon() {
+	for {
+		registerLogs := make([]model.SwapPairRegisterTxLog, 0)
+		engine.db.Where("status = ? and phase = ?", model.TxStatusConfirmed, model.ConfirmRequest).
+			Order("height asc").Limit(BatchSize).Find(&registerLogs)
+
+		if len(registerLogs) == 0 {
+			time.Sleep(SleepTime * time.Second)
+			continue
+		}
+
+		for _, registerLog := range registerLogs {
+			sm, err := engine.getSwapPairSMByRegisterTxHash(engine.db, registerLog.TxHash)
+			if err != nil {
+				util.Logger.Errorf("verify hmac of swap pair state machine failed: %s", registerLog.TxHash)
+				util.SendTelegramMessage(fmt.Sprintf("Urgent alert: verify hmac of swap pair state machine failed: %s", registerLog.TxHash))
+				continue
+			}
+
+			var validateErr error
+			if sm.Status == SwapPairReceived {
+				validateErr = engine.validateSwapPairRegister(&registerLog, sm)
+			}
+
+			writeDBErr := func() error {
+				tx := engine.db.Begin()
+				if err := tx.Error; err != nil {
+					return err
+				}
+				if sm.Status == SwapPairReceived {
+					if validateErr != nil {
+						util.Logger.Infof("reject swap pair register, chain %s, tx hash %s, reason: %s", registerLog.Chain, registerLog.TxHash, validateErr.Error())
+						sm.Status = SwapPairRejected
+						// the report of the erc20 validation is kept as the log
+						if !errors.Is(validateErr, errERC20ValidationFailed) {
+							sm.Log = validateErr.Error()
+						}
+					} else {
+						sm.Status = SwapPairConfirmed
+					}
+					engine.updateSwapPairSM(tx, sm)
+				}
+				tx.Model(model.SwapPairRegisterTxLog{}).Where("id = ?", registerLog.Id).Updates(
+					map[string]interface{}{
+						"phase":       model.AckRequest,
+						"update_time": time.Now().Unix(),
+					})
+				return tx.Commit().Error
+			}()
+			if writeDBErr != nil {
+				util.Logger.Errorf("write db error: %s", writeDBErr.Error())
+				util.SendTelegramMessage(fmt.Sprintf("write db error: %s", writeDBErr.Error()))
+			}
+		}
+	}
+}
+
+// validateSwapPairRegister checks the register tx is sent by the deployer of the token, the token is a valid
+// ERC20 and it is not registered yet
+func (engine *SwapPairEngine) validateSwapPairRegister(registerLog *model.SwapPairRegisterTxLog, sm *model.SwapPairStateMachine) error {
+	chain, err := engine.swapEngine.getChainByName(sm.Chain)
+	if err != nil {
+		return err
+	}
+	if _, err := engine.swapEngine.getChainByName(sm.ToChain); err != nil {
+		return err
+	}
+	erc20Addr := ethcom.HexToAddress(sm.ERC20Addr)
+	sponsor := ethcom.HexToAddress(sm.Sponsor)
+
+	registerTx, _, err := chain.Client.TransactionByHash(context.Background(), ethcom.HexToHash(registerLog.TxHash))
+	if err != nil {
+		return err
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(chain.ChainID), registerTx)
+	if err != nil {
+		return err
+	}
+	if sender != sponsor {
+		return fmt.Errorf("register tx is sent by %s, not the sponsor %s", sender.String(), sponsor.String())
+	}
+	deployer, err := findContractDeployer(chain, erc20Addr, registerLog.Height)
+	if err != nil {
+		return err
+	}
+	if deployer != sponsor {
+		return fmt.Errorf("token %s is deployed by %s, not the sponsor %s", erc20Addr.String(), deployer.String(), sponsor.String())
+	}
+
+	report := validateERC20Token(chain, erc20Addr, sponsor, registerLog.Height, sm)
+	sm.Log = report.String()
+	if err := report.Err(); err != nil {
+		return err
+	}
+
+	var registered int64
+	engine.db.Model(model.SwapPair{}).Where("erc20_addr = ? or bep20_addr = ?", sm.ERC20Addr, sm.BEP20Addr).Count(&registered)
+	if registered > 0 {
+		return fmt.Errorf("token %s is already registered", sm.ERC20Addr)
+	}
+	engine.db.Model(model.SwapPairStateMachine{}).Where("erc20_addr = ? and id != ? and status not in (?)",
+		sm.ERC20Addr, sm.ID, []common.SwapPairStatus{SwapPairReceived, SwapPairRejected, SwapPairSendFailed}).Count(&registered)
+	if registered > 0 {
+		return fmt.Errorf("token %s is being registered", sm.ERC20Addr)
+	}
+	return nil
+}
+
+// findContractDeployer returns the account which deployed the contract, the block of the deployment is found by a
+// binary search on the code of the contract, so the provider must serve the historical state. Contracts created by
+// other contracts have no deployer
+func findContractDeployer(chain *ChainIns, contract ethcom.Address, height int64) (ethcom.Address, error) {
+	low, high := int64(0), height
+	for low < high {
+		mid := (low + high) / 2
+		code, err := chain.Client.CodeAt(context.Background(), contract, big.NewInt(mid))
+		if err != nil {
+			return ethcom.Address{}, err
+		}
+		if len(code) > 0 {
+			high = mid
+		} else {
+			low = mid + 1
+		}
+	}
+
+	block, err := chain.Client.BlockByNumber(context.Background(), big.NewInt(low))
+	if err != nil {
+		return ethcom.Address{}, err
+	}
+	signer := types.LatestSignerForChainID(chain.ChainID)
+	for _, tx := range block.Transactions() {
+		if tx.To() != nil {
+			continue
+		}
+		from, err := types.Sender(signer, tx)
+		if err != nil {
+			continue
+		}
+		if crypto.CreateAddress(from, tx.Nonce()) == contract {
+			return from, nil
+		}
+	}
+	return ethcom.Address{}, fmt.Errorf("contract %s is not deployed by an account in block %d", contract.String(), low)
+}
+
+func (engine *SwapPairEngine) createSwapPairDaemon() {
+	for {
+		sms := make([]model.SwapPairStateMachine, 0)
+		engine.db.Where("status in (?)", []common.SwapPairStatus{SwapPairConfirmed, SwapPairSending}).
+			Order("id asc").Limit(TrackSwapPairSMBatchSize).Find(&sms)
+
+		if len(sms) == 0 {
+			time.Sleep(SleepTime * time.Second)
+			continue
+		}
+
+		for _, sm := range sms {
+			if !engine.verifySwapPairSM(&sm) {
+				util.Logger.Errorf("verify hmac of swap pair state machine failed: %s", sm.PairRegisterTxHash)
+				util.SendTelegramMessage(fmt.Sprintf("Urgent alert: verify hmac of swap pair state machine failed: %s", sm.PairRegisterTxHash))
+				continue
+			}
+
+			skip, writeDBErr := func() (bool, error) {
+				isSkip := false
+				tx := engine.db.Begin()
+				if err := tx.Error; err != nil {
+					return false, err
+				}
+				if sm.Status == SwapPairSending {
+					var creatTx model.SwapPairCreatTx
+					engine.db.Where("swap_pair_register_tx_hash = ?", sm.PairRegisterTxHash).First(&creatTx)
+					if creatTx.SwapPairCreatTxHash == "" {
+						util.Logger.Infof("retry creating swap pair, register tx hash %s, symbol %s", sm.PairRegisterTxHash, sm.Symbol)
+						sm.Status = SwapPairConfirmed
+						engine.updateSwapPairSM(tx, &sm)
+					} else {
+						util.Logger.Infof("swap pair creat tx is built successfully, but the tx status is uncertain, just mark the tx as sent, register tx hash %s", sm.PairRegisterTxHash)
+						tx.Model(model.SwapPairCreatTx{}).Where("id = ?", creatTx.ID).Updates(
+							map[string]interface{}{
+								"status":     model.FillTxSent,
+								"updated_at": time.Now().Unix(),
+							})
+						sm.Status = SwapPairSent
+						sm.PairCreatTxHash = creatTx.SwapPairCreatTxHash
+						engine.updateSwapPairSM(tx, &sm)
+
+						isSkip = true
+					}
+				} else {
+					sm.Status = SwapPairSending
+					engine.updateSwapPairSM(tx, &sm)
+				}
+				return isSkip, tx.Commit().Error
+			}()
+			if writeDBErr != nil {
+				util.Logger.Errorf("write db error: %s", writeDBErr.Error())
+				util.SendTelegramMessage(fmt.Sprintf("write db error: %s", writeDBErr.Error()))
+				continue
+			}
+			if skip {
+				continue
+			}
+
+			util.Logger.Infof("Create swap pair on %s, symbol %s, erc20 address %s, bep20 address %s", sm.ToChain, sm.Symbol, sm.ERC20Addr, sm.BEP20Addr)
+			creatTx, createErr := engine.doCreateSwapPair(&sm)
+			writeDBErr = func() error {
+				tx := engine.db.Begin()
+				if err := tx.Error; err != nil {
+					return err
+				}
+				if createErr != nil && errors.Is(createErr, errGasPriceAboveCeiling) {
+					util.Logger.Infof("defer creating swap pair, register tx hash %s: %s", sm.PairRegisterTxHash, createErr.Error())
+					sm.Status = SwapPairConfirmed
+					sm.Log = fmt.Sprintf("swap pair creation deferred: %s", createErr.Error())
+					engine.updateSwapPairSM(tx, &sm)
+				} else if createErr != nil {
+					util.Logger.Errorf("create swap pair failed: %s, register tx hash %s", createErr.Error(), sm.PairRegisterTxHash)
+					util.SendTelegramMessage(fmt.Sprintf("create swap pair failed: %s, register tx hash %s", createErr.Error(), sm.PairRegisterTxHash))
+					if creatTx != nil {
+						tx.Model(model.SwapPairCreatTx{}).Where("id = ?", creatTx.ID).Updates(
+							map[string]interface{}{
+								"status":     model.FillTxFailed,
+								"updated_at": time.Now().Unix(),
+							})
+						sm.PairCreatTxHash = creatTx.SwapPairCreatTxHash
+					}
+					sm.Status = SwapPairSendFailed
+					sm.Log = fmt.Sprintf("create swap pair failure: %s", createErr.Error())
+					engine.updateSwapPairSM(tx, &sm)
+				} else {
+					tx.Model(model.SwapPairCreatTx{}).Where("id = ?", creatTx.ID).Updates(
+						map[string]interface{}{
+							"status":     model.FillTxSent,
+							"updated_at": time.Now().Unix(),
+						})
+					sm.Status = SwapPairSent
+					sm.PairCreatTxHash = creatTx.SwapPairCreatTxHash
+					engine.updateSwapPairSM(tx, &sm)
+				}
+				return tx.Commit().Error
+			}()
+			if writeDBErr != nil {
+				util.Logger.Errorf("write db error: %s", writeDBErr.Error())
+				util.SendTelegramMessage(fmt.Sprintf("write db error: %s", writeDBErr.Error()))
+			}
+		}
+	}
+}
+
+// doCreateSwapPair calls createSwapPair on the swap agent of the destination chain
+func (engine *SwapPairEngine) doCreateSwapPair(sm *model.SwapPairStateMachine) (*model.SwapPairCreatTx, error) {
+	chain, err := engine.swapEngine.getChainByName(sm.ToChain)
+	if err != nil {
+		return nil, err
+	}
+	data, err := abiEncodeCreateSwapPair(ethcom.HexToHash(sm.PairRegisterTxHash), ethcom.HexToAddress(sm.ERC20Addr),
+		ethcom.HexToAddress(sm.BEP20Addr), sm.Name, sm.Symbol, uint8(sm.Decimals), engine.swapPairAgentABI)
+	if err != nil {
+		return nil, err
+	}
+
+	chain.mutex.Lock()
+	defer chain.mutex.Unlock()
+	nonce, err := chain.nonceManager.Reserve()
+	if err != nil {
+		return nil, err
+	}
+	signedTx, err := buildSignedTransaction(chain, chain.SwapAgent, data, nonce)
+	if err != nil {
+		chain.nonceManager.Release(nonce)
+		return nil, err
+	}
+	creatTx := &model.SwapPairCreatTx{
+		Chain:                  chain.Name,
+		SwapPairRegisterTxHash: sm.PairRegisterTxHash,
+		SwapPairCreatTxHash:    signedTx.Hash().String(),
+		ERC20Addr:              sm.ERC20Addr,
+		BEP20Addr:              sm.BEP20Addr,
+		Symbol:                 sm.Symbol,
+		Name:                   sm.Name,
+		Decimals:               sm.Decimals,
+		Nonce:                  nonce,
+		GasPrice:               signedTx.GasPrice().String(),
+		Status:                 model.FillTxCreated,
+	}
+	if err := engine.db.Create(creatTx).Error; err != nil {
+		chain.nonceManager.Release(nonce)
+		return nil, err
+	}
+	err = chain.Client.SendTransaction(context.Background(), signedTx)
+	if errors.Is(err, provider.ErrSendUncertain) {
+		// the tx may be relayed, it is tracked like a sent tx and settled by the chain
+		util.Logger.Errorf("broadcast tx to %s is uncertain, track it as sent: %s", chain.Name, err.Error())
+		err = nil
+	}
+	if err != nil {
+		util.Logger.Errorf("broadcast tx to %s error: %s", chain.Name, err.Error())
+		chain.nonceManager.Release(nonce)
+		if reconcileErr := chain.nonceManager.Reconcile(); reconcileErr != nil {
+			util.Logger.Errorf("reconcile nonce of %s error: %s", chain.Name, reconcileErr.Error())
+		}
+		return creatTx, err
+	}
+	chain.nonceManager.Commit(nonce)
+	util.Logger.Infof("Send transaction to %s, %s/%s", chain.Name, chain.Config.ExplorerUrl, signedTx.Hash().String())
+	return creatTx, nil
+}
+
+func (engine *SwapPairEngine) trackSwapPairTxDaemon() {
+	for {
+		time.Sleep(SleepTime * time.Second)
+
+		for _, chain := range engine.swapEngine.chains {
+			creatTxs := make([]model.SwapPairCreatTx, 0)
+			engine.db.Where("status = ? and chain = ?", model.FillTxSent, chain.Name).
+				Order("id asc").Limit(TrackSentTxBatchSize).Find(&creatTxs)
+
+			if len(creatTxs) > 0 {
+				util.Logger.Debugf("Track %d non-finalized swap pair creat txs on %s", len(creatTxs), chain.Name)
+			}
+
+			for _, creatTx := range creatTxs {
+				var txRecipient *types.Receipt
+				var txFee *big.Int
+				queryTxStatusErr := func() error {
+					block, err := chain.Client.BlockByNumber(context.Background(), nil)
+					if err != nil {
+						util.Logger.Debugf("%s, query block failed: %s", chain.Name, err.Error())
+						return err
+					}
+					txRecipient, err = chain.Client.TransactionReceipt(context.Background(), ethcom.HexToHash(creatTx.SwapPairCreatTxHash))
+					if err != nil {
+						util.Logger.Debugf("%s, query tx failed: %s", chain.Name, err.Error())
+						return err
+					}
+					if block.Number().Int64() < txRecipient.BlockNumber.Int64()+chain.Config.ConfirmNum {
+						return fmt.Errorf("%s, swap pair creat tx is still not finalized", chain.Name)
+					}
+					_, txFee, err = getFillTxFee(chain, txRecipient)
+					if err != nil {
+						util.Logger.Debugf("%s, query tx fee failed: %s", chain.Name, err.Error())
+						return err
+					}
+					return nil
+				}()
+
+				writeDBErr := func() error {
+					tx := engine.db.Begin()
+					if err := tx.Error; err != nil {
+						return err
+					}
+					if queryTxStatusErr != nil && creatTx.TrackRetryCounter+1 < chain.Config.MaxTrackRetry {
+						tx.Model(model.SwapPairCreatTx{}).Where("id = ?", creatTx.ID).Updates(
+							map[string]interface{}{
+								"track_retry_counter": gorm.Expr("track_retry_counter + 1"),
+								"updated_at":          time.Now().Unix(),
+							})
+						return tx.Commit().Error
+					}
+
+					sm, err := engine.getSwapPairSMByRegisterTxHash(tx, creatTx.SwapPairRegisterTxHash)
+					if err != nil {
+						tx.Rollback()
+						return err
+					}
+					if queryTxStatusErr != nil {
+						util.Logger.Errorf("The swap pair creat tx is sent, however, after %d seconds its status is still uncertain. Mark tx as missing and mark swap pair as failed, chain %s, creat hash %s", SleepTime*chain.Config.MaxTrackRetry, chain.Name, creatTx.SwapPairCreatTxHash)
+						util.SendTelegramMessage(fmt.Sprintf("The swap pair creat tx is sent, however, after %d seconds its status is still uncertain. Mark tx as missing and mark swap pair as failed, chain %s, creat hash %s", SleepTime*chain.Config.MaxTrackRetry, chain.Name, creatTx.SwapPairCreatTxHash))
+						tx.Model(model.SwapPairCreatTx{}).Where("id = ?", creatTx.ID).Updates(
+							map[string]interface{}{
+								"status":     model.FillTxMissing,
+								"updated_at": time.Now().Unix(),
+							})
+						sm.Status = SwapPairSendFailed
+						sm.Log = fmt.Sprintf("track swap pair creat tx for more than %d times, the tx status is still uncertain", chain.Config.MaxTrackRetry)
+						engine.updateSwapPairSM(tx, sm)
+						return tx.Commit().Error
+					}
+
+					status := model.FillTxSuccess
+					if txRecipient.Status == TxFailedStatus {
+						util.Logger.Errorf("swap pair creat tx is failed, chain %s, txHash: %s", chain.Name, txRecipient.TxHash.String())
+						util.SendTelegramMessage(fmt.Sprintf("swap pair creat tx is failed, chain %s, txHash: %s", chain.Name, txRecipient.TxHash.String()))
+						status = model.FillTxFailed
+						sm.Status = SwapPairSendFailed
+						sm.Log = "swap pair creat tx is failed"
+					} else {
+						util.Logger.Infof("swap pair creat tx is success, chain %s, txHash: %s", chain.Name, txRecipient.TxHash.String())
+						sm.Status = SwapPairSuccess
+					}
+					err = tx.Model(model.SwapPairCreatTx{}).Where("id = ?", creatTx.ID).Updates(
+						map[string]interface{}{
+							"status":              status,
+							"height":              txRecipient.BlockNumber.Int64(),
+							"consumed_fee_amount": txFee.String(),
+							"updated_at":          time.Now().Unix(),
+						}).Error
+					if err != nil {
+						tx.Rollback()
+						return err
+					}
+					engine.updateSwapPairSM(tx, sm)
+					return tx.Commit().Error
+				}()
+				if writeDBErr != nil {
+					util.Logger.Errorf("write db error: %s", writeDBErr.Error())
+					util.SendTelegramMessage(fmt.Sprintf("write db error: %s", writeDBErr.Error()))
+				}
+			}
+		}
+	}
+}
+
+// finalizeSwapPairDaemon adds the created swap pairs to swap_pairs and to the swap engine
+func (engine *SwapPairEngine) finalizeSwapPairDaemon() {
+	for {
+		sms := make([]model.SwapPairStateMachine, 0)
+		engine.db.Where("status = ?", SwapPairSuccess).Order("id asc").Limit(TrackSwapPairSMBatchSize).Find(&sms)
+
+		if len(sms) == 0 {
+			time.Sleep(SleepTime * time.Second)
+			continue
+		}
+
+		for _, sm := range sms {
+			if !engine.verifySwapPairSM(&sm) {
+				util.Logger.Errorf("verify hmac of swap pair state machine failed: %s", sm.PairRegisterTxHash)
+				util.SendTelegramMessage(fmt.Sprintf("Urgent alert: verify hmac of swap pair state machine failed: %s", sm.PairRegisterTxHash))
+				continue
+			}
+
+			swapPair := &model.SwapPair{
+				Sponsor:    sm.Sponsor,
+				Symbol:     sm.Symbol,
+				Name:       sm.Name,
+				Decimals:   sm.Decimals,
+				BEP20Addr:  sm.BEP20Addr,
+				ERC20Addr:  sm.ERC20Addr,
+				Available:  true,
+				LowBound:   "0",
+				UpperBound: MaxUpperBound,
+			}
+			swapPair.RecordHash = engine.getSwapPairHMAC(swapPair)
+
+			inserted := false
+			writeDBErr := func() error {
+				tx := engine.db.Begin()
+				if err := tx.Error; err != nil {
+					return err
+				}
+				var registered int64
+				tx.Model(model.SwapPair{}).Where("erc20_addr = ?", sm.ERC20Addr).Count(&registered)
+				if registered > 0 {
+					util.Logger.Errorf("swap pair of %s already exists, register tx hash %s", sm.ERC20Addr, sm.PairRegisterTxHash)
+					util.SendTelegramMessage(fmt.Sprintf("swap pair of %s already exists, register tx hash %s", sm.ERC20Addr, sm.PairRegisterTxHash))
+					sm.Log = "swap pair already exists"
+				} else {
+					if err := tx.Create(swapPair).Error; err != nil {
+						tx.Rollback()
+						return err
+					}
+					inserted = true
+				}
+				sm.Status = SwapPairFinalized
+				engine.updateSwapPairSM(tx, &sm)
+				return tx.Commit().Error
+			}()
+			if writeDBErr != nil {
+				util.Logger.Errorf("write db error: %s", writeDBErr.Error())
+				util.SendTelegramMessage(fmt.Sprintf("write db error: %s", writeDBErr.Error()))
+				continue
+			}
+			if inserted {
+				if err := engine.swapEngine.AddSwapPairInstance(swapPair); err != nil {
+					util.Logger.Errorf("load swap pair %s error: %s", swapPair.Symbol, err.Error())
+				}
+			}
+		}
+	}
+}
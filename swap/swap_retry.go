@@ -6,6 +6,7 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"math/big"
 	"strings"
@@ -18,11 +19,11 @@ import (
 
 	ethcom "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/jinzhu/gorm"
 
 	"occ-swap-server/common"
 	"occ-swap-server/model"
+	"occ-swap-server/provider"
 	"occ-swap-server/util"
 )
 
@@ -30,6 +31,13 @@ func (engine *SwapEngine) getRetrySwapHMAC(retrySwap *model.RetrySwap) string {
 	material := fmt.Sprintf("%d#%s#%s#%s#%s#%s#%s#%s#%s#%d#%s",
 		retrySwap.SwapID, retrySwap.Direction, retrySwap.StartTxHash, retrySwap.FillTxHash, retrySwap.Sponsor,
 		retrySwap.BEP20Addr, retrySwap.ERC20Addr, retrySwap.Symbol, retrySwap.Amount, retrySwap.Decimals, retrySwap.Status)
+	// appended only when set, so the hashes of retry swaps created before swap ids are recorded stay valid
+	if retrySwap.FillSwapId != "" {
+		material = fmt.Sprintf("%s#%d#%s", material, retrySwap.StartLogIndex, retrySwap.FillSwapId)
+	}
+	if retrySwap.VelocityReleased {
+		material = fmt.Sprintf("%s#velocity_released", material)
+	}
 	mac := hmac.New(sha256.New, []byte(engine.hmacCKey))
 	mac.Write([]byte(material))
 
@@ -77,6 +85,17 @@ func (engine *SwapEngine) insertRetrySwapTxsToDB(data *model.RetrySwapTx) error
 }
 
 func (engine *SwapEngine) doRetrySwap(retrySwap *model.RetrySwap, swapPairInstance *SwapPairIns) (*model.RetrySwapTx, error) {
+	fillLog, err := engine.getConfirmedSwapFill(retrySwap.StartTxHash, retrySwap.StartLogIndex, retrySwap.FillSwapId)
+	if err != nil {
+		return nil, err
+	}
+	if fillLog != nil {
+		return nil, fmt.Errorf("%w, chain %s, fill tx hash %s", errSwapAlreadyFilled, fillLog.Chain, fillLog.TxHash)
+	}
+	if err := engine.checkSwapNotRefunded(retrySwap.StartTxHash, retrySwap.StartLogIndex, retrySwap.FillSwapId); err != nil {
+		return nil, err
+	}
+
 	amount := big.NewInt(0)
 	_, ok := amount.SetString(retrySwap.Amount, 10)
 	if !ok {
@@ -87,98 +106,69 @@ func (engine *SwapEngine) doRetrySwap(retrySwap *model.RetrySwap, swapPairInstan
 	if !okk {
 		return nil, fmt.Errorf("invalid chainId: %s", retrySwap.ToChainId)
 	}
-	if retrySwap.Direction == SwapEth2BSC || retrySwap.Direction == SwapMATIC2BSC {
-		bscClientMutex.Lock()
-		defer bscClientMutex.Unlock()
-		data, err := abiEncodeFillSwap(toChainId, ethcom.HexToAddress(retrySwap.Sponsor), amount, engine.swapAgentABI)
-		if err != nil {
-			return nil, err
-		}
-		signedTx, err := buildSignedTransaction(engine.bscSwapAgent, engine.bscClient, data, engine.bscPrivateKey, toChainId)
-		if err != nil {
-			return nil, err
-		}
-		retrySwapTx := &model.RetrySwapTx{
-			RetrySwapID:         retrySwap.ID,
-			StartTxHash:         retrySwap.StartTxHash,
-			Direction:           retrySwap.Direction,
-			RetryFillSwapTxHash: signedTx.Hash().String(),
-			Status:              model.FillRetryTxCreated,
-			GasPrice:            signedTx.GasPrice().String(),
-		}
-		err = engine.insertRetrySwapTxsToDB(retrySwapTx)
-		if err != nil {
-			return nil, err
-		}
-		err = engine.bscClient.SendTransaction(context.Background(), signedTx)
-		if err != nil {
-			util.Logger.Errorf("broadcast tx to BSC error: %s", err.Error())
-			return nil, err
-		}
-		util.Logger.Infof("Send transaction to BSC, %s/%s", engine.config.ChainConfig.BSCExplorerUrl, signedTx.Hash().String())
-		return retrySwapTx, nil
-	} else if retrySwap.Direction == SwapBSC2Eth || retrySwap.Direction == SwapMATIC2Eth {
-		ethClientMutex.Lock()
-		defer ethClientMutex.Unlock()
-		data, err := abiEncodeFillSwap(toChainId, ethcom.HexToAddress(retrySwap.Sponsor), amount, engine.swapAgentABI)
-		if err != nil {
-			return nil, err
-		}
-		signedTx, err := buildSignedTransaction(engine.ethSwapAgent, engine.ethClient, data, engine.ethPrivateKey, toChainId)
-		if err != nil {
-			return nil, err
-		}
-		retrySwapTx := &model.RetrySwapTx{
-			RetrySwapID:         retrySwap.ID,
-			StartTxHash:         retrySwap.StartTxHash,
-			Direction:           retrySwap.Direction,
-			RetryFillSwapTxHash: signedTx.Hash().String(),
-			GasPrice:            signedTx.GasPrice().String(),
-			Status:              model.FillRetryTxCreated,
-		}
-		err = engine.insertRetrySwapTxsToDB(retrySwapTx)
-		if err != nil {
-			return nil, err
-		}
-		err = engine.ethClient.SendTransaction(context.Background(), signedTx)
-		if err != nil {
-			util.Logger.Errorf("broadcast tx to ETH error: %s", err.Error())
-			return nil, err
-		} else {
-			util.Logger.Infof("Send transaction to ETH, %s/%s", engine.config.ChainConfig.ETHExplorerUrl, signedTx.Hash().String())
-		}
-		return retrySwapTx, nil
-	} else {
-		maticClientMutex.Lock()
-		defer maticClientMutex.Unlock()
-		data, err := abiEncodeFillSwap(toChainId, ethcom.HexToAddress(retrySwap.Sponsor), amount, engine.swapAgentABI)
-		if err != nil {
-			return nil, err
-		}
-		signedTx, err := buildSignedTransaction(engine.maticSwapAgent, engine.maticClient, data, engine.maticPrivateKey, toChainId)
-		if err != nil {
-			return nil, err
-		}
-		retrySwapTx := &model.RetrySwapTx{
-			RetrySwapID:         retrySwap.ID,
-			StartTxHash:         retrySwap.StartTxHash,
-			Direction:           retrySwap.Direction,
-			RetryFillSwapTxHash: signedTx.Hash().String(),
-			GasPrice:            signedTx.GasPrice().String(),
-			Status:              model.FillRetryTxCreated,
-		}
-		err = engine.insertRetrySwapTxsToDB(retrySwapTx)
-		if err != nil {
-			return nil, err
-		}
-		err = engine.maticClient.SendTransaction(context.Background(), signedTx)
-		if err != nil {
-			util.Logger.Errorf("broadcast tx to MATIC error: %s", err.Error())
-			return nil, err
+
+	chain, err := engine.getChainByChainId(retrySwap.ToChainId)
+	if err != nil {
+		return nil, err
+	}
+	swap, err := engine.getSwapByStartTx(engine.db, retrySwap.StartTxHash, retrySwap.StartLogIndex)
+	if err != nil {
+		return nil, err
+	}
+	if err := engine.checkDestinationToken(chain, retrySwap.ToChainId, swap.ToTokenAddr); err != nil {
+		return nil, err
+	}
+
+	chain.mutex.Lock()
+	defer chain.mutex.Unlock()
+	data, err := abiEncodeFillSwap(getSwapId(retrySwap.FillSwapId, retrySwap.StartTxHash), toChainId, ethcom.HexToAddress(retrySwap.Sponsor), amount, engine.swapAgentABI)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := chain.nonceManager.Reserve()
+	if err != nil {
+		return nil, err
+	}
+	signedTx, err := buildSignedTransaction(chain, chain.SwapAgent, data, nonce)
+	if err != nil {
+		chain.nonceManager.Release(nonce)
+		return nil, err
+	}
+	retrySwapTx := &model.RetrySwapTx{
+		RetrySwapID:         retrySwap.ID,
+		StartTxHash:         retrySwap.StartTxHash,
+		StartLogIndex:       retrySwap.StartLogIndex,
+		SwapId:              getSwapId(retrySwap.FillSwapId, retrySwap.StartTxHash),
+		Chain:               chain.Name,
+		Direction:           retrySwap.Direction,
+		RetryFillSwapTxHash: signedTx.Hash().String(),
+		Status:              model.FillRetryTxCreated,
+		Nonce:               nonce,
+		GasPrice:            signedTx.GasPrice().String(),
+		GasTipCap:           gasTipCap(signedTx),
+	}
+	err = engine.insertRetrySwapTxsToDB(retrySwapTx)
+	if err != nil {
+		chain.nonceManager.Release(nonce)
+		return nil, err
+	}
+	err = chain.Client.SendTransaction(context.Background(), signedTx)
+	if errors.Is(err, provider.ErrSendUncertain) {
+		// the tx may be relayed, it is tracked like a sent tx and settled by the chain
+		util.Logger.Errorf("broadcast tx to %s is uncertain, track it as sent: %s", chain.Name, err.Error())
+		err = nil
+	}
+	if err != nil {
+		util.Logger.Errorf("broadcast tx to %s error: %s", chain.Name, err.Error())
+		chain.nonceManager.Release(nonce)
+		if reconcileErr := chain.nonceManager.Reconcile(); reconcileErr != nil {
+			util.Logger.Errorf("reconcile nonce of %s error: %s", chain.Name, reconcileErr.Error())
 		}
-		util.Logger.Infof("Send transaction to MATIC, %s/%s", engine.config.ChainConfig.MATICExplorerUrl, signedTx.Hash().String())
-		return retrySwapTx, nil
+		return retrySwapTx, err
 	}
+	chain.nonceManager.Commit(nonce)
+	util.Logger.Infof("Send transaction to %s, %s/%s", chain.Name, chain.Config.ExplorerUrl, signedTx.Hash().String())
+	return retrySwapTx, nil
 }
 
 func (engine *SwapEngine) retryFailedSwapsDaemon() {
@@ -186,7 +176,19 @@ func (engine *SwapEngine) retryFailedSwapsDaemon() {
 		retrySwaps := make([]model.RetrySwap, 0)
 		engine.db.Where("status in (?)", []common.RetrySwapStatus{RetrySwapConfirmed, RetrySwapSending}).Order("id asc").Limit(BatchSize).Find(&retrySwaps)
 
+		pauses, err := engine.GetPauses()
+		if err != nil {
+			util.Logger.Errorf("query pauses error: %s", err.Error())
+			time.Sleep(SleepTime * time.Second)
+			continue
+		}
+		paused := 0
 		for _, retrySwap := range retrySwaps {
+			chain, err := engine.getChainByChainId(retrySwap.ToChainId)
+			if err == nil && pauseSet(pauses).get(chain.Name, retrySwap.Direction) != nil {
+				paused++
+				continue
+			}
 			var swapPairInstance *SwapPairIns
 			// var err error
 			retryCheckErr := func() error {
@@ -215,15 +217,22 @@ func (engine *SwapEngine) retryFailedSwapsDaemon() {
 				continue
 			}
 
+			held := false
 			skip, writeDBErr := func() (bool, error) {
 				isSkip := false
+				// the retry swaps are checked against the velocity limits like the claims of the swap workers, which
+				// they are serialized with
+				if chain != nil {
+					chain.claimMutex.Lock()
+					defer chain.claimMutex.Unlock()
+				}
 				tx := engine.db.Begin()
 				if err := tx.Error; err != nil {
 					return false, err
 				}
 				if retrySwap.Status == RetrySwapSending {
 					var retrySwapTx model.RetrySwapTx
-					engine.db.Where("start_swap_tx_hash = ?", retrySwap.StartTxHash).First(&retrySwapTx)
+					engine.db.Where("retry_swap_id = ? and status != ?", retrySwap.ID, model.FillRetryTxFailed).Order("id desc").First(&retrySwapTx)
 					if retrySwapTx.RetryFillSwapTxHash == "" {
 						util.Logger.Infof("retry the retrySwap, start tx hash %s, symbol %s, amount %s, direction",
 							retrySwap.StartTxHash, retrySwap.Symbol, retrySwap.Amount, retrySwap.Direction)
@@ -243,6 +252,17 @@ func (engine *SwapEngine) retryFailedSwapsDaemon() {
 						isSkip = true
 					}
 				} else {
+					if chain != nil && !retrySwap.VelocityReleased {
+						var err error
+						held, err = engine.holdRetrySwap(tx, chain, &retrySwap)
+						if err != nil {
+							tx.Rollback()
+							return false, err
+						}
+						if held {
+							return true, tx.Commit().Error
+						}
+					}
 					retrySwap.Status = RetrySwapSending
 					engine.updateRetrySwap(tx, &retrySwap)
 				}
@@ -253,6 +273,9 @@ func (engine *SwapEngine) retryFailedSwapsDaemon() {
 				util.SendTelegramMessage(fmt.Sprintf("write db error: %s", writeDBErr.Error()))
 				continue
 			}
+			if held {
+				engine.alertHeld(chain, "retry swap", retrySwap.StartTxHash, retrySwap.Sponsor, retrySwap.Amount, retrySwap.ErrorMsg)
+			}
 			if skip {
 				util.Logger.Debugf("skip this swap, start tx hash %s", retrySwap.StartTxHash)
 				continue
@@ -268,9 +291,44 @@ func (engine *SwapEngine) retryFailedSwapsDaemon() {
 					return err
 				}
 				if doRetrySwapErr != nil {
-					if doRetrySwapErr.Error() == core.ErrReplaceUnderpriced.Error() {
-						// delete the fill retry swap tx
-						tx.Where("retry_fill_swap_tx_hash = ?", retrySwapTx.RetryFillSwapTxHash).Delete(model.RetrySwapTx{})
+					if errors.Is(doRetrySwapErr, errGasPriceAboveCeiling) {
+						// nothing is sent, pick up this retry swap again once the gas price drops
+						util.Logger.Infof("defer retry swap, start hash %s: %s", retrySwap.StartTxHash, doRetrySwapErr.Error())
+						retrySwap.Status = RetrySwapConfirmed
+						retrySwap.ErrorMsg = doRetrySwapErr.Error()
+						engine.updateRetrySwap(tx, &retrySwap)
+					} else if errors.Is(doRetrySwapErr, errSwapAlreadyFilled) {
+						util.Logger.Errorf("refuse to fill retry swap, start hash %s: %s", retrySwap.StartTxHash, doRetrySwapErr.Error())
+						util.SendTelegramMessage(fmt.Sprintf("refuse to fill retry swap, start hash %s: %s", retrySwap.StartTxHash, doRetrySwapErr.Error()))
+						fillLog, err := engine.getConfirmedSwapFill(retrySwap.StartTxHash, retrySwap.StartLogIndex, retrySwap.FillSwapId)
+						if err != nil || fillLog == nil {
+							tx.Rollback()
+							return fmt.Errorf("query fill of swap %s error", retrySwap.StartTxHash)
+						}
+						retrySwap.Status = RetrySwapSuccess
+						retrySwap.FillTxHash = fillLog.TxHash
+						retrySwap.ErrorMsg = doRetrySwapErr.Error()
+						engine.updateRetrySwap(tx, &retrySwap)
+
+						swap, err := engine.getSwapByStartTx(tx, retrySwap.StartTxHash, retrySwap.StartLogIndex)
+						if err != nil {
+							tx.Rollback()
+							return err
+						}
+						swap.Status = SwapSuccess
+						swap.FillTxHash = fillLog.TxHash
+						swap.Log = fmt.Sprintf("filled on chain, fill txHash %s", fillLog.TxHash)
+						engine.updateSwap(tx, swap)
+					} else if doRetrySwapErr.Error() == core.ErrReplaceUnderpriced.Error() {
+						// the nonce is taken by another tx and has been reconciled, drop this fill retry tx
+						if retrySwapTx != nil {
+							tx.Model(model.RetrySwapTx{}).Where("retry_fill_swap_tx_hash = ?", retrySwapTx.RetryFillSwapTxHash).Updates(
+								map[string]interface{}{
+									"status":     model.FillRetryTxFailed,
+									"error_msg":  doRetrySwapErr.Error(),
+									"updated_at": time.Now().Unix(),
+								})
+						}
 						// retry this swap
 						retrySwap.ErrorMsg = doRetrySwapErr.Error()
 						engine.updateRetrySwap(tx, &retrySwap)
@@ -283,12 +341,14 @@ func (engine *SwapEngine) retryFailedSwapsDaemon() {
 						retrySwap.ErrorMsg = doRetrySwapErr.Error()
 						engine.updateRetrySwap(tx, &retrySwap)
 
-						tx.Model(model.RetrySwapTx{}).Where("retry_fill_swap_tx_hash = ?", retrySwapTx.RetryFillSwapTxHash).Updates(
-							map[string]interface{}{
-								"status":     model.FillRetryTxFailed,
-								"error_msg":  doRetrySwapErr.Error(),
-								"updated_at": time.Now().Unix(),
-							})
+						if retrySwapTx != nil {
+							tx.Model(model.RetrySwapTx{}).Where("retry_fill_swap_tx_hash = ?", retrySwapTx.RetryFillSwapTxHash).Updates(
+								map[string]interface{}{
+									"status":     model.FillRetryTxFailed,
+									"error_msg":  doRetrySwapErr.Error(),
+									"updated_at": time.Now().Unix(),
+								})
+						}
 					}
 				} else {
 					tx.Model(model.RetrySwapTx{}).Where("retry_fill_swap_tx_hash = ?", retrySwapTx.RetryFillSwapTxHash).Updates(
@@ -306,6 +366,9 @@ func (engine *SwapEngine) retryFailedSwapsDaemon() {
 				util.SendTelegramMessage(fmt.Sprintf("write db error: %s", writeDBErr.Error()))
 			}
 		}
+		if paused == len(retrySwaps) {
+			time.Sleep(SleepTime * time.Second)
+		}
 	}
 }
 
@@ -314,53 +377,46 @@ func (engine *SwapEngine) trackRetrySwapTxDaemon() {
 		for {
 			time.Sleep(SleepTime * time.Second)
 
-			retrySwapTxs := make([]model.RetrySwapTx, 0)
-			engine.db.Where("status = ? and track_retry_counter >= ?", model.FillRetryTxSent, engine.config.ChainConfig.ETHMaxTrackRetry).
-				Order("id asc").Limit(TrackSentTxBatchSize).Find(&retrySwapTxs)
-
-			if len(retrySwapTxs) > 0 {
-				util.Logger.Infof("%d retry fill tx are missing, mark these retry swaps as failed", len(retrySwapTxs))
-			}
+			for _, chain := range engine.chains {
+				retrySwapTxs := make([]model.RetrySwapTx, 0)
+				engine.db.Where("status = ? and chain = ? and track_retry_counter >= ?", model.FillRetryTxSent, chain.Name, chain.Config.MaxTrackRetry).
+					Order("id asc").Limit(TrackSentTxBatchSize).Find(&retrySwapTxs)
 
-			for _, retrySwapTx := range retrySwapTxs {
-				chainName := "ETH"
-				maxRetry := engine.config.ChainConfig.ETHMaxTrackRetry
-				if retrySwapTx.Direction == SwapEth2BSC || retrySwapTx.Direction == SwapMATIC2BSC {
-					chainName = "BSC"
-					maxRetry = engine.config.ChainConfig.BSCMaxTrackRetry
-				}
-				if retrySwapTx.Direction == SwapBSC2MATIC || retrySwapTx.Direction == SwapEth2MATIC {
-					chainName = "MATIC"
-					maxRetry = engine.config.ChainConfig.MATICMaxTrackRetry
+				if len(retrySwapTxs) > 0 {
+					util.Logger.Infof("%d retry fill tx are missing on %s, mark these retry swaps as failed", len(retrySwapTxs), chain.Name)
 				}
-				util.Logger.Errorf("The retry fill tx is sent, however, after %d seconds its status is still uncertain. Mark tx as missing and mark swap as failed, chain %s, fill hash %s", SleepTime*maxRetry, chainName, retrySwapTx.RetryFillSwapTxHash)
-				util.SendTelegramMessage(fmt.Sprintf("The retry fill tx is sent, however, after %d seconds its status is still uncertain. Mark tx as missing and mark swap as failed, chain %s, start hash %s", SleepTime*maxRetry, chainName, retrySwapTx.RetryFillSwapTxHash))
 
-				writeDBErr := func() error {
-					tx := engine.db.Begin()
-					if err := tx.Error; err != nil {
-						return err
-					}
-					tx.Model(model.RetrySwapTx{}).Where("id = ?", retrySwapTx.ID).Updates(
-						map[string]interface{}{
-							"status":     model.FillRetryTxMissing,
-							"updated_at": time.Now().Unix(),
-						})
+				for _, retrySwapTx := range retrySwapTxs {
+					maxRetry := chain.Config.MaxTrackRetry
+					util.Logger.Errorf("The retry fill tx is sent, however, after %d seconds its status is still uncertain. Mark tx as missing and mark swap as failed, chain %s, fill hash %s", SleepTime*maxRetry, chain.Name, retrySwapTx.RetryFillSwapTxHash)
+					util.SendTelegramMessage(fmt.Sprintf("The retry fill tx is sent, however, after %d seconds its status is still uncertain. Mark tx as missing and mark swap as failed, chain %s, start hash %s", SleepTime*maxRetry, chain.Name, retrySwapTx.RetryFillSwapTxHash))
 
-					retrySwap, err := engine.getRetrySwapByID(tx, retrySwapTx.RetrySwapID)
-					if err != nil {
-						tx.Rollback()
-						return err
-					}
-					retrySwap.Status = RetrySwapSendFailed
-					retrySwap.ErrorMsg = fmt.Sprintf("track fill retry swap tx for more than %d times, the fill retry swap tx status is still uncertain", maxRetry)
-					engine.updateRetrySwap(tx, retrySwap)
+					writeDBErr := func() error {
+						tx := engine.db.Begin()
+						if err := tx.Error; err != nil {
+							return err
+						}
+						tx.Model(model.RetrySwapTx{}).Where("id = ?", retrySwapTx.ID).Updates(
+							map[string]interface{}{
+								"status":     model.FillRetryTxMissing,
+								"updated_at": time.Now().Unix(),
+							})
 
-					return tx.Commit().Error
-				}()
-				if writeDBErr != nil {
-					util.Logger.Errorf("write db error: %s", writeDBErr.Error())
-					util.SendTelegramMessage(fmt.Sprintf("write db error: %s", writeDBErr.Error()))
+						retrySwap, err := engine.getRetrySwapByID(tx, retrySwapTx.RetrySwapID)
+						if err != nil {
+							tx.Rollback()
+							return err
+						}
+						retrySwap.Status = RetrySwapSendFailed
+						retrySwap.ErrorMsg = fmt.Sprintf("track fill retry swap tx for more than %d times, the fill retry swap tx status is still uncertain", maxRetry)
+						engine.updateRetrySwap(tx, retrySwap)
+
+						return tx.Commit().Error
+					}()
+					if writeDBErr != nil {
+						util.Logger.Errorf("write db error: %s", writeDBErr.Error())
+						util.SendTelegramMessage(fmt.Sprintf("write db error: %s", writeDBErr.Error()))
+					}
 				}
 			}
 		}
@@ -370,120 +426,124 @@ func (engine *SwapEngine) trackRetrySwapTxDaemon() {
 		for {
 			time.Sleep(SleepTime * time.Second)
 
-			retrySwapTxs := make([]model.RetrySwapTx, 0)
-			engine.db.Where("status = ? and track_retry_counter < ?", model.FillRetryTxSent, engine.config.ChainConfig.ETHMaxTrackRetry).
-				Order("id asc").Limit(TrackSentTxBatchSize).Find(&retrySwapTxs)
-
-			if len(retrySwapTxs) > 0 {
-				util.Logger.Debugf("Track %d non-finalized retry swap txs", len(retrySwapTxs))
-			}
+			for _, chain := range engine.chains {
+				retrySwapTxs := make([]model.RetrySwapTx, 0)
+				engine.db.Where("status = ? and chain = ? and track_retry_counter < ?", model.FillRetryTxSent, chain.Name, chain.Config.MaxTrackRetry).
+					Order("id asc").Limit(TrackSentTxBatchSize).Find(&retrySwapTxs)
 
-			for _, retrySwapTx := range retrySwapTxs {
-				gasPrice := big.NewInt(0)
-				gasPrice.SetString(retrySwapTx.GasPrice, 10)
-
-				var client *ethclient.Client
-				var chainName string
-				client = engine.ethClient
-				if retrySwapTx.Direction == SwapEth2BSC || retrySwapTx.Direction == SwapMATIC2BSC {
-					chainName = "BSC"
-					client = engine.bscClient
-				}
-				if retrySwapTx.Direction == SwapBSC2MATIC || retrySwapTx.Direction == SwapEth2MATIC {
-					chainName = "MATIC"
-					client = engine.maticClient
+				if len(retrySwapTxs) > 0 {
+					util.Logger.Debugf("Track %d non-finalized retry swap txs on %s", len(retrySwapTxs), chain.Name)
 				}
-				var txRecipient *types.Receipt
-				queryTxStatusErr := func() error {
-					block, err := client.BlockByNumber(context.Background(), nil)
-					if err != nil {
-						util.Logger.Debugf("%s, query block failed: %s", chainName, err.Error())
-						return err
-					}
-					txRecipient, err = client.TransactionReceipt(context.Background(), ethcom.HexToHash(retrySwapTx.RetryFillSwapTxHash))
-					if err != nil {
-						util.Logger.Debugf("%s, query tx failed: %s", chainName, err.Error())
-						return err
-					}
-					if block.Number().Int64() < txRecipient.BlockNumber.Int64()+engine.config.ChainConfig.ETHConfirmNum {
-						return fmt.Errorf("%s, swap tx is still not finalized", chainName)
-					}
-					return nil
-				}()
 
-				writeDBErr := func() error {
-					tx := engine.db.Begin()
-					if err := tx.Error; err != nil {
-						return err
-					}
-					if queryTxStatusErr != nil {
-						tx.Model(model.RetrySwapTx{}).Where("id = ?", retrySwapTx.ID).Updates(
-							map[string]interface{}{
-								"track_retry_counter": gorm.Expr("track_retry_counter + 1"),
-								"updated_at":          time.Now().Unix(),
-							})
-					} else {
-						txFee := big.NewInt(1).Mul(gasPrice, big.NewInt(int64(txRecipient.GasUsed))).String()
-						if txRecipient.Status == TxFailedStatus {
-							util.Logger.Infof(fmt.Sprintf("fill retry swap tx is failed, chain %s, txHash: %s", chainName, txRecipient.TxHash.String()))
-							util.SendTelegramMessage(fmt.Sprintf("fill retry swap tx is failed, chain %s, txHash: %s", chainName, txRecipient.TxHash.String()))
-							err := tx.Model(model.RetrySwapTx{}).Where("id = ?", retrySwapTx.ID).Updates(
+				for _, retrySwapTx := range retrySwapTxs {
+					var txRecipient *types.Receipt
+					var baseFee, txFee *big.Int
+					queryTxStatusErr := func() error {
+						block, err := chain.Client.BlockByNumber(context.Background(), nil)
+						if err != nil {
+							util.Logger.Debugf("%s, query block failed: %s", chain.Name, err.Error())
+							return err
+						}
+						txRecipient, err = chain.Client.TransactionReceipt(context.Background(), ethcom.HexToHash(retrySwapTx.RetryFillSwapTxHash))
+						if err != nil {
+							util.Logger.Debugf("%s, query tx failed: %s", chain.Name, err.Error())
+							return err
+						}
+						if block.Number().Int64() < txRecipient.BlockNumber.Int64()+chain.Config.ConfirmNum {
+							return fmt.Errorf("%s, swap tx is still not finalized", chain.Name)
+						}
+						baseFee, txFee, err = getFillTxFee(chain, txRecipient)
+						if err != nil {
+							util.Logger.Debugf("%s, query tx fee failed: %s", chain.Name, err.Error())
+							return err
+						}
+						return nil
+					}()
+
+					writeDBErr := func() error {
+						tx := engine.db.Begin()
+						if err := tx.Error; err != nil {
+							return err
+						}
+						if queryTxStatusErr != nil {
+							tx.Model(model.RetrySwapTx{}).Where("id = ?", retrySwapTx.ID).Updates(
 								map[string]interface{}{
-									"status":              model.FillRetryTxFailed,
-									"height":              txRecipient.BlockNumber.Int64(),
-									"consumed_fee_amount": txFee,
+									"track_retry_counter": gorm.Expr("track_retry_counter + 1"),
 									"updated_at":          time.Now().Unix(),
-								}).Error
-							if err != nil {
-								tx.Rollback()
-								return err
-							}
-							retrySwap, err := engine.getRetrySwapByID(tx, retrySwapTx.RetrySwapID)
-							if err != nil {
-								tx.Rollback()
-								return err
-							}
-							retrySwap.Status = RetrySwapSendFailed
-							retrySwap.ErrorMsg = "fill retry swap tx is failed"
-							engine.updateRetrySwap(tx, retrySwap)
+								})
 						} else {
-							util.Logger.Infof(fmt.Sprintf("fill retry swap tx is success, chain %s, txHash: %s", chainName, txRecipient.TxHash.String()))
-							err := tx.Model(model.RetrySwapTx{}).Where("id = ?", retrySwapTx.ID).Updates(
-								map[string]interface{}{
-									"status":              model.FillRetryTxSuccess,
-									"height":              txRecipient.BlockNumber.Int64(),
-									"consumed_fee_amount": txFee,
-									"updated_at":          time.Now().Unix(),
-								}).Error
-							if err != nil {
-								tx.Rollback()
-								return err
+							baseFeeStr := ""
+							if baseFee != nil {
+								baseFeeStr = baseFee.String()
 							}
-
-							retrySwap, err := engine.getRetrySwapByID(tx, retrySwapTx.RetrySwapID)
-							if err != nil {
-								tx.Rollback()
-								return err
+							swapIdVerified := retrySwapTx.SwapId == "" || engine.verifySwapFilled(chain, txRecipient, retrySwapTx.SwapId)
+							if !swapIdVerified {
+								util.Logger.Errorf("fill retry swap tx does not emit SwapFilled of swap id %s, chain %s, txHash: %s", retrySwapTx.SwapId, chain.Name, txRecipient.TxHash.String())
+								util.SendTelegramMessage(fmt.Sprintf("Upgent alert: fill retry swap tx does not emit SwapFilled of swap id %s, chain %s, txHash: %s", retrySwapTx.SwapId, chain.Name, txRecipient.TxHash.String()))
 							}
-							retrySwap.Status = RetrySwapSuccess
-							retrySwap.ErrorMsg = "fill retry swap tx is failed"
-							engine.updateRetrySwap(tx, retrySwap)
-
-							swap, err := engine.getSwapByStartTxHash(tx, retrySwapTx.StartTxHash)
-							if err != nil {
-								tx.Rollback()
-								return err
+							if txRecipient.Status == TxFailedStatus || !swapIdVerified {
+								util.Logger.Infof(fmt.Sprintf("fill retry swap tx is failed, chain %s, txHash: %s", chain.Name, txRecipient.TxHash.String()))
+								util.SendTelegramMessage(fmt.Sprintf("fill retry swap tx is failed, chain %s, txHash: %s", chain.Name, txRecipient.TxHash.String()))
+								err := tx.Model(model.RetrySwapTx{}).Where("id = ?", retrySwapTx.ID).Updates(
+									map[string]interface{}{
+										"status":              model.FillRetryTxFailed,
+										"height":              txRecipient.BlockNumber.Int64(),
+										"base_fee":            baseFeeStr,
+										"consumed_fee_amount": txFee.String(),
+										"updated_at":          time.Now().Unix(),
+									}).Error
+								if err != nil {
+									tx.Rollback()
+									return err
+								}
+								retrySwap, err := engine.getRetrySwapByID(tx, retrySwapTx.RetrySwapID)
+								if err != nil {
+									tx.Rollback()
+									return err
+								}
+								retrySwap.Status = RetrySwapSendFailed
+								retrySwap.ErrorMsg = "fill retry swap tx is failed"
+								engine.updateRetrySwap(tx, retrySwap)
+							} else {
+								util.Logger.Infof(fmt.Sprintf("fill retry swap tx is success, chain %s, txHash: %s", chain.Name, txRecipient.TxHash.String()))
+								err := tx.Model(model.RetrySwapTx{}).Where("id = ?", retrySwapTx.ID).Updates(
+									map[string]interface{}{
+										"status":              model.FillRetryTxSuccess,
+										"height":              txRecipient.BlockNumber.Int64(),
+										"base_fee":            baseFeeStr,
+										"consumed_fee_amount": txFee.String(),
+										"updated_at":          time.Now().Unix(),
+									}).Error
+								if err != nil {
+									tx.Rollback()
+									return err
+								}
+
+								retrySwap, err := engine.getRetrySwapByID(tx, retrySwapTx.RetrySwapID)
+								if err != nil {
+									tx.Rollback()
+									return err
+								}
+								retrySwap.Status = RetrySwapSuccess
+								retrySwap.ErrorMsg = "fill retry swap tx is failed"
+								engine.updateRetrySwap(tx, retrySwap)
+
+								swap, err := engine.getSwapByStartTx(tx, retrySwapTx.StartTxHash, retrySwapTx.StartLogIndex)
+								if err != nil {
+									tx.Rollback()
+									return err
+								}
+								swap.Status = SwapSuccess
+								swap.Log = fmt.Sprintf("retry success, retry txHash %s", retrySwapTx.RetryFillSwapTxHash)
+								engine.updateSwap(tx, swap)
 							}
-							swap.Status = SwapSuccess
-							swap.Log = fmt.Sprintf("retry success, retry txHash %s", retrySwapTx.RetryFillSwapTxHash)
-							engine.updateSwap(tx, swap)
 						}
+						return tx.Commit().Error
+					}()
+					if writeDBErr != nil {
+						util.Logger.Errorf("update db failure2: %s", writeDBErr.Error())
+						util.SendTelegramMessage(fmt.Sprintf("Upgent alert: update db failure2: %s", writeDBErr.Error()))
 					}
-					return tx.Commit().Error
-				}()
-				if writeDBErr != nil {
-					util.Logger.Errorf("update db failure2: %s", writeDBErr.Error())
-					util.SendTelegramMessage(fmt.Sprintf("Upgent alert: update db failure2: %s", writeDBErr.Error()))
 				}
 			}
 		}
@@ -514,6 +574,15 @@ func (engine *SwapEngine) InsertRetryFailedSwaps(swapIDList []uint) ([]uint, []u
 				rejectedRetrySwapList = append(rejectedRetrySwapList, swap.ID)
 				continue
 			}
+			if engine.getActiveSwapRefund(tx, swap.StartTxHash, swap.StartLogIndex) != nil {
+				rejectedRetrySwapList = append(rejectedRetrySwapList, swap.ID)
+				continue
+			}
+			if err := engine.checkSwapFillsSettled(tx, &swap); err != nil {
+				util.Logger.Infof("refuse to retry swap, start hash %s: %s", swap.StartTxHash, err.Error())
+				rejectedRetrySwapList = append(rejectedRetrySwapList, swap.ID)
+				continue
+			}
 			retrySwapList = append(retrySwapList, swap.ID)
 			retrySwap := &model.RetrySwap{
 				Status:      RetrySwapConfirmed,
@@ -528,6 +597,9 @@ func (engine *SwapEngine) InsertRetryFailedSwaps(swapIDList []uint) ([]uint, []u
 				Amount:      swap.Amount,
 				Decimals:    swap.Decimals,
 				ToChainId:   swap.ToChainId,
+
+				StartLogIndex: swap.StartLogIndex,
+				FillSwapId:    swap.SwapId,
 			}
 			if err := engine.insertRetrySwap(tx, retrySwap); err != nil {
 				tx.Rollback()
@@ -539,54 +611,61 @@ func (engine *SwapEngine) InsertRetryFailedSwaps(swapIDList []uint) ([]uint, []u
 	return retrySwapList, rejectedRetrySwapList, writeDBErr
 }
 
-func (engine *SwapEngine) WithdrawToken(chain string, tokenAddr, recipient ethcom.Address, amount *big.Int) (string, error) {
+func (engine *SwapEngine) WithdrawToken(chainName string, tokenAddr, recipient ethcom.Address, amount *big.Int) (string, error) {
 	tokenABI, err := abi.JSON(strings.NewReader(sabi.ERC20ABI))
 	if err != nil {
 		return "", err
 	}
+	chain, err := engine.getChainByName(chainName)
+	if err != nil {
+		return "", err
+	}
 	emptyAddr := ethcom.Address{}
-	privateKey := engine.bscPrivateKey
-	client := engine.bscClient
-	explorerUrl := engine.config.ChainConfig.BSCExplorerUrl
-	if chain == common.ChainETH {
-		privateKey = engine.ethPrivateKey
-		client = engine.ethClient
-		explorerUrl = engine.config.ChainConfig.ETHExplorerUrl
-		ethClientMutex.Lock()
-		defer ethClientMutex.Unlock()
-	} else {
-		bscClientMutex.Lock()
-		defer bscClientMutex.Unlock()
+	chain.mutex.Lock()
+	defer chain.mutex.Unlock()
+
+	nonce, err := chain.nonceManager.Reserve()
+	if err != nil {
+		return "", err
 	}
+	var signedTx *types.Transaction
 	// withdraw native token
 	if bytes.Equal(tokenAddr[:], emptyAddr[:]) {
-		signedTx, err := buildNativeCoinTransferTx(recipient, client, amount, privateKey)
+		signedTx, err = buildNativeCoinTransferTx(chain, recipient, amount, nonce)
 		if err != nil {
 			util.Logger.Errorf("build native coin transfer error: %s", err.Error())
+			chain.nonceManager.Release(nonce)
 			return "", err
 		}
-		err = client.SendTransaction(context.Background(), signedTx)
+	} else {
+		// withdraw BEP20 or ERC20 token
+		data, err := abiEncodeERC20Transfer(recipient, amount, &tokenABI)
 		if err != nil {
-			util.Logger.Errorf("broadcast tx to %s error: %s", chain, err.Error())
+			chain.nonceManager.Release(nonce)
+			return "", err
+		}
+		signedTx, err = buildSignedTransaction(chain, tokenAddr, data, nonce)
+		if err != nil {
+			chain.nonceManager.Release(nonce)
 			return "", err
 		}
-		util.Logger.Infof("Send transaction to %s, %s/%s", chain, explorerUrl, signedTx.Hash().String())
-		return signedTx.Hash().String(), nil
-	}
-	// withdraw BEP20 or ERC20 token
-	data, err := abiEncodeERC20Transfer(recipient, amount, &tokenABI)
-	if err != nil {
-		return "", err
 	}
-	signedTx, err := buildSignedTransaction(tokenAddr, client, data, privateKey, big.NewInt(56))
-	if err != nil {
-		return "", err
+	err = chain.Client.SendTransaction(context.Background(), signedTx)
+	if errors.Is(err, provider.ErrSendUncertain) {
+		// the tx may be relayed, the admin looks it up by its hash
+		util.Logger.Errorf("broadcast tx to %s is uncertain: %s", chain.Name, err.Error())
+		chain.nonceManager.Commit(nonce)
+		return signedTx.Hash().String(), err
 	}
-	err = client.SendTransaction(context.Background(), signedTx)
 	if err != nil {
-		util.Logger.Errorf("broadcast tx to %s error: %s", chain, err.Error())
+		util.Logger.Errorf("broadcast tx to %s error: %s", chain.Name, err.Error())
+		chain.nonceManager.Release(nonce)
+		if reconcileErr := chain.nonceManager.Reconcile(); reconcileErr != nil {
+			util.Logger.Errorf("reconcile nonce of %s error: %s", chain.Name, reconcileErr.Error())
+		}
 		return "", err
 	}
-	util.Logger.Infof("Send transaction to %s, %s/%s", chain, explorerUrl, signedTx.Hash().String())
+	chain.nonceManager.Commit(nonce)
+	util.Logger.Infof("Send transaction to %s, %s/%s", chain.Name, chain.Config.ExplorerUrl, signedTx.Hash().String())
 	return signedTx.Hash().String(), nil
 }
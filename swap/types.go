@@ -8,21 +8,32 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi"
 
 	ethcom "github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/jinzhu/gorm"
 
 	"occ-swap-server/common"
+	"occ-swap-server/executor"
+	"occ-swap-server/provider"
+	"occ-swap-server/screening"
 	"occ-swap-server/util"
 )
 
 const (
-	SwapTokenReceived common.SwapStatus = "received"
-	SwapQuoteRejected common.SwapStatus = "rejected"
-	SwapConfirmed     common.SwapStatus = "confirmed"
-	SwapSending       common.SwapStatus = "sending"
-	SwapSent          common.SwapStatus = "sent"
-	SwapSendFailed    common.SwapStatus = "sent_fail"
-	SwapSuccess       common.SwapStatus = "sent_success"
+	SwapTokenReceived   common.SwapStatus = "received"
+	SwapQuoteRejected   common.SwapStatus = "rejected"
+	SwapConfirmed       common.SwapStatus = "confirmed"
+	SwapSending         common.SwapStatus = "sending"
+	SwapSent            common.SwapStatus = "sent"
+	SwapSendFailed      common.SwapStatus = "sent_fail"
+	SwapSuccess         common.SwapStatus = "sent_success"
+	SwapRefunded        common.SwapStatus = "refunded"
+	SwapHeld            common.SwapStatus = "held"
+	SwapPendingApproval common.SwapStatus = "pending_approval"
+	SwapBlocked         common.SwapStatus = "blocked"
+	// the fill tx may still be mined, the swap is neither refunded nor retried until the chain settles it
+	SwapFillMissing common.SwapStatus = "fill_missing"
+
+	SwapApprovalApprove common.SwapApprovalDecision = "approve"
+	SwapApprovalReject  common.SwapApprovalDecision = "reject"
 
 	SwapPairReceived   common.SwapPairStatus = "received"
 	SwapPairConfirmed  common.SwapPairStatus = "confirmed"
@@ -31,19 +42,21 @@ const (
 	SwapPairSendFailed common.SwapPairStatus = "sent_fail"
 	SwapPairSuccess    common.SwapPairStatus = "sent_success"
 	SwapPairFinalized  common.SwapPairStatus = "finalized"
+	SwapPairRejected   common.SwapPairStatus = "rejected"
 
 	RetrySwapConfirmed  common.RetrySwapStatus = "confirmed"
 	RetrySwapSending    common.RetrySwapStatus = "sending"
 	RetrySwapSent       common.RetrySwapStatus = "sent"
 	RetrySwapSendFailed common.RetrySwapStatus = "sent_fail"
 	RetrySwapSuccess    common.RetrySwapStatus = "sent_success"
+	RetrySwapHeld       common.RetrySwapStatus = "held"
 
-	SwapEth2BSC   common.SwapDirection = "eth_bsc"
-	SwapEth2MATIC common.SwapDirection = "eth_matic"
-	SwapBSC2Eth   common.SwapDirection = "bsc_eth"
-	SwapBSC2MATIC common.SwapDirection = "bsc_matic"
-	SwapMATIC2BSC common.SwapDirection = "matic_bsc"
-	SwapMATIC2Eth common.SwapDirection = "matic_eth"
+	RefundPending common.SwapRefundStatus = "refund_pending"
+	RefundSent    common.SwapRefundStatus = "refund_sent"
+	RefundSuccess common.SwapRefundStatus = "refund_success"
+	RefundFailed  common.SwapRefundStatus = "refund_failed"
+	// the refund tx may still land, the refund blocks other payouts of the swap until it is resolved by hand
+	RefundMissing common.SwapRefundStatus = "refund_missing"
 
 	BatchSize                = 50
 	TrackSentTxBatchSize     = 100
@@ -56,10 +69,6 @@ const (
 	MaxUpperBound = "999999999999999999999999999999999999"
 )
 
-var ethClientMutex sync.RWMutex
-var bscClientMutex sync.RWMutex
-var maticClientMutex sync.RWMutex
-
 type SwapEngine struct {
 	mutex    sync.RWMutex
 	db       *gorm.DB
@@ -67,23 +76,42 @@ type SwapEngine struct {
 	config   *util.Config
 	// key is the bsc contract addr
 	swapPairsFromERC20Addr map[ethcom.Address]*SwapPairIns
-	ethClient              *ethclient.Client
-	bscClient              *ethclient.Client
-	maticClient            *ethclient.Client
-	ethPrivateKey          *ecdsa.PrivateKey
-	bscPrivateKey          *ecdsa.PrivateKey
-	maticPrivateKey        *ecdsa.PrivateKey
-	ethChainID             int64
-	bscChainID             int64
-	maticChainID           int64
-	bep20ToERC20           map[ethcom.Address]ethcom.Address
-	erc20ToBEP20           map[ethcom.Address]ethcom.Address
+	// key is the chain name
+	chains       map[string]*ChainIns
+	bep20ToERC20 map[ethcom.Address]ethcom.Address
+	erc20ToBEP20 map[ethcom.Address]ethcom.Address
+
+	directionResolver *DirectionResolver
+	// screens the sponsors of the swaps before they are confirmed
+	screener screening.Screener
 
 	swapAgentABI *abi.ABI
+}
 
-	ethSwapAgent   ethcom.Address
-	bscSwapAgent   ethcom.Address
-	maticSwapAgent ethcom.Address
+// ChainIns holds everything the engine needs to send transactions to one configured chain
+type ChainIns struct {
+	// serializes the transactions signed by PrivateKey, except the fills of the swap workers which only share the
+	// nonce manager
+	mutex sync.Mutex
+	// serializes the claims of the swap workers of the chain
+	claimMutex sync.Mutex
+	// set while the swap workers wait for the pending fills to the chain to be finalized
+	backpressure bool
+	// set while the fills to the chain are paused
+	paused bool
+	// the fills to the chain failing in a row, accessed atomically
+	fillFailures int64
+
+	Name       string
+	ChainID    *big.Int
+	Client     *provider.Pool
+	PrivateKey *ecdsa.PrivateKey
+	SwapAgent  ethcom.Address
+	// the latest agent version, its events are emitted by the fill txs
+	Agent  *executor.Agent
+	Config *util.ChainInfo
+
+	nonceManager *NonceManager
 }
 
 type SwapPairEngine struct {
@@ -92,14 +120,10 @@ type SwapPairEngine struct {
 	hmacKey string
 	config  *util.Config
 
+	// swap pairs are created with the chains of the swap engine and loaded into it once finalized
 	swapEngine *SwapEngine
 
-	bscClient       *ethclient.Client
-	bscPrivateKey   *ecdsa.PrivateKey
-	bscChainID      int64
-	bscTxSender     ethcom.Address
-	bscSwapAgent    ethcom.Address
-	bscSwapAgentABi *abi.ABI
+	swapPairAgentABI *abi.ABI
 }
 
 type SwapPairIns struct {
@@ -108,6 +132,7 @@ type SwapPairIns struct {
 	Decimals   int
 	LowBound   *big.Int
 	UpperBound *big.Int
+	Available  bool
 
 	BEP20Addr ethcom.Address
 	ERC20Addr ethcom.Address
@@ -6,15 +6,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/big"
+	"strconv"
 	"strings"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	ethcom "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
 
 	"occ-swap-server/common"
 	"occ-swap-server/model"
@@ -43,6 +42,7 @@ func buildSwapPairInstance(pairs []model.SwapPair) (map[ethcom.Address]*SwapPair
 			Decimals:   pair.Decimals,
 			LowBound:   lowBound,
 			UpperBound: upperBound,
+			Available:  pair.Available,
 			BEP20Addr:  ethcom.HexToAddress(pair.BEP20Addr),
 			ERC20Addr:  ethcom.HexToAddress(pair.ERC20Addr),
 		}
@@ -53,6 +53,15 @@ func buildSwapPairInstance(pairs []model.SwapPair) (map[ethcom.Address]*SwapPair
 	return swapPairInstances, nil
 }
 
+// chainIdStrings returns the chain ids of the chain in the format stored in swaps.to_chain_id
+func chainIdStrings(chainCfg *util.ChainInfo) []string {
+	chainIds := make([]string, 0, len(chainCfg.ChainIds))
+	for _, chainId := range chainCfg.ChainIds {
+		chainIds = append(chainIds, strconv.FormatInt(chainId, 10))
+	}
+	return chainIds
+}
+
 func GetKeyConfig(cfg *util.Config) (*util.KeyConfig, error) {
 	if cfg.KeyManagerConfig.KeyType == common.AWSPrivateKey {
 		result, err := util.GetSecret(cfg.KeyManagerConfig.AWSSecretName, cfg.KeyManagerConfig.AWSRegion)
@@ -68,12 +77,11 @@ func GetKeyConfig(cfg *util.Config) (*util.KeyConfig, error) {
 		return &keyConfig, nil
 	} else {
 		return &util.KeyConfig{
-			HMACKey:         cfg.KeyManagerConfig.LocalHMACKey,
-			AdminApiKey:     cfg.KeyManagerConfig.LocalAdminApiKey,
-			AdminSecretKey:  cfg.KeyManagerConfig.LocalAdminSecretKey,
-			BSCPrivateKey:   cfg.KeyManagerConfig.LocalBSCTxHash,
-			ETHPrivateKey:   cfg.KeyManagerConfig.LocalETHPrivateKey,
-			MATICPrivateKey: cfg.KeyManagerConfig.LocalMATICPrivateKey,
+			HMACKey:        cfg.KeyManagerConfig.LocalHMACKey,
+			AdminApiKey:    cfg.KeyManagerConfig.LocalAdminApiKey,
+			AdminSecretKey: cfg.KeyManagerConfig.LocalAdminSecretKey,
+			PrivateKeys:    cfg.KeyManagerConfig.LocalPrivateKeys,
+			ApproverKeys:   cfg.KeyManagerConfig.LocalApproverKeys,
 		}, nil
 	}
 }
@@ -102,8 +110,8 @@ func abiEncodeFillBSC2ETHSwap(ethTxHash ethcom.Hash, erc20Addr ethcom.Address, t
 	return data, nil
 }
 
-func abiEncodeFillSwap(toChainId *big.Int, toAddress ethcom.Address, amount *big.Int, abi *abi.ABI) ([]byte, error) {
-	data, err := abi.Pack("fillSwap", big.NewInt(0), toChainId, toAddress, amount)
+func abiEncodeFillSwap(swapId string, toChainId *big.Int, toAddress ethcom.Address, amount *big.Int, abi *abi.ABI) ([]byte, error) {
+	data, err := abi.Pack("fillSwap", ethcom.HexToHash(swapId).Big(), toChainId, toAddress, amount)
 	if err != nil {
 		return nil, err
 	}
@@ -118,61 +126,39 @@ func abiEncodeCreateSwapPair(registerTxHash ethcom.Hash, erc20Addr ethcom.Addres
 	return data, nil
 }
 
-func buildSignedTransaction(contract ethcom.Address, ethClient *ethclient.Client, txInput []byte, privateKey *ecdsa.PrivateKey, chainId *big.Int) (*types.Transaction, error) {
-	txOpts := bind.NewKeyedTransactor(privateKey)
+func buildSignedTransaction(chain *ChainIns, contract ethcom.Address, txInput []byte, nonce uint64) (*types.Transaction, error) {
+	return buildTransaction(chain, contract, big.NewInt(0), txInput, nonce)
+}
 
-	nonce, err := ethClient.PendingNonceAt(context.Background(), txOpts.From)
-	if err != nil {
-		return nil, err
-	}
-	gasPrice, err := ethClient.SuggestGasPrice(context.Background())
-	if err != nil {
-		return nil, err
-	}
-	value := big.NewInt(0)
-	msg := ethereum.CallMsg{From: txOpts.From, To: &contract, GasPrice: gasPrice, Value: value, Data: txInput}
-	gasLimit, err := ethClient.EstimateGas(context.Background(), msg)
-	if err != nil {
-		return nil, fmt.Errorf("failed to estimate gas needed: %v", err)
-	}
-	fmt.Printf("estimateGas: %s", txInput)
+func buildNativeCoinTransferTx(chain *ChainIns, recipient ethcom.Address, value *big.Int, nonce uint64) (*types.Transaction, error) {
+	return buildTransaction(chain, recipient, value, nil, nonce)
+}
 
-	rawTx := types.NewTransaction(nonce, contract, value, gasLimit, gasPrice, txInput)
-	signedTx, err := txOpts.Signer(types.NewEIP155Signer(chainId), txOpts.From, rawTx)
+// buildTransaction builds and signs a tx priced by the gas strategy of the chain
+func buildTransaction(chain *ChainIns, to ethcom.Address, value *big.Int, txInput []byte, nonce uint64) (*types.Transaction, error) {
+	price, err := suggestGasPrice(chain)
 	if err != nil {
 		return nil, err
 	}
-	fmt.Printf("signer: %s", txInput)
-
-	return signedTx, nil
+	return signTransaction(chain, price, to, value, txInput, nonce)
 }
 
-func buildNativeCoinTransferTx(contract ethcom.Address, ethClient *ethclient.Client, value *big.Int, privateKey *ecdsa.PrivateKey) (*types.Transaction, error) {
-	txOpts := bind.NewKeyedTransactor(privateKey)
-
-	nonce, err := ethClient.PendingNonceAt(context.Background(), txOpts.From)
-	if err != nil {
-		return nil, err
-	}
-	gasPrice, err := ethClient.SuggestGasPrice(context.Background())
-	fmt.Printf("gasPrice: %d", gasPrice)
-	if err != nil {
-		return nil, err
+func signTransaction(chain *ChainIns, price *txGasPrice, to ethcom.Address, value *big.Int, txInput []byte, nonce uint64) (*types.Transaction, error) {
+	from := crypto.PubkeyToAddress(chain.PrivateKey.PublicKey)
+	msg := ethereum.CallMsg{From: from, To: &to, Value: value, Data: txInput}
+	if price.isDynamicFee() {
+		msg.GasFeeCap = price.GasFeeCap
+		msg.GasTipCap = price.GasTipCap
+	} else {
+		msg.GasPrice = price.GasPrice
 	}
-	msg := ethereum.CallMsg{From: txOpts.From, To: &contract, GasPrice: gasPrice, Value: value}
-	gasLimit, err := ethClient.EstimateGas(context.Background(), msg)
-	fmt.Printf("gasLimit: %d", gasLimit)
+	gasLimit, err := chain.Client.EstimateGas(context.Background(), msg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to estimate gas needed: %v", err)
 	}
 
-	rawTx := types.NewTransaction(nonce, contract, value, gasLimit, gasPrice, nil)
-	signedTx, err := txOpts.Signer(types.HomesteadSigner{}, txOpts.From, rawTx)
-	if err != nil {
-		return nil, err
-	}
-
-	return signedTx, nil
+	rawTx := price.newTx(chain.ChainID, nonce, to, value, gasLimit, txInput)
+	return types.SignTx(rawTx, types.LatestSignerForChainID(chain.ChainID), chain.PrivateKey)
 }
 
 func BuildKeys(privateKeyStr string) (*ecdsa.PrivateKey, *ecdsa.PublicKey, error) {
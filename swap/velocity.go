@@ -0,0 +1,279 @@
+package swap
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	ethcom "github.com/ethereum/go-ethereum/common"
+	"github.com/jinzhu/gorm"
+
+	"occ-swap-server/model"
+	"occ-swap-server/util"
+)
+
+// HeldVolume is the amount of a token held by the velocity limits of a chain
+type HeldVolume struct {
+	Chain  string `json:"chain"`
+	Token  string `json:"token"`
+	Count  int    `json:"count"`
+	Amount string `json:"amount"`
+}
+
+// sumAmounts adds up the amounts of swaps, the invalid ones are skipped
+func sumAmounts(amounts []string) *big.Int {
+	sum := big.NewInt(0)
+	for _, amount := range amounts {
+		if value, ok := big.NewInt(0).SetString(amount, 10); ok {
+			sum.Add(sum, value)
+		}
+	}
+	return sum
+}
+
+// paidVolume returns the amount counted by the velocity limit which was paid out to the chain in its window. It is
+// the amount of the fill txs and retry fill txs sent in the window, the replacements of a fill tx pay out the same
+// swap and are not counted, plus the amount of the swaps and retry swaps being sent whose txs are not recorded yet
+func (engine *SwapEngine) paidVolume(tx *gorm.DB, chain *ChainIns, limit *util.VelocityLimit, swap *model.Swap) (*big.Int, error) {
+	scope := func(query *gorm.DB) *gorm.DB {
+		query = query.Where("swaps.to_chain_id in (?) and swaps.deleted_at is null", chainIdStrings(chain.Config))
+		if limit.Token != "" {
+			query = query.Where("swaps.to_token_addr = ?", ethcom.HexToAddress(limit.Token).String())
+		}
+		if limit.PerSponsor {
+			query = query.Where("swaps.sponsor = ?", swap.Sponsor)
+		}
+		return query
+	}
+	since := time.Now().Add(-time.Duration(limit.Window) * time.Second)
+
+	filled := make([]string, 0)
+	err := scope(tx.Table(model.SwapFillTx{}.TableName()).
+		Joins("join swaps on swaps.start_tx_hash = swap_fill_txs.start_swap_tx_hash and swaps.start_log_index = swap_fill_txs.start_log_index")).
+		Where("swap_fill_txs.chain = ? and swap_fill_txs.created_at > ? and swap_fill_txs.replaced_tx_hash = '' and swap_fill_txs.status != ? and swap_fill_txs.deleted_at is null",
+			chain.Name, since, model.FillTxFailed).
+		Pluck("swaps.amount", &filled).Error
+	if err != nil {
+		return nil, err
+	}
+
+	sending := make([]string, 0)
+	err = scope(tx.Model(model.Swap{})).
+		Where("swaps.status = ? and not exists (?)", SwapSending,
+			tx.Model(model.SwapFillTx{}).Select("id").
+				Where("swap_fill_txs.start_swap_tx_hash = swaps.start_tx_hash and swap_fill_txs.start_log_index = swaps.start_log_index").QueryExpr()).
+		Pluck("swaps.amount", &sending).Error
+	if err != nil {
+		return nil, err
+	}
+
+	retried := make([]string, 0)
+	err = scope(tx.Table(model.RetrySwapTx{}.TableName()).
+		Joins("join swaps on swaps.start_tx_hash = retry_swap_txs.start_tx_hash and swaps.start_log_index = retry_swap_txs.start_log_index")).
+		Where("retry_swap_txs.chain = ? and retry_swap_txs.created_at > ? and retry_swap_txs.status != ? and retry_swap_txs.deleted_at is null",
+			chain.Name, since, model.FillRetryTxFailed).
+		Pluck("swaps.amount", &retried).Error
+	if err != nil {
+		return nil, err
+	}
+
+	retrying := make([]string, 0)
+	err = scope(tx.Table(model.RetrySwap{}.TableName()).Joins("join swaps on swaps.id = retry_swaps.swap_id")).
+		Where("retry_swaps.status = ? and retry_swaps.deleted_at is null and not exists (?)", RetrySwapSending,
+			tx.Model(model.RetrySwapTx{}).Select("id").Where("retry_swap_txs.retry_swap_id = retry_swaps.id").QueryExpr()).
+		Pluck("swaps.amount", &retrying).Error
+	if err != nil {
+		return nil, err
+	}
+
+	amounts := append(append(append(filled, sending...), retried...), retrying...)
+	return sumAmounts(amounts), nil
+}
+
+// exceededVelocityLimit returns the first velocity limit of the chain which paying out the swap would exceed and the
+// volume it would reach, the limit is nil if the swap is within all limits
+func (engine *SwapEngine) exceededVelocityLimit(tx *gorm.DB, chain *ChainIns, swap *model.Swap) (*util.VelocityLimit, *big.Int, error) {
+	amount, ok := big.NewInt(0).SetString(swap.Amount, 10)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid swap amount: %s", swap.Amount)
+	}
+	for idx := range chain.Config.VelocityLimits {
+		limit := &chain.Config.VelocityLimits[idx]
+		if limit.Token != "" && !strings.EqualFold(limit.Token, swap.ToTokenAddr) {
+			continue
+		}
+		paid, err := engine.paidVolume(tx, chain, limit, swap)
+		if err != nil {
+			return nil, nil, err
+		}
+		volume := paid.Add(paid, amount)
+		maxAmount, _ := big.NewInt(0).SetString(limit.MaxAmount, 10)
+		if volume.Cmp(maxAmount) > 0 {
+			return limit, volume, nil
+		}
+	}
+	return nil, nil, nil
+}
+
+// holdRetrySwap moves the retry swap to held if paying it out would exceed a velocity limit of its chain, the limits
+// are checked with the swap it retries
+func (engine *SwapEngine) holdRetrySwap(tx *gorm.DB, chain *ChainIns, retrySwap *model.RetrySwap) (bool, error) {
+	swap, err := engine.getSwapByStartTx(tx, retrySwap.StartTxHash, retrySwap.StartLogIndex)
+	if err != nil {
+		return false, err
+	}
+	limit, volume, err := engine.exceededVelocityLimit(tx, chain, swap)
+	if err != nil || limit == nil {
+		return false, err
+	}
+	retrySwap.Status = RetrySwapHeld
+	retrySwap.ErrorMsg = fmt.Sprintf("velocity limit exceeded: %s paid in %d seconds, above max amount %s",
+		volume.String(), limit.Window, limit.MaxAmount)
+	engine.updateRetrySwap(tx, retrySwap)
+	return true, nil
+}
+
+// alertHeldSwap reports the held swap together with the volume held on its chain
+func (engine *SwapEngine) alertHeldSwap(chain *ChainIns, swap *model.Swap) {
+	engine.alertHeld(chain, "swap", swap.StartTxHash, swap.Sponsor, swap.Amount, swap.Log)
+}
+
+// alertHeld reports a held swap or retry swap together with the volume held on its chain
+func (engine *SwapEngine) alertHeld(chain *ChainIns, kind, startTxHash, sponsor, amount, reason string) {
+	msg := fmt.Sprintf("%s is held, chain %s, start tx hash %s, sponsor %s, amount %s: %s",
+		kind, chain.Name, startTxHash, sponsor, amount, reason)
+	_, _, volumes, err := engine.GetHeldSwaps()
+	if err == nil {
+		for _, volume := range volumes {
+			if volume.Chain == chain.Name {
+				msg = fmt.Sprintf("%s\nheld on %s: %d swaps of token %s, amount %s", msg, volume.Chain, volume.Count, volume.Token, volume.Amount)
+			}
+		}
+	}
+	util.Logger.Infof("%s", msg)
+	util.SendTelegramMessage(msg)
+}
+
+// GetHeldSwaps returns the swaps and retry swaps held by the velocity limits and the volume held on every chain by
+// token
+func (engine *SwapEngine) GetHeldSwaps() ([]model.Swap, []model.RetrySwap, []*HeldVolume, error) {
+	swaps := make([]model.Swap, 0)
+	if err := engine.db.Where("status = ?", SwapHeld).Order("id asc").Find(&swaps).Error; err != nil {
+		return nil, nil, nil, err
+	}
+	retrySwaps := make([]model.RetrySwap, 0)
+	if err := engine.db.Where("status = ?", RetrySwapHeld).Order("id asc").Find(&retrySwaps).Error; err != nil {
+		return nil, nil, nil, err
+	}
+	// the held amounts are counted by the swaps, a retry swap pays out the swap it retries
+	heldSwaps := swaps
+	if len(retrySwaps) > 0 {
+		retriedIds := make([]uint, 0, len(retrySwaps))
+		for _, retrySwap := range retrySwaps {
+			retriedIds = append(retriedIds, retrySwap.SwapID)
+		}
+		retried := make([]model.Swap, 0)
+		if err := engine.db.Where("id in (?)", retriedIds).Find(&retried).Error; err != nil {
+			return nil, nil, nil, err
+		}
+		heldSwaps = append(append(make([]model.Swap, 0, len(swaps)+len(retried)), swaps...), retried...)
+	}
+
+	volumes := make(map[string]*HeldVolume)
+	amounts := make(map[string][]string)
+	for _, swap := range heldSwaps {
+		chainName := swap.ToChainId
+		if chain, err := engine.getChainByChainId(swap.ToChainId); err == nil {
+			chainName = chain.Name
+		}
+		key := chainName + "#" + swap.ToTokenAddr
+		if _, ok := volumes[key]; !ok {
+			volumes[key] = &HeldVolume{Chain: chainName, Token: swap.ToTokenAddr}
+		}
+		volumes[key].Count++
+		amounts[key] = append(amounts[key], swap.Amount)
+	}
+
+	heldVolumes := make([]*HeldVolume, 0, len(volumes))
+	for key, volume := range volumes {
+		volume.Amount = sumAmounts(amounts[key]).String()
+		heldVolumes = append(heldVolumes, volume)
+	}
+	sort.Slice(heldVolumes, func(i, j int) bool {
+		if heldVolumes[i].Chain != heldVolumes[j].Chain {
+			return heldVolumes[i].Chain < heldVolumes[j].Chain
+		}
+		return heldVolumes[i].Token < heldVolumes[j].Token
+	})
+	return swaps, retrySwaps, heldVolumes, nil
+}
+
+// ReleaseHeldSwaps queues the held swaps of the list again without checking them against the velocity limits, it
+// returns the ids of the released swaps and the ids of the swaps which can not be released
+func (engine *SwapEngine) ReleaseHeldSwaps(swapIDList []uint) ([]uint, []uint, error) {
+	swaps := make([]model.Swap, 0)
+	engine.db.Where("id in (?)", swapIDList).Find(&swaps)
+
+	if len(swaps) == 0 {
+		return nil, nil, fmt.Errorf("no matched swap")
+	}
+
+	releasedSwapList := make([]uint, 0, len(swapIDList))
+	rejectedReleaseSwapList := make([]uint, 0, len(swapIDList))
+	writeDBErr := func() error {
+		tx := engine.db.Begin()
+		if err := tx.Error; err != nil {
+			return err
+		}
+		for _, swap := range swaps {
+			if !engine.verifySwap(&swap) || swap.Status != SwapHeld {
+				rejectedReleaseSwapList = append(rejectedReleaseSwapList, swap.ID)
+				continue
+			}
+			util.Logger.Infof("release held swap, start tx hash %s, amount %s", swap.StartTxHash, swap.Amount)
+			swap.Status = SwapConfirmed
+			swap.VelocityReleased = true
+			swap.Log = fmt.Sprintf("released by admin, %s", swap.Log)
+			engine.updateSwap(tx, &swap)
+			releasedSwapList = append(releasedSwapList, swap.ID)
+		}
+		return tx.Commit().Error
+	}()
+	return releasedSwapList, rejectedReleaseSwapList, writeDBErr
+}
+
+// ReleaseHeldRetrySwaps queues the held retry swaps of the list again without checking them against the velocity
+// limits, it returns the ids of the released retry swaps and the ids of the retry swaps which can not be released
+func (engine *SwapEngine) ReleaseHeldRetrySwaps(retrySwapIDList []uint) ([]uint, []uint, error) {
+	retrySwaps := make([]model.RetrySwap, 0)
+	engine.db.Where("id in (?)", retrySwapIDList).Find(&retrySwaps)
+
+	if len(retrySwaps) == 0 {
+		return nil, nil, fmt.Errorf("no matched retry swap")
+	}
+
+	releasedRetrySwapList := make([]uint, 0, len(retrySwapIDList))
+	rejectedReleaseRetrySwapList := make([]uint, 0, len(retrySwapIDList))
+	writeDBErr := func() error {
+		tx := engine.db.Begin()
+		if err := tx.Error; err != nil {
+			return err
+		}
+		for _, retrySwap := range retrySwaps {
+			if !engine.verifyRetrySwap(&retrySwap) || retrySwap.Status != RetrySwapHeld {
+				rejectedReleaseRetrySwapList = append(rejectedReleaseRetrySwapList, retrySwap.ID)
+				continue
+			}
+			util.Logger.Infof("release held retry swap, start tx hash %s, amount %s", retrySwap.StartTxHash, retrySwap.Amount)
+			retrySwap.Status = RetrySwapConfirmed
+			retrySwap.VelocityReleased = true
+			retrySwap.ErrorMsg = fmt.Sprintf("released by admin, %s", retrySwap.ErrorMsg)
+			engine.updateRetrySwap(tx, &retrySwap)
+			releasedRetrySwapList = append(releasedRetrySwapList, retrySwap.ID)
+		}
+		return tx.Commit().Error
+	}()
+	return releasedRetrySwapList, rejectedReleaseRetrySwapList, writeDBErr
+}
@@ -0,0 +1,397 @@
+package swap
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	ethcom "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/jinzhu/gorm"
+
+	"occ-swap-server/common"
+	"occ-swap-server/model"
+	"occ-swap-server/provider"
+	"occ-swap-server/util"
+)
+
+// startSwapWorkers recovers the swaps left sending by the last run and starts the swap workers of the destination
+// chain, the confirmed swaps in db are the queue of the workers
+func (engine *SwapEngine) startSwapWorkers(chain *ChainIns) {
+	engine.recoverSendingSwaps(chain)
+
+	workers := chain.Config.GetSwapWorkers()
+	util.Logger.Infof("start %d swap workers, chain %s", workers, chain.Name)
+	for idx := 0; idx < workers; idx++ {
+		go engine.swapWorker(chain)
+	}
+}
+
+// recoverSendingSwaps settles the swaps which were being sent when the process stopped, the swaps whose fill tx is
+// recorded are marked as sent and tracked, the others are queued again
+func (engine *SwapEngine) recoverSendingSwaps(chain *ChainIns) {
+	swaps := make([]model.Swap, 0)
+	engine.db.Where("status = ? and to_chain_id in (?)", SwapSending, chainIdStrings(chain.Config)).Order("id asc").Find(&swaps)
+
+	for _, swap := range swaps {
+		writeDBErr := func() error {
+			tx := engine.db.Begin()
+			if err := tx.Error; err != nil {
+				return err
+			}
+			var swapTx model.SwapFillTx
+			engine.db.Where("start_swap_tx_hash = ? and start_log_index = ? and status != ?", swap.StartTxHash, swap.StartLogIndex, model.FillTxFailed).
+				Order("id desc").First(&swapTx)
+			if swapTx.FillSwapTxHash == "" {
+				util.Logger.Infof("retry swap, start tx hash %s, symbol %s, amount %s, direction %s",
+					swap.StartTxHash, swap.Symbol, swap.Amount, swap.Direction)
+				swap.Status = SwapConfirmed
+				engine.updateSwap(tx, &swap)
+			} else {
+				util.Logger.Infof("swap tx is built successfully, but the swap tx status is uncertain, just mark the swap and swap tx status as sent, swap ID %d", swap.ID)
+				tx.Model(model.SwapFillTx{}).Where("fill_swap_tx_hash = ?", swapTx.FillSwapTxHash).Updates(
+					map[string]interface{}{
+						"status":     model.FillTxSent,
+						"updated_at": time.Now().Unix(),
+					})
+				swap.Status = SwapSent
+				swap.FillTxHash = swapTx.FillSwapTxHash
+				engine.updateSwap(tx, &swap)
+			}
+			return tx.Commit().Error
+		}()
+		if writeDBErr != nil {
+			util.Logger.Errorf("write db error: %s", writeDBErr.Error())
+			util.SendTelegramMessage(fmt.Sprintf("write db error: %s", writeDBErr.Error()))
+		}
+	}
+}
+
+// swapWorker claims the confirmed swaps to the chain one by one and sends their fill txs
+func (engine *SwapEngine) swapWorker(chain *ChainIns) {
+	for {
+		swap, err := engine.claimSwap(chain)
+		if err != nil {
+			util.Logger.Errorf("claim swap to %s error: %s", chain.Name, err.Error())
+		}
+		if swap == nil {
+			time.Sleep(SwapSleepSecond * time.Second)
+			continue
+		}
+
+		engine.processSwap(chain, swap)
+		time.Sleep(time.Duration(chain.Config.WaitMilliSecBetweenSwaps) * time.Millisecond)
+	}
+}
+
+// countPendingFills returns the number of the fill txs to the chain which are not finalized yet
+func (engine *SwapEngine) countPendingFills(chain *ChainIns) (int64, error) {
+	var pending int64
+	err := engine.db.Model(model.SwapFillTx{}).Where("chain = ? and status in (?)", chain.Name,
+		[]model.FillTxStatus{model.FillTxCreated, model.FillTxSent}).Count(&pending).Error
+	return pending, err
+}
+
+// claimSwap takes the oldest confirmed swap to the chain off the queue and marks it as sending, it is nil if there is
+// none, the fills to the chain are paused or the chain has too many pending fills. The swaps of paused directions
+// are skipped. A swap above the approval threshold of its token waits for approvals
+// and a swap exceeding a velocity limit of the chain is held instead. The row is locked with skip locked on mysql so concurrent workers
+// never wait for each other, the status is only changed if no other worker claimed the swap in between
+func (engine *SwapEngine) claimSwap(chain *ChainIns) (*model.Swap, error) {
+	chain.claimMutex.Lock()
+	defer chain.claimMutex.Unlock()
+
+	pauses, err := engine.GetPauses()
+	if err != nil {
+		return nil, err
+	}
+	if pause := pauseSet(pauses).get(chain.Name, ""); pause != nil {
+		if !chain.paused {
+			util.Logger.Infof("fills to %s are paused by %s: %s", chain.Name, pause.PausedBy, pause.Reason)
+			chain.paused = true
+		}
+		return nil, nil
+	}
+	if chain.paused {
+		util.Logger.Infof("fills to %s are resumed", chain.Name)
+		chain.paused = false
+	}
+
+	pending, err := engine.countPendingFills(chain)
+	if err != nil {
+		return nil, err
+	}
+	maxPending := chain.Config.GetMaxPendingFills()
+	if pending >= maxPending {
+		if !chain.backpressure {
+			util.Logger.Infof("%d fill txs to %s are pending, stop claiming swaps until they are finalized", pending, chain.Name)
+			chain.backpressure = true
+		}
+		return nil, nil
+	}
+	if chain.backpressure {
+		util.Logger.Infof("%d fill txs to %s are pending, resume claiming swaps", pending, chain.Name)
+		chain.backpressure = false
+	}
+
+	tx := engine.db.Begin()
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+	query := tx.Where("status = ? and to_chain_id in (?)", SwapConfirmed, chainIdStrings(chain.Config)).Order("id asc")
+	if directions := pauseSet(pauses).directions(); len(directions) > 0 {
+		query = query.Where("direction not in (?)", directions)
+	}
+	if engine.db.Dialect().GetName() == common.DBDialectMysql {
+		query = query.Set("gorm:query_option", "FOR UPDATE SKIP LOCKED")
+	}
+	swap := model.Swap{}
+	if err := query.First(&swap).Error; err != nil {
+		tx.Rollback()
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	// the swap failing the hmac verification is rejected by processSwap, its record hash must not be renewed
+	if !engine.verifySwap(&swap) {
+		tx.Rollback()
+		return &swap, nil
+	}
+	if !swap.Approved {
+		if threshold := chain.Config.GetApprovalThreshold(swap.ToTokenAddr); threshold != nil && engine.aboveThreshold(&swap, threshold) {
+			parked, err := engine.parkSwap(tx, &swap, SwapPendingApproval, fmt.Sprintf("amount is above the approval threshold %s", threshold.String()))
+			if err != nil || !parked {
+				tx.Rollback()
+				return nil, err
+			}
+			if err := tx.Commit().Error; err != nil {
+				return nil, err
+			}
+			engine.alertPendingApproval(chain, &swap)
+			return nil, nil
+		}
+	}
+	// the claims are serialized, so the fills of the other workers are counted by the limits
+	if !swap.VelocityReleased {
+		limit, volume, err := engine.exceededVelocityLimit(tx, chain, &swap)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if limit != nil {
+			held, err := engine.parkSwap(tx, &swap, SwapHeld, fmt.Sprintf("velocity limit exceeded: %s paid in %d seconds, above max amount %s",
+				volume.String(), limit.Window, limit.MaxAmount))
+			if err != nil || !held {
+				tx.Rollback()
+				return nil, err
+			}
+			if err := tx.Commit().Error; err != nil {
+				return nil, err
+			}
+			engine.alertHeldSwap(chain, &swap)
+			return nil, nil
+		}
+	}
+
+	swap.Status = SwapSending
+	claim := tx.Model(model.Swap{}).Where("id = ? and status = ?", swap.ID, SwapConfirmed).Updates(
+		map[string]interface{}{
+			"status":      swap.Status,
+			"record_hash": engine.getSwapHMAC(&swap),
+		})
+	if claim.Error != nil {
+		tx.Rollback()
+		return nil, claim.Error
+	}
+	if claim.RowsAffected == 0 {
+		tx.Rollback()
+		return nil, nil
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	swap.RecordHash = engine.getSwapHMAC(&swap)
+	return &swap, nil
+}
+
+// parkSwap moves the confirmed swap to a status waiting for the admin instead of claiming it, it is false if another
+// worker claimed the swap in between
+func (engine *SwapEngine) parkSwap(tx *gorm.DB, swap *model.Swap, status common.SwapStatus, reason string) (bool, error) {
+	swap.Status = status
+	swap.Log = reason
+	park := tx.Model(model.Swap{}).Where("id = ? and status = ?", swap.ID, SwapConfirmed).Updates(
+		map[string]interface{}{
+			"status":      swap.Status,
+			"log":         swap.Log,
+			"record_hash": engine.getSwapHMAC(swap),
+		})
+	return park.RowsAffected > 0, park.Error
+}
+
+// rejectTamperedSwap stops the workers from claiming the swap failing the hmac verification. Only its status and log
+// are updated, its record hash is kept so the row still fails the verification and the tampering stays visible
+func (engine *SwapEngine) rejectTamperedSwap(swap *model.Swap) {
+	util.Logger.Errorf("verify hmac of swap failed: %s", swap.StartTxHash)
+	util.SendTelegramMessage(fmt.Sprintf("Urgent alert: verify hmac of swap failed: %s", swap.StartTxHash))
+	err := engine.db.Model(model.Swap{}).Where("id = ? and status = ?", swap.ID, swap.Status).UpdateColumns(
+		map[string]interface{}{
+			"status": SwapQuoteRejected,
+			"log":    fmt.Sprintf("verify hmac of swap failed: %s", swap.StartTxHash),
+		}).Error
+	if err != nil {
+		util.Logger.Errorf("write db error: %s", err.Error())
+		util.SendTelegramMessage(fmt.Sprintf("write db error: %s", err.Error()))
+	}
+}
+
+// processSwap sends the fill tx of the claimed swap and records the outcome
+func (engine *SwapEngine) processSwap(chain *ChainIns, swap *model.Swap) {
+	if !engine.verifySwap(swap) {
+		engine.rejectTamperedSwap(swap)
+		return
+	}
+
+	var swapPairInstance *SwapPairIns
+	retryCheckErr := func() error {
+		// swaps created before swaps carry tokens have no swap pair
+		if erc20Addr := ethcom.HexToAddress(swap.ERC20Addr); erc20Addr != (ethcom.Address{}) {
+			pairInstance, err := engine.GetSwapPairInstance(erc20Addr)
+			if err != nil {
+				return fmt.Errorf("%w: %s", errSwapQuoteRejected, err.Error())
+			}
+			// the swap pair may be disabled after the swap is created
+			if !pairInstance.Available {
+				return fmt.Errorf("%w: swap pair %s is not available", errSwapQuoteRejected, pairInstance.Symbol)
+			}
+			swapPairInstance = pairInstance
+		}
+		return nil
+	}()
+	if retryCheckErr != nil {
+		writeDBErr := func() error {
+			tx := engine.db.Begin()
+			if err := tx.Error; err != nil {
+				return err
+			}
+			swap.Status = SwapQuoteRejected
+			swap.Log = retryCheckErr.Error()
+			engine.updateSwap(tx, swap)
+			if errors.Is(retryCheckErr, errSwapQuoteRejected) {
+				var txEventLog model.SwapStartTxLog
+				if err := tx.Where("tx_hash = ? and log_index = ?", swap.StartTxHash, swap.StartLogIndex).First(&txEventLog).Error; err != nil {
+					tx.Rollback()
+					return err
+				}
+				if err := engine.refundRejectedSwap(tx, &txEventLog, swap); err != nil {
+					tx.Rollback()
+					return err
+				}
+			}
+			return tx.Commit().Error
+		}()
+		if writeDBErr != nil {
+			util.Logger.Errorf("write db error: %s", writeDBErr.Error())
+			util.SendTelegramMessage(fmt.Sprintf("write db error: %s", writeDBErr.Error()))
+		}
+		return
+	}
+
+	util.Logger.Infof("Swap token %s, direction %s, sponsor: %s, amount %s, decimals %d", swap.BEP20Addr, swap.Direction, swap.Sponsor, swap.Amount, swap.Decimals)
+	swapTx, swapErr := engine.doSwap(swap, swapPairInstance)
+
+	// the failed fill is recorded once it is committed
+	fillFailed := false
+	writeDBErr := func() error {
+		tx := engine.db.Begin()
+		if err := tx.Error; err != nil {
+			return err
+		}
+		if swapErr != nil && errors.Is(swapErr, errGasPriceAboveCeiling) {
+			// nothing is sent, pick up this swap again once the gas price drops
+			util.Logger.Infof("defer swap, start hash %s: %s", swap.StartTxHash, swapErr.Error())
+			swap.Status = SwapConfirmed
+			swap.Log = fmt.Sprintf("swap deferred: %s", swapErr.Error())
+			engine.updateSwap(tx, swap)
+		} else if swapErr != nil && errors.Is(swapErr, errSwapAlreadyFilled) {
+			util.Logger.Errorf("refuse to fill swap, start hash %s: %s", swap.StartTxHash, swapErr.Error())
+			util.SendTelegramMessage(fmt.Sprintf("refuse to fill swap, start hash %s: %s", swap.StartTxHash, swapErr.Error()))
+			fillLog, err := engine.getConfirmedSwapFill(swap.StartTxHash, swap.StartLogIndex, swap.SwapId)
+			if err != nil || fillLog == nil {
+				tx.Rollback()
+				return fmt.Errorf("query fill of swap %s error", swap.StartTxHash)
+			}
+			swap.Status = SwapSuccess
+			swap.FillTxHash = fillLog.TxHash
+			swap.Log = fmt.Sprintf("filled on chain, fill txHash %s", fillLog.TxHash)
+			engine.updateSwap(tx, swap)
+		} else if swapErr != nil && errors.Is(swapErr, provider.ErrSendUncertain) {
+			// the fill tx may be relayed, it is settled by the chain rather than failed
+			util.Logger.Errorf("fill tx of swap is uncertain: %s, start hash %s", swapErr.Error(), swap.StartTxHash)
+			util.SendTelegramMessage(fmt.Sprintf("Urgent alert: fill tx of swap is uncertain: %s, start hash %s", swapErr.Error(), swap.StartTxHash))
+			tx.Model(model.SwapFillTx{}).Where("fill_swap_tx_hash = ?", swapTx.FillSwapTxHash).Updates(
+				map[string]interface{}{
+					"status":     model.FillTxMissing,
+					"updated_at": time.Now().Unix(),
+				})
+			swap.Status = SwapFillMissing
+			swap.FillTxHash = swapTx.FillSwapTxHash
+			swap.Log = fmt.Sprintf("fill tx is uncertain: %s", swapErr.Error())
+			engine.updateSwap(tx, swap)
+		} else if swapErr != nil {
+			util.Logger.Errorf("do swap failed: %s, start hash %s", swapErr.Error(), swap.StartTxHash)
+			util.SendTelegramMessage(fmt.Sprintf("do swap failed: %s, start hash %s", swapErr.Error(), swap.StartTxHash))
+			if swapErr.Error() == core.ErrReplaceUnderpriced.Error() {
+				// the nonce is taken by another tx and has been reconciled, drop this fill tx
+				if swapTx != nil {
+					tx.Model(model.SwapFillTx{}).Where("fill_swap_tx_hash = ?", swapTx.FillSwapTxHash).Updates(
+						map[string]interface{}{
+							"status":     model.FillTxFailed,
+							"updated_at": time.Now().Unix(),
+						})
+				}
+				// retry this swap
+				swap.Status = SwapConfirmed
+				swap.Log = fmt.Sprintf("do swap failure: %s", swapErr.Error())
+
+				engine.updateSwap(tx, swap)
+			} else {
+				fillTxHash := ""
+				if swapTx != nil {
+					tx.Model(model.SwapFillTx{}).Where("fill_swap_tx_hash = ?", swapTx.FillSwapTxHash).Updates(
+						map[string]interface{}{
+							"status":     model.FillTxFailed,
+							"updated_at": time.Now().Unix(),
+						})
+					fillTxHash = swapTx.FillSwapTxHash
+				}
+				fillFailed = true
+
+				swap.Status = SwapSendFailed
+				swap.FillTxHash = fillTxHash
+				swap.Log = fmt.Sprintf("do swap failure: %s", swapErr.Error())
+				engine.updateSwap(tx, swap)
+			}
+		} else {
+			tx.Model(model.SwapFillTx{}).Where("fill_swap_tx_hash = ?", swapTx.FillSwapTxHash).Updates(
+				map[string]interface{}{
+					"status":     model.FillTxSent,
+					"updated_at": time.Now().Unix(),
+				})
+
+			swap.Status = SwapSent
+			swap.FillTxHash = swapTx.FillSwapTxHash
+			engine.updateSwap(tx, swap)
+		}
+
+		return tx.Commit().Error
+	}()
+	if writeDBErr != nil {
+		util.Logger.Errorf("write db error: %s", writeDBErr.Error())
+		util.SendTelegramMessage(fmt.Sprintf("write db error: %s", writeDBErr.Error()))
+		return
+	}
+	if fillFailed {
+		engine.recordFillResult(chain, swapErr)
+	}
+}
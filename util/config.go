@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"math/big"
+	"strings"
 
 	ethcom "github.com/ethereum/go-ethereum/common"
 
@@ -17,6 +19,7 @@ type Config struct {
 	LogConfig        LogConfig        `json:"log_config"`
 	AlertConfig      AlertConfig      `json:"alert_config"`
 	AdminConfig      AdminConfig      `json:"admin_config"`
+	ScreeningConfig  ScreeningConfig  `json:"screening_config"`
 }
 
 func (cfg *Config) Validate() {
@@ -24,6 +27,13 @@ func (cfg *Config) Validate() {
 	cfg.ChainConfig.Validate()
 	cfg.LogConfig.Validate()
 	cfg.AlertConfig.Validate()
+	cfg.AdminConfig.Validate()
+	cfg.ScreeningConfig.Validate()
+	for _, chain := range cfg.ChainConfig.Chains {
+		if len(chain.ApprovalThresholds) > 0 && cfg.AdminConfig.RequiredApprovals == 0 {
+			panic(fmt.Sprintf("required_approvals should be larger than 0 if approval_thresholds of %s are set", chain.Name))
+		}
+	}
 }
 
 type AlertConfig struct {
@@ -45,32 +55,41 @@ type KeyManagerConfig struct {
 	AWSSecretName string `json:"aws_secret_name"`
 
 	// local keys
-	LocalHMACKey         string `json:"local_hmac_key"`
-	LocalBSCTxHash   string `json:"local_bsc_private_key"`
-	LocalETHPrivateKey   string `json:"local_eth_private_key"`
-	LocalMATICPrivateKey string `json:"local_matic_private_key"`
-	LocalAdminApiKey     string `json:"local_admin_api_key"`
-	LocalAdminSecretKey  string `json:"local_admin_secret_key"`
+	LocalHMACKey string `json:"local_hmac_key"`
+	// key is the chain name
+	LocalPrivateKeys    map[string]string `json:"local_private_keys"`
+	LocalAdminApiKey    string            `json:"local_admin_api_key"`
+	LocalAdminSecretKey string            `json:"local_admin_secret_key"`
+	// key is the api key of the approver, value is its secret key
+	LocalApproverKeys map[string]string `json:"local_approver_keys"`
 }
 
 type KeyConfig struct {
-	HMACKey         string `json:"hmac_key"`
-	BSCPrivateKey   string `json:"bsc_private_key"`
-	ETHPrivateKey   string `json:"eth_private_key"`
-	MATICPrivateKey string `json:"matic_private_key"`
-	AdminApiKey     string `json:"admin_api_key"`
-	AdminSecretKey  string `json:"admin_secret_key"`
+	HMACKey string `json:"hmac_key"`
+	// key is the chain name
+	PrivateKeys    map[string]string `json:"private_keys"`
+	AdminApiKey    string            `json:"admin_api_key"`
+	AdminSecretKey string            `json:"admin_secret_key"`
+	// key is the api key of the approver, value is its secret key
+	ApproverKeys map[string]string `json:"approver_keys"`
+}
+
+// GetPrivateKey returns the private key of the given chain, the chain name is case insensitive
+func (cfg KeyConfig) GetPrivateKey(chain string) (string, bool) {
+	for name, key := range cfg.PrivateKeys {
+		if strings.EqualFold(name, chain) {
+			return key, true
+		}
+	}
+	return "", false
 }
 
 func (cfg KeyManagerConfig) Validate() {
 	if cfg.KeyType == common.LocalPrivateKey && len(cfg.LocalHMACKey) == 0 {
 		panic("missing local hmac key")
 	}
-	if cfg.KeyType == common.LocalPrivateKey && len(cfg.LocalBSCTxHash) == 0 {
-		panic("missing local bsc private key")
-	}
-	if cfg.KeyType == common.LocalPrivateKey && len(cfg.LocalETHPrivateKey) == 0 {
-		panic("missing local eth private key")
+	if cfg.KeyType == common.LocalPrivateKey && len(cfg.LocalPrivateKeys) == 0 {
+		panic("missing local private keys")
 	}
 
 	if cfg.KeyType == common.LocalPrivateKey && len(cfg.LocalAdminApiKey) == 0 {
@@ -109,68 +128,390 @@ func (cfg DBConfig) Validate() {
 type ChainConfig struct {
 	BalanceMonitorInterval int64 `json:"balance_monitor_interval"`
 
-	BSCObserverFetchInterval    int64  `json:"bsc_observer_fetch_interval"`
-	BSCStartHeight              int64  `json:"bsc_start_height"`
-	BSCProvider                 string `json:"bsc_provider"`
-	BSCConfirmNum               int64  `json:"bsc_confirm_num"`
-	BSCSwapAgentAddr            string `json:"bsc_swap_agent_addr"`
-	BSCExplorerUrl              string `json:"bsc_explorer_url"`
-	BSCMaxTrackRetry            int64  `json:"bsc_max_track_retry"`
-	BSCAlertThreshold           string `json:"bsc_alert_threshold"`
-	BSCWaitMilliSecBetweenSwaps int64  `json:"bsc_wait_milli_sec_between_swaps"`
-
-	ETHObserverFetchInterval    int64  `json:"eth_observer_fetch_interval"`
-	ETHStartHeight              int64  `json:"eth_start_height"`
-	ETHProvider                 string `json:"eth_provider"`
-	ETHConfirmNum               int64  `json:"eth_confirm_num"`
-	ETHSwapAgentAddr            string `json:"eth_swap_agent_addr"`
-	ETHExplorerUrl              string `json:"eth_explorer_url"`
-	ETHMaxTrackRetry            int64  `json:"eth_max_track_retry"`
-	ETHAlertThreshold           string `json:"eth_alert_threshold"`
-	ETHWaitMilliSecBetweenSwaps int64  `json:"eth_wait_milli_sec_between_swaps"`
-
-	MATICObserverFetchInterval    int64  `json:"matic_observer_fetch_interval"`
-	MATICStartHeight              int64  `json:"matic_start_height"`
-	MATICProvider                 string `json:"matic_provider"`
-	MATICConfirmNum               int64  `json:"matic_confirm_num"`
-	MATICSwapAgentAddr            string `json:"matic_swap_agent_addr"`
-	MATICExplorerUrl              string `json:"matic_explorer_url"`
-	MATICMaxTrackRetry            int64  `json:"matic_max_track_retry"`
-	MATICAlertThreshold           string `json:"matic_alert_threshold"`
-	MATICWaitMilliSecBetweenSwaps int64  `json:"matic_wait_milli_sec_between_swaps"`
+	Chains []ChainInfo `json:"chains"`
 }
 
 func (cfg ChainConfig) Validate() {
-	if cfg.BSCStartHeight < 0 {
-		panic("bsc_start_height should not be less than 0")
+	if len(cfg.Chains) == 0 {
+		panic("chains should not be empty")
+	}
+	if cfg.BalanceMonitorInterval < 0 {
+		panic("balance_monitor_interval should not be less than 0")
+	}
+
+	names := make(map[string]bool, len(cfg.Chains))
+	chainIds := make(map[int64]string, len(cfg.Chains))
+	for _, chain := range cfg.Chains {
+		chain.Validate()
+
+		name := strings.ToUpper(chain.Name)
+		if names[name] {
+			panic(fmt.Sprintf("duplicated chain name: %s", chain.Name))
+		}
+		names[name] = true
+
+		for _, chainId := range chain.ChainIds {
+			if other, ok := chainIds[chainId]; ok {
+				panic(fmt.Sprintf("chain id %d is used by both %s and %s", chainId, other, chain.Name))
+			}
+			chainIds[chainId] = chain.Name
+		}
+	}
+
+	for _, chain := range cfg.Chains {
+		if chain.SwapPairChain == "" {
+			continue
+		}
+		if !names[strings.ToUpper(chain.SwapPairChain)] || strings.EqualFold(chain.SwapPairChain, chain.Name) {
+			panic(fmt.Sprintf("invalid swap_pair_chain of %s: %s", chain.Name, chain.SwapPairChain))
+		}
+	}
+}
+
+// GetBalanceMonitorInterval returns the seconds between the balance checks of the signers
+func (cfg ChainConfig) GetBalanceMonitorInterval() int64 {
+	if cfg.BalanceMonitorInterval == 0 {
+		return common.BalanceMonitorDefaultInterval
+	}
+	return cfg.BalanceMonitorInterval
+}
+
+// GetChain returns the chain with the given name, the name is case insensitive
+func (cfg ChainConfig) GetChain(name string) *ChainInfo {
+	for idx := range cfg.Chains {
+		if strings.EqualFold(cfg.Chains[idx].Name, name) {
+			return &cfg.Chains[idx]
+		}
+	}
+	return nil
+}
+
+// GetChainByChainId returns the chain which declares the given chain id
+func (cfg ChainConfig) GetChainByChainId(chainId int64) *ChainInfo {
+	for idx := range cfg.Chains {
+		if cfg.Chains[idx].HasChainId(chainId) {
+			return &cfg.Chains[idx]
+		}
+	}
+	return nil
+}
+
+// ChainInfo describes a chain served by the bridge, e.g. BSC, ETH or CRO
+type ChainInfo struct {
+	Name string `json:"name"`
+	// ChainIds are the ids users may use to address this chain, e.g. mainnet and testnet ids
+	ChainIds []int64 `json:"chain_ids"`
+
+	ObserverFetchInterval int64  `json:"observer_fetch_interval"`
+	StartHeight           int64  `json:"start_height"`
+	Provider              string `json:"provider"`
+	// optional websocket provider, the observer is woken by its new heads and logs and polls the provider while it
+	// is disconnected
+	WsProvider string `json:"ws_provider"`
+	// more providers of the chain, provider is the first one of the pool. Calls go to the healthy providers by
+	// provider_strategy, and the observer requires provider_quorum of them to agree on blocks and logs
+	Providers                []string `json:"providers"`
+	ProviderStrategy         string   `json:"provider_strategy"`
+	ProviderQuorum           int      `json:"provider_quorum"`
+	ProviderMaxHeadLag       int64    `json:"provider_max_head_lag"`
+	ConfirmNum               int64    `json:"confirm_num"`
+	SwapAgentAddr            string   `json:"swap_agent_addr"`
+	ExplorerUrl              string   `json:"explorer_url"`
+	MaxTrackRetry            int64    `json:"max_track_retry"`
+	AlertThreshold           string   `json:"alert_threshold"`
+	WaitMilliSecBetweenSwaps int64    `json:"wait_milli_sec_between_swaps"`
+	// swap_workers workers send the fills to this chain, the nonce manager hands out as many nonces at once. The
+	// workers stop claiming swaps while max_pending_fills fill txs to this chain are not finalized, the defaults
+	// are used if they are 0
+	SwapWorkers     int   `json:"swap_workers"`
+	MaxPendingFills int64 `json:"max_pending_fills"`
+	// the fills to this chain are paused after max_fill_failures fills failed in a row, the default is used if it is 0
+	MaxFillFailures int64 `json:"max_fill_failures"`
+	// the fills to this chain are paused while the native balance of the signer is below pause_threshold, an alert
+	// is sent while it is below alert_threshold
+	PauseThreshold string `json:"pause_threshold"`
+	// swaps from this chain rejected for their swap pair quote are refunded to the sponsor
+	RefundRejectedSwaps bool `json:"refund_rejected_swaps"`
+	// swaps from this chain which failed to be filled are refunded to the sponsor
+	RefundFailedSwaps bool `json:"refund_failed_swaps"`
+	// swap pairs registered on this chain are created on the named chain, registration is ignored if it is empty
+	SwapPairChain string `json:"swap_pair_chain"`
+	// the observer scans up to max_fetch_range blocks with one log query while it is more than catch_up_distance
+	// blocks behind the head, the defaults are used if they are 0
+	MaxFetchRange   int64 `json:"max_fetch_range"`
+	CatchUpDistance int64 `json:"catch_up_distance"`
+
+	// the executor fetching the events of the chain, evm if empty. The replay executor reads the blocks of
+	// replay_file instead of the chain, it is meant for testing
+	ExecutorType string `json:"executor_type"`
+	ReplayFile   string `json:"replay_file"`
+	// the swap agents deployed on the chain by the height they take over from, the agent at swap_agent_addr with
+	// the built-in abis is used if empty
+	AgentVersions []AgentVersion `json:"agent_versions"`
+	// caps of the volume paid out to this chain in rolling windows, the swaps above a cap are held until the admin
+	// releases them
+	VelocityLimits []VelocityLimit `json:"velocity_limits"`
+	// the swaps paying out more than the threshold of their token to this chain wait for required_approvals of the
+	// approvers, key is the token address
+	ApprovalThresholds map[string]string `json:"approval_thresholds"`
+
+	GasConfig GasConfig `json:"gas_config"`
+}
+
+func (cfg ChainInfo) Validate() {
+	if cfg.Name == "" {
+		panic("chain name should not be empty")
+	}
+	if len(cfg.ChainIds) == 0 {
+		panic(fmt.Sprintf("chain_ids of %s should not be empty", cfg.Name))
+	}
+	if cfg.ObserverFetchInterval <= 0 {
+		panic(fmt.Sprintf("observer_fetch_interval of %s should be larger than 0", cfg.Name))
+	}
+	if cfg.StartHeight < 0 {
+		panic(fmt.Sprintf("start_height of %s should not be less than 0", cfg.Name))
+	}
+	if cfg.Provider == "" {
+		panic(fmt.Sprintf("provider of %s should not be empty", cfg.Name))
+	}
+	for _, provider := range cfg.Providers {
+		if provider == "" {
+			panic(fmt.Sprintf("providers of %s should not be empty", cfg.Name))
+		}
+	}
+	if cfg.ProviderStrategy != "" && cfg.ProviderStrategy != common.ProviderStrategyPriority &&
+		cfg.ProviderStrategy != common.ProviderStrategyRoundRobin {
+		panic(fmt.Sprintf("unknown provider_strategy of %s: %s", cfg.Name, cfg.ProviderStrategy))
+	}
+	if cfg.ProviderQuorum < 0 || cfg.ProviderQuorum > len(cfg.Providers)+1 {
+		panic(fmt.Sprintf("provider_quorum of %s should be between 0 and the number of providers", cfg.Name))
+	}
+	if cfg.ProviderMaxHeadLag < 0 {
+		panic(fmt.Sprintf("provider_max_head_lag of %s should not be less than 0", cfg.Name))
+	}
+	if cfg.WsProvider != "" && !strings.HasPrefix(cfg.WsProvider, "ws://") && !strings.HasPrefix(cfg.WsProvider, "wss://") {
+		panic(fmt.Sprintf("ws_provider of %s should be a ws:// or wss:// url", cfg.Name))
+	}
+	if cfg.ConfirmNum <= 0 {
+		panic(fmt.Sprintf("confirm_num of %s should be larger than 0", cfg.Name))
+	}
+	if !ethcom.IsHexAddress(cfg.SwapAgentAddr) {
+		panic(fmt.Sprintf("invalid swap_agent_addr of %s: %s", cfg.Name, cfg.SwapAgentAddr))
+	}
+	if cfg.MaxTrackRetry <= 0 {
+		panic(fmt.Sprintf("max_track_retry of %s should be larger than 0", cfg.Name))
+	}
+	// other executor types are checked when the executor is created, they may be registered by the executor package
+	if cfg.ExecutorType == common.ExecutorTypeReplay && cfg.ReplayFile == "" {
+		panic(fmt.Sprintf("replay_file of %s should not be empty", cfg.Name))
+	}
+	for idx, version := range cfg.AgentVersions {
+		if version.SwapAgentAddr != "" && !ethcom.IsHexAddress(version.SwapAgentAddr) {
+			panic(fmt.Sprintf("invalid swap_agent_addr of agent version %d of %s: %s", idx, cfg.Name, version.SwapAgentAddr))
+		}
+		if idx > 0 && version.FromHeight <= cfg.AgentVersions[idx-1].FromHeight {
+			panic(fmt.Sprintf("from_height of the agent versions of %s should be increasing", cfg.Name))
+		}
+	}
+	// fills are sent to swap_agent_addr, it must be the latest agent
+	if len(cfg.AgentVersions) > 0 {
+		latest := cfg.AgentVersions[len(cfg.AgentVersions)-1]
+		if latest.SwapAgentAddr != "" && !strings.EqualFold(latest.SwapAgentAddr, cfg.SwapAgentAddr) {
+			panic(fmt.Sprintf("the latest agent version of %s should be at swap_agent_addr", cfg.Name))
+		}
+	}
+	if cfg.MaxFetchRange < 0 {
+		panic(fmt.Sprintf("max_fetch_range of %s should not be less than 0", cfg.Name))
+	}
+	if cfg.CatchUpDistance < 0 {
+		panic(fmt.Sprintf("catch_up_distance of %s should not be less than 0", cfg.Name))
+	}
+	if cfg.SwapWorkers < 0 {
+		panic(fmt.Sprintf("swap_workers of %s should not be less than 0", cfg.Name))
 	}
-	if cfg.BSCProvider == "" {
-		panic("bsc_provider should not be empty")
+	if cfg.MaxPendingFills < 0 {
+		panic(fmt.Sprintf("max_pending_fills of %s should not be less than 0", cfg.Name))
 	}
-	if cfg.BSCConfirmNum <= 0 {
-		panic("bsc_confirm_num should be larger than 0")
+	if cfg.MaxFillFailures < 0 {
+		panic(fmt.Sprintf("max_fill_failures of %s should not be less than 0", cfg.Name))
 	}
-	if !ethcom.IsHexAddress(cfg.BSCSwapAgentAddr) {
-		panic(fmt.Sprintf("invalid bsc_swap_contract_addr: %s", cfg.BSCSwapAgentAddr))
+	thresholds := map[string]string{
+		"alert_threshold": cfg.AlertThreshold,
+		"pause_threshold": cfg.PauseThreshold,
 	}
-	if cfg.BSCMaxTrackRetry <= 0 {
-		panic("bsc_max_track_retry should be larger than 0")
+	for field, threshold := range thresholds {
+		if threshold == "" {
+			continue
+		}
+		if value, ok := big.NewInt(0).SetString(threshold, 10); !ok || value.Sign() < 0 {
+			panic(fmt.Sprintf("invalid %s of %s: %s", field, cfg.Name, threshold))
+		}
+	}
+	for idx, limit := range cfg.VelocityLimits {
+		limit.Validate(cfg.Name, idx)
+	}
+	for token, threshold := range cfg.ApprovalThresholds {
+		if !ethcom.IsHexAddress(token) {
+			panic(fmt.Sprintf("invalid token of approval_thresholds of %s: %s", cfg.Name, token))
+		}
+		if value, ok := big.NewInt(0).SetString(threshold, 10); !ok || value.Sign() < 0 {
+			panic(fmt.Sprintf("invalid approval threshold of %s of %s: %s", token, cfg.Name, threshold))
+		}
+	}
+	cfg.GasConfig.Validate(cfg.Name)
+}
+
+// GetSwapWorkers returns the number of the swap workers of the chain
+func (cfg ChainInfo) GetSwapWorkers() int {
+	if cfg.SwapWorkers == 0 {
+		return common.SwapDefaultWorkers
+	}
+	return cfg.SwapWorkers
+}
+
+// GetMaxFillFailures returns the number of the fills to the chain failing in a row which pauses the fills
+func (cfg ChainInfo) GetMaxFillFailures() int64 {
+	if cfg.MaxFillFailures == 0 {
+		return common.SwapDefaultMaxFillFailures
+	}
+	return cfg.MaxFillFailures
+}
+
+// GetApprovalThreshold returns the amount of the token above which the swaps to the chain need approvals, it is nil
+// if there is no threshold for the token
+func (cfg ChainInfo) GetApprovalThreshold(token string) *big.Int {
+	for addr, threshold := range cfg.ApprovalThresholds {
+		if strings.EqualFold(addr, token) {
+			value, _ := big.NewInt(0).SetString(threshold, 10)
+			return value
+		}
 	}
+	return nil
+}
 
-	if cfg.ETHStartHeight < 0 {
-		panic("bsc_start_height should not be less than 0")
+// GetMaxPendingFills returns the number of the pending fill txs to the chain which stops the swap workers
+func (cfg ChainInfo) GetMaxPendingFills() int64 {
+	if cfg.MaxPendingFills == 0 {
+		return common.SwapDefaultMaxPendingFills
 	}
-	if cfg.ETHProvider == "" {
-		panic("bsc_provider should not be empty")
+	return cfg.MaxPendingFills
+}
+
+func (cfg ChainInfo) HasChainId(chainId int64) bool {
+	for _, id := range cfg.ChainIds {
+		if id == chainId {
+			return true
+		}
 	}
-	if !ethcom.IsHexAddress(cfg.ETHSwapAgentAddr) {
-		panic(fmt.Sprintf("invalid eth_swap_contract_addr: %s", cfg.ETHSwapAgentAddr))
+	return false
+}
+
+// AgentVersion is a deployment of the swap agent, its events are decoded by the abis and the event names. The
+// arguments of the events are matched by name, so a new agent may index or order them differently
+type AgentVersion struct {
+	FromHeight int64 `json:"from_height"`
+	// swap_agent_addr of the chain if empty
+	SwapAgentAddr string `json:"swap_agent_addr"`
+	// abi json files of the swap agent and the swap pair agent, the built-in abis are used if empty
+	SwapAgentAbiFile     string `json:"swap_agent_abi_file"`
+	SwapPairAgentAbiFile string `json:"swap_pair_agent_abi_file"`
+	// the default event names are used if empty
+	SwapStartedEvent      string `json:"swap_started_event"`
+	SwapFilledEvent       string `json:"swap_filled_event"`
+	SwapPairRegisterEvent string `json:"swap_pair_register_event"`
+}
+
+// VelocityLimit caps the amount paid out to a chain in the last window seconds. The amounts of token are counted, or
+// the amounts of all tokens if it is empty, which is meant for chains paying out a single token. The amounts paid to
+// each sponsor are counted separately if per_sponsor is set
+type VelocityLimit struct {
+	Token      string `json:"token"`
+	PerSponsor bool   `json:"per_sponsor"`
+	Window     int64  `json:"window"`
+	MaxAmount  string `json:"max_amount"`
+}
+
+func (limit VelocityLimit) Validate(chain string, idx int) {
+	if limit.Token != "" && !ethcom.IsHexAddress(limit.Token) {
+		panic(fmt.Sprintf("invalid token of velocity limit %d of %s: %s", idx, chain, limit.Token))
 	}
-	if cfg.ETHConfirmNum <= 0 {
-		panic("bsc_confirm_num should be larger than 0")
+	if limit.Window <= 0 {
+		panic(fmt.Sprintf("window of velocity limit %d of %s should be larger than 0", idx, chain))
 	}
-	if cfg.ETHMaxTrackRetry <= 0 {
-		panic("eth_max_track_retry should be larger than 0")
+	if value, ok := big.NewInt(0).SetString(limit.MaxAmount, 10); !ok || value.Sign() < 0 {
+		panic(fmt.Sprintf("invalid max_amount of velocity limit %d of %s: %s", idx, chain, limit.MaxAmount))
+	}
+}
+
+// GasConfig decides how the fill txs of a chain are priced, all amounts are in wei
+type GasConfig struct {
+	// one of legacy, eip1559, fixed and fee_history, legacy is used if empty
+	Strategy string `json:"strategy"`
+
+	// gas price of the fixed strategy
+	FixedGasPrice string `json:"fixed_gas_price"`
+	// caps of the eip1559 and fee_history strategies, no cap if empty
+	MaxFeePerGas         string `json:"max_fee_per_gas"`
+	MaxPriorityFeePerGas string `json:"max_priority_fee_per_gas"`
+
+	// the priority fee of the fee_history strategy is the given percentile of the rewards in the last blocks
+	FeeHistoryBlocks     uint64  `json:"fee_history_blocks"`
+	FeeHistoryPercentile float64 `json:"fee_history_percentile"`
+
+	// fills are deferred while the expected gas price is above the ceiling, no ceiling if empty
+	GasPriceCeiling string `json:"gas_price_ceiling"`
+
+	// a fill tx still pending after bump_after_blocks blocks is replaced by a tx with the same nonce and a gas price
+	// at least bump_percent higher, at most max_bumps times, bumping is disabled if bump_after_blocks is 0
+	BumpAfterBlocks int64 `json:"bump_after_blocks"`
+	BumpPercent     int64 `json:"bump_percent"`
+	MaxBumps        int64 `json:"max_bumps"`
+}
+
+func (cfg GasConfig) Validate(chain string) {
+	switch cfg.Strategy {
+	case "", common.GasStrategyLegacy, common.GasStrategyEIP1559:
+	case common.GasStrategyFixed:
+		if cfg.FixedGasPrice == "" {
+			panic(fmt.Sprintf("fixed_gas_price of %s should not be empty", chain))
+		}
+	case common.GasStrategyFeeHistory:
+		if cfg.FeeHistoryBlocks == 0 {
+			panic(fmt.Sprintf("fee_history_blocks of %s should be larger than 0", chain))
+		}
+		if cfg.FeeHistoryPercentile <= 0 || cfg.FeeHistoryPercentile > 100 {
+			panic(fmt.Sprintf("fee_history_percentile of %s should be in (0, 100]", chain))
+		}
+	default:
+		panic(fmt.Sprintf("unsupported gas strategy of %s: %s", chain, cfg.Strategy))
+	}
+
+	if cfg.BumpAfterBlocks < 0 {
+		panic(fmt.Sprintf("bump_after_blocks of %s should not be less than 0", chain))
+	}
+	if cfg.BumpAfterBlocks > 0 {
+		// nodes reject replacements whose gas price is not at least 10% higher
+		if cfg.BumpPercent < 10 {
+			panic(fmt.Sprintf("bump_percent of %s should not be less than 10", chain))
+		}
+		if cfg.MaxBumps <= 0 {
+			panic(fmt.Sprintf("max_bumps of %s should be larger than 0", chain))
+		}
+	}
+
+	amounts := map[string]string{
+		"fixed_gas_price":          cfg.FixedGasPrice,
+		"max_fee_per_gas":          cfg.MaxFeePerGas,
+		"max_priority_fee_per_gas": cfg.MaxPriorityFeePerGas,
+		"gas_price_ceiling":        cfg.GasPriceCeiling,
+	}
+	for field, amount := range amounts {
+		if amount == "" {
+			continue
+		}
+		if value, ok := big.NewInt(0).SetString(amount, 10); !ok || value.Sign() <= 0 {
+			panic(fmt.Sprintf("invalid %s of %s: %s", field, chain, amount))
+		}
 	}
 }
 
@@ -201,6 +542,50 @@ func (cfg LogConfig) Validate() {
 
 type AdminConfig struct {
 	ListenAddr string `json:"listen_addr"`
+	// the number of distinct approvers approving a swap above the approval threshold of its token
+	RequiredApprovals int `json:"required_approvals"`
+}
+
+func (cfg AdminConfig) Validate() {
+	if cfg.RequiredApprovals < 0 {
+		panic("required_approvals should not be less than 0")
+	}
+}
+
+// ScreeningConfig lists the screeners the sponsor of every swap is checked by before the swap is confirmed, a swap is
+// blocked if any of them flags its sponsor
+type ScreeningConfig struct {
+	Screeners []ScreenerConfig `json:"screeners"`
+}
+
+// ScreenerConfig configures a screener of the type, the built-in types are blocklist and webhook
+type ScreenerConfig struct {
+	Type string `json:"type"`
+	// a file of the blocked addresses of the blocklist screener, it is reloaded when it changes
+	BlocklistFile string `json:"blocklist_file"`
+	// the url the webhook screener posts the addresses to, and its timeout in seconds, the default is used if it is 0
+	WebhookUrl     string `json:"webhook_url"`
+	WebhookTimeout int64  `json:"webhook_timeout"`
+}
+
+func (cfg ScreeningConfig) Validate() {
+	for idx, screener := range cfg.Screeners {
+		// other screener types are checked when the screener is created, they may be registered by the screening
+		// package
+		switch screener.Type {
+		case common.ScreenerTypeBlocklist:
+			if screener.BlocklistFile == "" {
+				panic(fmt.Sprintf("blocklist_file of screener %d should not be empty", idx))
+			}
+		case common.ScreenerTypeWebhook:
+			if !strings.HasPrefix(screener.WebhookUrl, "http://") && !strings.HasPrefix(screener.WebhookUrl, "https://") {
+				panic(fmt.Sprintf("webhook_url of screener %d should be a http:// or https:// url", idx))
+			}
+			if screener.WebhookTimeout < 0 {
+				panic(fmt.Sprintf("webhook_timeout of screener %d should not be less than 0", idx))
+			}
+		}
+	}
 }
 
 func ParseConfigFromFile(filePath string) *Config {
@@ -43,6 +43,30 @@ func NewHmacSignerFromConfig(config *Config) (*HmacSigner, error) {
 	return NewHmacSigner(apiKey, secretKey), nil
 }
 
+// NewApproverSignersFromConfig returns the signers of the approvers keyed by their api keys
+func NewApproverSignersFromConfig(config *Config) (map[string]*HmacSigner, error) {
+	approverKeys := config.KeyManagerConfig.LocalApproverKeys
+	if config.KeyManagerConfig.KeyType == common.AWSPrivateKey {
+		result, err := GetSecret(config.KeyManagerConfig.AWSSecretName, config.KeyManagerConfig.AWSRegion)
+		if err != nil {
+			return nil, err
+		}
+
+		keyConfig := KeyConfig{}
+		err = json.Unmarshal([]byte(result), &keyConfig)
+		if err != nil {
+			return nil, err
+		}
+		approverKeys = keyConfig.ApproverKeys
+	}
+
+	signers := make(map[string]*HmacSigner, len(approverKeys))
+	for apiKey, secretKey := range approverKeys {
+		signers[apiKey] = NewHmacSigner(apiKey, secretKey)
+	}
+	return signers, nil
+}
+
 func NewHmacSigner(apiKey string, secretKey string) *HmacSigner {
 	return &HmacSigner{
 		ApiKey:    apiKey,